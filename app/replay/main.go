@@ -0,0 +1,135 @@
+package main
+
+// Replays a consensus trace recorded by a node (see `trace_file` in the consensus config) into a fresh consensus
+// module created with the config of that node, logging the state of the module after every entry.
+
+import (
+	"flag"
+	"log"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/persistence/pre_persistence"
+	"github.com/pokt-network/pocket/shared"
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/utility"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// The pacemaker timer still runs in manual mode, so it is pushed back far enough to never expire during a replay;
+// the timeouts of the original run are part of the trace.
+const replayPacemakerTimeoutMsec = 24 * 60 * 60 * 1000
+
+// Replaying a single trace entry publishes a handful of consensus events, which are logged after every entry.
+const replayEventsBufferSize = 100
+
+func main() {
+	configFilename := flag.String("config", "", "Relative or absolute path to the config file of the node that recorded the trace.")
+	traceFilename := flag.String("trace", "", "Relative or absolute path to the trace file.")
+	flag.Parse()
+
+	trace, err := consensus.ReadTrace(*traceFilename)
+	if err != nil {
+		log.Fatalf("[ERROR] Failed to read trace: %v", err)
+	}
+
+	cfg := config.LoadConfig(*configFilename)
+	cfg.RootDir = "" // The WAL of the node must not be restored, nor overwritten
+	cfg.Consensus.TraceFile = ""
+	cfg.Consensus.Pacemaker.Manual = true
+	cfg.Consensus.Pacemaker.TimeoutMsec = replayPacemakerTimeoutMsec
+	cfg.Consensus.Pacemaker.TimeoutMaxMsec = replayPacemakerTimeoutMsec
+
+	persistenceMod, err := pre_persistence.Create(cfg)
+	if err != nil {
+		log.Fatalf("[ERROR] Failed to create persistence module: %v", err)
+	}
+	utilityMod, err := utility.Create(cfg)
+	if err != nil {
+		log.Fatalf("[ERROR] Failed to create utility module: %v", err)
+	}
+	consensusMod, err := consensus.Create(cfg)
+	if err != nil {
+		log.Fatalf("[ERROR] Failed to create consensus module: %v", err)
+	}
+	bus, err := shared.CreateBus(persistenceMod, &replayP2PModule{}, utilityMod, consensusMod)
+	if err != nil {
+		log.Fatalf("[ERROR] Failed to create bus: %v", err)
+	}
+	// The events are logged through a subscription instead, which is read after every entry; nothing else is
+	// published on the bus during a replay
+	go func() {
+		for {
+			bus.GetBusEvent()
+		}
+	}()
+
+	events := make(chan *types.ConsensusEvent, replayEventsBufferSize)
+	consensusMod.SubscribeToEvents(events)
+
+	for _, mod := range []modules.Module{persistenceMod, utilityMod, consensusMod} {
+		if err := mod.Start(); err != nil {
+			log.Fatalf("[ERROR] Failed to start module: %v", err)
+		}
+	}
+
+	err = consensus.ReplayTrace(consensusMod, trace, func(i int, entry *typesCons.TraceEntry) {
+		log.Printf("[REPLAY] Entry %d at %s: %s %s (peer: %q)\n", i, entry.Timestamp.AsTime(), entry.Type, entry.Message.GetTypeUrl(), entry.Peer)
+		logConsensusEvents(events)
+
+		snapshot := consensusMod.StateSnapshot()
+		log.Printf("[REPLAY] State after entry %d: (height, round, step): (%d, %d, %d); leader: %d; locked QC: %v; high QC: %v\n",
+			i, snapshot.Height, snapshot.Round, snapshot.Step, snapshot.LeaderId, snapshot.LockedQc, snapshot.HighPrepareQc)
+	})
+	if err != nil {
+		log.Fatalf("[ERROR] Failed to replay trace: %v", err)
+	}
+}
+
+// Logs the consensus events published while the last trace entry was replayed.
+func logConsensusEvents(events chan *types.ConsensusEvent) {
+	for len(events) > 0 {
+		event := <-events
+		log.Printf("[REPLAY] \t%s\n", event.String())
+	}
+}
+
+// Messages sent by the module being replayed are dropped since they were already recorded in the trace.
+type replayP2PModule struct {
+	bus modules.Bus
+}
+
+func (m *replayP2PModule) SetBus(bus modules.Bus) {
+	m.bus = bus
+}
+
+func (m *replayP2PModule) GetBus() modules.Bus {
+	return m.bus
+}
+
+func (m *replayP2PModule) Start() error {
+	return nil
+}
+
+func (m *replayP2PModule) Stop() error {
+	return nil
+}
+
+func (m *replayP2PModule) Broadcast(_ *anypb.Any, _ types.PocketTopic) error {
+	return nil
+}
+
+func (m *replayP2PModule) Send(_ cryptoPocket.Address, _ *anypb.Any, _ types.PocketTopic) error {
+	return nil
+}
+
+func (m *replayP2PModule) UpdateAddrBook(_ modules.ValidatorMap) error {
+	return nil
+}
+
+func (m *replayP2PModule) HandleAddrBookEvent(_ *types.AddrBookEvent) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+// Exports the last signed state of a validator from the root directory of its node, or imports it into the root
+// directory of the node it is migrated to, so the validator cannot sign messages that conflict with the ones it
+// signed on the previous machine. The node must not be running while its state is imported.
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+
+	"github.com/pokt-network/pocket/consensus"
+	"github.com/pokt-network/pocket/shared/config"
+)
+
+func main() {
+	configFilename := flag.String("config", "", "Relative or absolute path to the config file of the node.")
+	exportFilename := flag.String("export", "", "Relative or absolute path to the file the last signed state is exported to.")
+	importFilename := flag.String("import", "", "Relative or absolute path to the file the last signed state is imported from.")
+	flag.Parse()
+
+	cfg := config.LoadConfig(*configFilename)
+	if cfg.RootDir == "" {
+		log.Fatalf("[ERROR] The node must have a root directory to persist its last signed state")
+	}
+
+	switch {
+	case *exportFilename != "" && *importFilename == "":
+		bz, err := consensus.ExportLastSignedState(cfg.RootDir)
+		if err != nil {
+			log.Fatalf("[ERROR] Failed to export the last signed state: %v", err)
+		}
+		if err := ioutil.WriteFile(*exportFilename, bz, 0600); err != nil {
+			log.Fatalf("[ERROR] Failed to write the last signed state: %v", err)
+		}
+		log.Printf("[INFO] Exported the last signed state to %s: %s\n", *exportFilename, bz)
+	case *importFilename != "" && *exportFilename == "":
+		bz, err := ioutil.ReadFile(*importFilename)
+		if err != nil {
+			log.Fatalf("[ERROR] Failed to read the last signed state: %v", err)
+		}
+		if err := consensus.ImportLastSignedState(cfg.RootDir, cfg.PrivateKey.Address().String(), bz); err != nil {
+			log.Fatalf("[ERROR] Failed to import the last signed state: %v", err)
+		}
+		log.Printf("[INFO] Imported the last signed state from %s\n", *importFilename)
+	default:
+		log.Fatalf("[ERROR] Exactly one of -export or -import must be specified")
+	}
+}
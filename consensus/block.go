@@ -2,18 +2,71 @@ package consensus
 
 import (
 	"encoding/hex"
-	"unsafe"
+	"math"
+	"strings"
 
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	typesCons "github.com/pokt-network/pocket/consensus/types"
 )
 
-// TODO(olshansky): Sync with Andrew on the type of validation we need here.
+// Validates that the block header commits to the contents of the block and that the block extends the last
+// block committed by this node. The app hash can only be validated once the block is applied.
 func (m *consensusModule) validateBlock(block *types.Block) error {
 	if block == nil {
 		return typesCons.ErrNilBlock
 	}
+
+	header := block.BlockHeader
+	if header == nil {
+		return typesCons.ErrNilBlockHeader
+	}
+
+	if blockSize := uint64(proto.Size(block)); blockSize > m.consCfg.MaxBlockBytes {
+		return typesCons.ErrInvalidBlockSize(blockSize, m.consCfg.MaxBlockBytes)
+	}
+
+	if header.Height != int64(m.Height) {
+		return typesCons.ErrInvalidBlockHeight(header.Height, m.Height)
+	}
+
+	blockHash, err := header.ComputeHash()
+	if err != nil {
+		return err
+	}
+	if header.Hash != blockHash {
+		return typesCons.ErrInvalidBlockHash(header.Hash, blockHash)
+	}
+
+	if header.NumTxs != uint32(len(block.Transactions)) || header.TransactionsRoot != types.TransactionsRoot(block.Transactions) {
+		return typesCons.ErrInvalidTransactionsRoot
+	}
+
+	if header.LastBlockHash != m.lastBlockHash {
+		return typesCons.ErrInvalidLastBlockHash(header.LastBlockHash, m.lastBlockHash)
+	}
+
+	validatorsHash, err := m.getValidatorSetHash()
+	if err != nil {
+		return err
+	}
+	if header.ValidatorsHash != validatorsHash {
+		return typesCons.ErrInvalidValidatorsHash(header.ValidatorsHash, validatorsHash)
+	}
+
+	// Every node that committed the last block did so with the exact same COMMIT QC, broadcast by the leader
+	// in the DECIDE step or received through block sync, so it must match the one in the header exactly.
+	lastCommitQC, err := bytesToQC(header.QuorumCertificate)
+	if err != nil {
+		return err
+	}
+	if !proto.Equal(lastCommitQC, m.lastCommitQC) {
+		return typesCons.ErrInvalidLastCommitQC
+	}
+
 	return nil
 }
 
@@ -22,27 +75,58 @@ func (m *consensusModule) prepareBlock() (*types.Block, error) {
 	if m.isReplica() {
 		return nil, typesCons.ErrReplicaPrepareBlock
 	}
+	if block := m.getReplayedProposal(); block != nil {
+		if err := m.executeBlock(block); err != nil {
+			return nil, err
+		}
+		return block, nil
+	}
 	if err := m.updateUtilityContext(); err != nil {
 		return nil, err
 	}
 
-	txs, err := m.utilityContext.GetTransactionsForProposal(m.privateKey.Address(), maxTxBytes, lastByzValidators)
+	lastCommitQCBz, err := m.getLastCommitQCBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	appHash, err := m.utilityContext.ApplyBlock(int64(m.Height), m.privateKey.Address(), txs, lastByzValidators)
+	validatorsHash, err := m.getValidatorSetHash()
 	if err != nil {
 		return nil, err
 	}
 
+	// The remaining fields of the header are set once the transactions are applied
 	blockHeader := &types.BlockHeader{
 		Height:            int64(m.Height),
-		Hash:              hex.EncodeToString(appHash),
-		NumTxs:            uint32(len(txs)),
-		LastBlockHash:     m.appHash,
+		Time:              timestamppb.Now(),
+		LastBlockHash:     m.lastBlockHash,
 		ProposerAddress:   m.privateKey.Address(),
-		QuorumCertificate: nil,
+		QuorumCertificate: lastCommitQCBz,
+		ValidatorsHash:    validatorsHash,
+	}
+
+	maxTxBytes, err := m.getMaxTransactionBytes(blockHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := m.utilityContext.GetTransactionsForProposal(m.privateKey.Address(), maxTxBytes, m.lastMissedValidators)
+	if err != nil {
+		return nil, err
+	}
+
+	appHash, validatorUpdates, err := m.utilityContext.ApplyBlock(int64(m.Height), m.privateKey.Address(), txs, m.lastMissedValidators)
+	if err != nil {
+		return nil, err
+	}
+	m.validatorUpdates = validatorUpdates
+
+	blockHeader.NumTxs = uint32(len(txs))
+	blockHeader.AppHash = hex.EncodeToString(appHash)
+	blockHeader.TransactionsRoot = types.TransactionsRoot(txs)
+	blockHeader.Hash, err = blockHeader.ComputeHash()
+	if err != nil {
+		return nil, err
 	}
 
 	block := &types.Block{
@@ -50,46 +134,74 @@ func (m *consensusModule) prepareBlock() (*types.Block, error) {
 		Transactions: txs,
 	}
 
+	// Only reachable if utility returns an app hash larger than the one accounted for in `getMaxTransactionBytes`
+	if blockSize := uint64(proto.Size(block)); blockSize > m.consCfg.MaxBlockBytes {
+		return nil, typesCons.ErrInvalidBlockSize(blockSize, m.consCfg.MaxBlockBytes)
+	}
+
 	return block, nil
 }
 
+// Returns the number of bytes the transactions can add to a block with the specified header before its serialized
+// size exceeds `MaxBlockBytes`. The header fields that depend on the transactions are sized for their largest values.
+func (m *consensusModule) getMaxTransactionBytes(blockHeader *types.BlockHeader) (int, error) {
+	hashPlaceholder := strings.Repeat("0", hex.EncodedLen(cryptoPocket.SHA3HashLen))
+
+	fullHeader := proto.Clone(blockHeader).(*types.BlockHeader)
+	fullHeader.NumTxs = math.MaxUint32
+	fullHeader.AppHash = hashPlaceholder
+	fullHeader.TransactionsRoot = hashPlaceholder
+	fullHeader.Hash = hashPlaceholder
+
+	headerSize := uint64(proto.Size(&types.Block{BlockHeader: fullHeader}))
+	if headerSize >= m.consCfg.MaxBlockBytes {
+		return 0, typesCons.ErrInvalidBlockSize(headerSize, m.consCfg.MaxBlockBytes)
+	}
+	return int(m.consCfg.MaxBlockBytes - headerSize), nil
+}
+
 // This is a helper function intended to be called by a replica/voter during a view change
 func (m *consensusModule) applyBlock(block *types.Block) error {
 	if m.isLeader() {
 		return typesCons.ErrLeaderApplyBLock
 	}
+	return m.executeBlock(block)
+}
 
-	// TODO(olshansky): Add unit tests to verify this.
-	if unsafe.Sizeof(*block) > uintptr(m.consCfg.MaxBlockBytes) {
-		return typesCons.ErrInvalidBlockSize(uint64(unsafe.Sizeof(*block)), m.consCfg.MaxBlockBytes)
-	}
-
+// Applies a block proposed by any validator, including a block the leader proposes again from an earlier round,
+// to a new utility context and verifies the app hash in its header.
+func (m *consensusModule) executeBlock(block *types.Block) error {
 	if err := m.updateUtilityContext(); err != nil {
 		return err
 	}
 
-	appHash, err := m.utilityContext.ApplyBlock(int64(m.Height), m.privateKey.Address(), block.Transactions, lastByzValidators)
+	appHash, validatorUpdates, err := m.utilityContext.ApplyBlock(int64(m.Height), block.BlockHeader.ProposerAddress, block.Transactions, m.lastMissedValidators)
 	if err != nil {
 		return err
 	}
 
-	// TODO(olshansky) blockhash is not the appHash. Discuss offline with Andrew
-	if block.BlockHeader.Hash != hex.EncodeToString(appHash) {
-		return typesCons.ErrInvalidAppHash(block.BlockHeader.Hash, hex.EncodeToString(appHash))
+	if block.BlockHeader.AppHash != hex.EncodeToString(appHash) {
+		return typesCons.ErrInvalidAppHash(block.BlockHeader.AppHash, hex.EncodeToString(appHash))
 	}
+	m.validatorUpdates = validatorUpdates
 
 	return nil
 }
 
 // Creates a new Utility context and clears/nullifies any previous contexts if they exist
 func (m *consensusModule) updateUtilityContext() error {
+	return m.updateUtilityContextAtHeight(int64(m.Height))
+}
+
+// In chained mode, blocks are committed at a lower height than the one the node votes at.
+func (m *consensusModule) updateUtilityContextAtHeight(height int64) error {
 	if m.utilityContext != nil {
 		m.nodeLog(typesCons.NilUtilityContextWarning)
 		m.utilityContext.ReleaseContext()
 		m.utilityContext = nil
 	}
 
-	utilityContext, err := m.GetBus().GetUtilityModule().NewContext(int64(m.Height))
+	utilityContext, err := m.GetBus().GetUtilityModule().NewContext(height)
 	if err != nil {
 		return err
 	}
@@ -98,16 +210,105 @@ func (m *consensusModule) updateUtilityContext() error {
 	return nil
 }
 
-func (m *consensusModule) commitBlock(block *types.Block) error {
-	m.nodeLog(typesCons.CommittingBlock(m.Height, len(block.Transactions)))
+func (m *consensusModule) commitBlock(block *types.Block, commitQC *typesCons.QuorumCertificate) error {
+	height := uint64(block.BlockHeader.Height)
+	m.nodeLog(typesCons.CommittingBlock(height, len(block.Transactions)))
 
-	if err := m.utilityContext.GetPersistenceContext().Commit(); err != nil {
+	if err := m.utilityContext.CommitContext(); err != nil {
 		return err
 	}
 	m.utilityContext.ReleaseContext()
 	m.utilityContext = nil
 
-	m.appHash = block.BlockHeader.Hash
+	m.appHash = block.BlockHeader.AppHash
+	m.lastBlockHash = block.BlockHeader.Hash
+	m.lastCommitQC = stripBlockFromQC(commitQC)
+	m.lastMissedValidators = m.getNonSigners(commitQC)
+	committedBlock := &typesCons.CommittedBlock{
+		Block:            block,
+		CommitQc:         commitQC,
+		MissedValidators: m.lastMissedValidators,
+	}
+	m.CommittedBlocks[height] = committedBlock
+	if height >= committedBlocksWindow {
+		delete(m.CommittedBlocks, height-committedBlocksWindow)
+	}
+	m.applyValidatorUpdates()
+
+	// The commit is recorded as soon as persistence holds the block, so the block is never applied twice
+	if err := m.writeCommitToWAL(); err != nil {
+		return err
+	}
+	if m.blockStore != nil {
+		if err := m.blockStore.put(committedBlock); err != nil {
+			return err
+		}
+	}
+	m.publishBlockCommitted(block.BlockHeader.Hash)
 
 	return nil
 }
+
+// Returns the addresses of the validators that did not contribute a partial signature to the QC, sorted so
+// every node passes the same list to utility when applying the next block. The QC is formed as soon as enough
+// stake signed it, so an honest validator whose vote arrived just after is also missing: the list is only passed
+// to utility as missed blocks (a liveness fault), while byzantine behaviour is punished through double sign evidence.
+func (m *consensusModule) getNonSigners(qc *typesCons.QuorumCertificate) [][]byte {
+	validators := m.getValidatorList()
+	signerBitmap := NewSignerBitmap(len(validators))
+	if qc != nil && qc.ThresholdSignature != nil {
+		bitmap, err := m.thresholdSigner.SignerBitmap(validators, qc.ThresholdSignature)
+		if err != nil {
+			m.nodeLogError(typesCons.ErrGetQCSigners.Error(), err)
+		} else {
+			signerBitmap = bitmap
+		}
+	}
+
+	nonSigners := make([][]byte, 0, len(validators)-signerBitmap.Count())
+	for i, validator := range validators {
+		if !signerBitmap.IsSet(i) {
+			nonSigners = append(nonSigners, validator.Address)
+		}
+	}
+	return nonSigners
+}
+
+// Returns the serialized COMMIT QC of the last committed block, which is embedded in the header of the next block.
+func (m *consensusModule) getLastCommitQCBytes() ([]byte, error) {
+	return qcToBytes(m.lastCommitQC)
+}
+
+// Returns a copy of the QC without the block it certifies, which is how QCs are embedded in block headers.
+func stripBlockFromQC(qc *typesCons.QuorumCertificate) *typesCons.QuorumCertificate {
+	if qc == nil {
+		return nil
+	}
+	return &typesCons.QuorumCertificate{
+		Height:             qc.Height,
+		Round:              qc.Round,
+		Step:               qc.Step,
+		BlockHash:          qc.BlockHash,
+		ThresholdSignature: qc.ThresholdSignature,
+	}
+}
+
+// Serializes a QC deterministically so it can be embedded in a block header, which is empty for a nil QC.
+func qcToBytes(qc *typesCons.QuorumCertificate) ([]byte, error) {
+	if qc == nil {
+		return nil, nil
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(qc)
+}
+
+// Deserializes the QC embedded in a block header, which is empty for the first block.
+func bytesToQC(qcBz []byte) (*typesCons.QuorumCertificate, error) {
+	if len(qcBz) == 0 {
+		return nil, nil
+	}
+	qc := &typesCons.QuorumCertificate{}
+	if err := proto.Unmarshal(qcBz, qc); err != nil {
+		return nil, err
+	}
+	return qc, nil
+}
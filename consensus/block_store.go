@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const blockStoreDirName = "blocks"
+
+// TODO(design): Move the block store over to the persistence module once it can store blocks.
+// Stores every block committed by the node in its root directory, alongside the COMMIT QC and the validators
+// that missed it, which the next block is applied with, so they survive restarts and can be served to lagging nodes.
+type blockStore struct {
+	dir string
+}
+
+func openBlockStore(rootDir string) (*blockStore, error) {
+	dir := filepath.Join(rootDir, blockStoreDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &blockStore{dir: dir}, nil
+}
+
+// Durably records the committed block before returning.
+func (s *blockStore) put(committedBlock *typesCons.CommittedBlock) error {
+	bz, err := proto.MarshalOptions{Deterministic: true}.Marshal(committedBlock)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.path(uint64(committedBlock.Block.BlockHeader.Height)), bz)
+}
+
+// Returns the committed block at the specified height, or nil if it is not in the store.
+func (s *blockStore) get(height uint64) (*typesCons.CommittedBlock, error) {
+	bz, err := ioutil.ReadFile(s.path(height))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	committedBlock := &typesCons.CommittedBlock{}
+	if err := proto.Unmarshal(bz, committedBlock); err != nil {
+		return nil, err
+	}
+	return committedBlock, nil
+}
+
+// Discards every block in the store.
+func (s *blockStore) reset() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(s.dir, 0700)
+}
+
+func (s *blockStore) path(height uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d.block", height))
+}
+
+/*** Block Store Helpers ***/
+
+// Returns the committed block at the specified height from memory, or from the block store if it is no longer
+// in the window of recently committed blocks.
+func (m *consensusModule) getCommittedBlock(height uint64) (*typesCons.CommittedBlock, error) {
+	if committedBlock, ok := m.CommittedBlocks[height]; ok {
+		return committedBlock, nil
+	}
+	if m.blockStore == nil {
+		return nil, nil
+	}
+	return m.blockStore.get(height)
+}
@@ -0,0 +1,239 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"time"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// TODO(olshansky): Move this to config.json.
+// The amount of time a node waits for a response before requesting the same block from the network again.
+var blockSyncRequestTimeout = 2 * time.Second
+
+// The number of most recently committed blocks kept in memory to serve the block sync requests of lagging nodes.
+// Older blocks are served from the block store if the node has one.
+const committedBlocksWindow = 1000
+
+// Triggered when the node receives a message from a height ahead of its own. The height of a message is chosen by
+// its sender, so the sync target only moves to a height proven by a certificate the message carries. A single forged
+// message can therefore not make the node request blocks the network never committed.
+func (m *consensusModule) handleFutureMessage(msg *typesCons.HotstuffMessage) {
+	networkHeight := m.syncTargetHeight
+	if msg.Height > networkHeight {
+		if certifiedHeight := m.getCertifiedNetworkHeight(msg); certifiedHeight > networkHeight {
+			networkHeight = certifiedHeight
+		}
+	}
+	m.startStateSync(networkHeight)
+}
+
+// Returns the height the network is working on according to the certificates carried by `msg`, or 0 if it does not
+// carry any valid certificate of a height up to its own:
+//   - A QC or TimeoutQC of a height proves that enough validators are working on that height.
+//   - A COMMIT QC, including the one embedded in the header of a proposed block, proves that its height was committed.
+//
+// DISCUSS: Certificates are verified against the current validator set, so a node that missed a validator set update
+// may not be able to sync past it.
+func (m *consensusModule) getCertifiedNetworkHeight(msg *typesCons.HotstuffMessage) (networkHeight uint64) {
+	certifyHeight := func(height uint64) {
+		if height <= msg.Height && height > networkHeight {
+			networkHeight = height
+		}
+	}
+
+	if timeoutQC := msg.GetTimeoutQc(); timeoutQC != nil {
+		if err := m.validateTimeoutQuorumCertificate(timeoutQC, timeoutQC.Height, timeoutQC.Round); err == nil {
+			certifyHeight(timeoutQC.Height)
+		}
+	}
+
+	qcs := []*typesCons.QuorumCertificate{msg.GetQuorumCertificate()}
+	if header := msg.GetBlock().GetBlockHeader(); header != nil {
+		if lastCommitQC, err := bytesToQC(header.QuorumCertificate); err == nil {
+			qcs = append(qcs, lastCommitQC)
+		}
+	}
+	for _, qc := range qcs {
+		if qc == nil {
+			continue
+		}
+		if _, err := VerifyQuorumCertificateSignature(m.thresholdSigner, m.getValidatorList(), qc); err != nil {
+			continue
+		}
+		if qc.Step == Commit {
+			certifyHeight(qc.Height + 1)
+		} else {
+			certifyHeight(qc.Height)
+		}
+	}
+	return
+}
+
+// Requests every block the node is missing (alongside its COMMIT QC) from the network, applies them one by one and
+// rejoins Hotstuff once it reaches `networkHeight`.
+func (m *consensusModule) startStateSync(networkHeight uint64) {
+	if networkHeight <= m.Height {
+		return
+	}
+
+	if networkHeight > m.syncTargetHeight {
+		m.nodeLog(typesCons.StateSyncStarted(m.Height, networkHeight))
+		m.syncTargetHeight = networkHeight
+	}
+
+	// Avoid flooding the network with requests for the same block while waiting for a response.
+	if m.syncRequestHeight == m.Height && time.Since(m.syncRequestTime) < blockSyncRequestTimeout {
+		return
+	}
+
+	m.requestBlock(m.Height)
+}
+
+func (m *consensusModule) isSyncing() bool {
+	return m.syncTargetHeight > m.Height
+}
+
+func (m *consensusModule) requestBlock(height uint64) {
+	m.syncRequestHeight = height
+	m.syncRequestTime = time.Now()
+
+	request := &typesCons.BlockSyncRequest{
+		Height:           height,
+		RequesterAddress: m.privateKey.Address().String(),
+	}
+	m.nodeLog(typesCons.RequestingBlock(height))
+	m.broadcastBlockSyncMessage(request)
+}
+
+func (m *consensusModule) handleBlockSyncRequest(request *typesCons.BlockSyncRequest) {
+	// The requester can be served by any node that already committed the block.
+	committedBlock, err := m.getCommittedBlock(request.Height)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrBlockStore.Error(), err)
+		return
+	}
+	if committedBlock == nil {
+		return
+	}
+
+	response := &typesCons.BlockSyncResponse{
+		CommittedBlock: committedBlock,
+	}
+	anyResponse, err := anypb.New(response)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrCreateConsensusMessage.Error(), err)
+		return
+	}
+
+	requesterAddr, err := hex.DecodeString(request.RequesterAddress)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrBlockSyncInvalidRequester.Error(), err)
+		return
+	}
+
+	m.traceOutbound(request.RequesterAddress, anyResponse)
+	if err := m.GetBus().GetP2PModule().Send(cryptoPocket.Address(requesterAddr), anyResponse, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC); err != nil {
+		m.nodeLogError(typesCons.ErrSendMessage.Error(), err)
+		return
+	}
+}
+
+func (m *consensusModule) handleBlockSyncResponse(response *typesCons.BlockSyncResponse) {
+	committedBlock := response.GetCommittedBlock()
+	if committedBlock == nil || committedBlock.Block == nil || committedBlock.Block.BlockHeader == nil {
+		m.nodeLogError(typesCons.ErrApplyCommittedBlock.Error(), typesCons.ErrNilBlock)
+		return
+	}
+
+	// Multiple peers may respond to the same request, so only the first valid response is applied.
+	if !m.isSyncing() || uint64(committedBlock.Block.BlockHeader.Height) != m.Height {
+		return
+	}
+
+	if err := m.applyCommittedBlock(committedBlock); err != nil {
+		m.nodeLogError(typesCons.ErrApplyCommittedBlock.Error(), err)
+		return
+	}
+	m.nodeLog(typesCons.StateSyncAppliedBlock(m.Height))
+
+	// Rejoin Hotstuff at the height the rest of the network is working on.
+	if m.Height+1 >= m.syncTargetHeight {
+		m.nodeLog(typesCons.StateSyncCompleted(m.syncTargetHeight))
+		m.syncTargetHeight = 0
+		m.paceMaker.NewHeight()
+		return
+	}
+
+	m.Height++
+	m.Round = 0
+	m.Step = NewRound
+	m.Block = nil
+	m.HighPrepareQC = nil
+	m.LockedQC = nil
+	m.clearLeader()
+	m.clearMessagesPool()
+
+	m.requestBlock(m.Height)
+}
+
+// Validates a block committed by the rest of the network and applies it without going through Hotstuff.
+func (m *consensusModule) applyCommittedBlock(committedBlock *typesCons.CommittedBlock) error {
+	block := committedBlock.Block
+	commitQC := committedBlock.CommitQc
+
+	if err := m.validateBlock(block); err != nil {
+		return err
+	}
+
+	if err := m.validateQuorumCertificate(commitQC); err != nil {
+		return err
+	}
+
+	if commitQC.Step != Commit {
+		return typesCons.ErrQCStepMismatch(Commit, commitQC.Step)
+	}
+
+	if commitQC.Height != m.Height {
+		return typesCons.ErrQCHeightRoundMismatch(commitQC.Height, commitQC.Round, m.Height, commitQC.Round)
+	}
+
+	if protoHash(commitQC.Block) != protoHash(block) {
+		return typesCons.ErrQCBlockMismatch
+	}
+
+	if err := m.updateUtilityContext(); err != nil {
+		return err
+	}
+
+	appHash, validatorUpdates, err := m.utilityContext.ApplyBlock(int64(m.Height), block.BlockHeader.ProposerAddress, block.Transactions, m.lastMissedValidators)
+	if err != nil {
+		return err
+	}
+
+	if block.BlockHeader.AppHash != hex.EncodeToString(appHash) {
+		return typesCons.ErrInvalidAppHash(block.BlockHeader.AppHash, hex.EncodeToString(appHash))
+	}
+	m.validatorUpdates = validatorUpdates
+
+	m.Block = block
+	return m.commitBlock(block, commitQC)
+}
+
+func (m *consensusModule) broadcastBlockSyncMessage(msg proto.Message) {
+	anyMessage, err := anypb.New(msg)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrCreateConsensusMessage.Error(), err)
+		return
+	}
+
+	m.traceOutbound("", anyMessage)
+	if err := m.GetBus().GetP2PModule().Broadcast(anyMessage, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC); err != nil {
+		m.nodeLogError(typesCons.ErrBroadcastMessage.Error(), err)
+		return
+	}
+}
@@ -0,0 +1,190 @@
+package consensus_tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusEventsPublishedForCommittedBlock(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 3, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	eventChannels := SubscribeToConsensusEvents(pocketNodes)
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		ScheduleNextView(t, network, pocketNode, 0)
+	}
+
+	events := make(map[uint64][]*types.ConsensusEvent, numNodes)
+	committed := network.RunUntil(func() bool {
+		// Read the events as they are published so the subscriptions never fill up
+		for nodeId, eventChannel := range eventChannels {
+			events[uint64(nodeId)] = append(events[uint64(nodeId)], DrainConsensusEvents(eventChannel)...)
+		}
+		for _, pocketNode := range pocketNodes {
+			if pocketNode.GetBus().GetConsensusModule().StateSnapshot().Height < 2 {
+				return false
+			}
+		}
+		return true
+	}, 1000)
+	require.True(t, committed)
+
+	// Node 2 is the leader of the first round; its QCs are formed from the votes for every step but NEWROUND
+	leaderEvents := events[2]
+	require.Equal(t, []types.ConsensusEventType{
+		types.ConsensusEventType_CONSENSUS_EVENT_NEW_HEIGHT,
+		types.ConsensusEventType_CONSENSUS_EVENT_LEADER_ELECTED,
+		types.ConsensusEventType_CONSENSUS_EVENT_STEP_CHANGED, // PREPARE
+		types.ConsensusEventType_CONSENSUS_EVENT_QC_FORMED,
+		types.ConsensusEventType_CONSENSUS_EVENT_STEP_CHANGED, // PRECOMMIT
+		types.ConsensusEventType_CONSENSUS_EVENT_QC_FORMED,
+		types.ConsensusEventType_CONSENSUS_EVENT_STEP_CHANGED, // COMMIT
+		types.ConsensusEventType_CONSENSUS_EVENT_QC_FORMED,
+		types.ConsensusEventType_CONSENSUS_EVENT_STEP_CHANGED, // DECIDE
+		types.ConsensusEventType_CONSENSUS_EVENT_BLOCK_COMMITTED,
+		types.ConsensusEventType_CONSENSUS_EVENT_NEW_HEIGHT,
+	}, eventTypes(leaderEvents))
+	for i, step := range []typesCons.HotstuffStep{consensus.Prepare, consensus.PreCommit, consensus.Commit} {
+		qcEvent := leaderEvents[3+2*i]
+		require.Equal(t, uint32(step), qcEvent.QuorumCertificate.Step)
+		require.Equal(t, uint64(1), qcEvent.QuorumCertificate.Height)
+		require.GreaterOrEqual(t, qcEvent.QuorumCertificate.NumSignatures, uint32(3))
+	}
+
+	committedBlock := getCommittedBlock(t, pocketNodes[2], 1)
+	for nodeId, pocketNode := range pocketNodes {
+		nodeEvents := events[uint64(nodeId)]
+		require.Equal(t, types.ConsensusEventType_CONSENSUS_EVENT_LEADER_ELECTED, nodeEvents[1].Type)
+		require.Equal(t, uint64(2), nodeEvents[1].LeaderId)
+
+		commitEvent := nodeEvents[len(nodeEvents)-2]
+		require.Equal(t, types.ConsensusEventType_CONSENSUS_EVENT_BLOCK_COMMITTED, commitEvent.Type)
+		require.Equal(t, committedBlock.Block.BlockHeader.Hash, commitEvent.BlockHash)
+		require.Equal(t, uint32(consensus.Decide), commitEvent.Step)
+
+		newHeightEvent := nodeEvents[len(nodeEvents)-1]
+		require.Equal(t, types.ConsensusEventType_CONSENSUS_EVENT_NEW_HEIGHT, newHeightEvent.Type)
+		require.Equal(t, uint64(2), newHeightEvent.Height)
+		require.Equal(t, uint64(0), newHeightEvent.LeaderId)
+
+		// The state of the node once the block is committed
+		snapshot := pocketNode.GetBus().GetConsensusModule().StateSnapshot()
+		require.Equal(t, uint64(nodeId), snapshot.NodeId)
+		require.Equal(t, uint64(2), snapshot.Height)
+		require.Equal(t, uint32(consensus.NewRound), snapshot.Step)
+		require.Equal(t, committedBlock.Block.BlockHeader.Hash, snapshot.LastBlockHash)
+		require.Nil(t, snapshot.LockedQc)
+		require.Nil(t, snapshot.HighPrepareQc)
+		require.Empty(t, snapshot.MessagePoolCounts)
+	}
+}
+
+func TestConsensusEventsPublishedOnBus(t *testing.T) {
+	configs := GenerateNodeConfigs(t, 4)
+	pocketNode := CreateTestConsensusPocketNode(t, configs[0], make(modules.EventsChannel, 100))
+
+	// The event loop of the node is not started, so the test is the only reader of its bus
+	consensusMod := pocketNode.GetBus().GetConsensusModule()
+	require.NoError(t, consensusMod.Start())
+	defer consensusMod.Stop()
+	require.NoError(t, consensusMod.HandleDebugMessage(&types.DebugMessage{
+		Action: types.DebugMessageAction_DEBUG_CONSENSUS_TRIGGER_NEXT_VIEW,
+	}))
+
+	select {
+	case e := <-pocketNode.GetBus().GetEventBus():
+		require.Equal(t, types.PocketTopic_CONSENSUS_EVENT_TOPIC, e.Topic)
+		event := &types.ConsensusEvent{}
+		require.NoError(t, e.Data.UnmarshalTo(event))
+		require.Equal(t, types.ConsensusEventType_CONSENSUS_EVENT_NEW_HEIGHT, event.Type)
+		require.Equal(t, uint64(1), event.Height)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for a consensus event on the bus")
+	}
+}
+
+func TestConsensusStateSnapshotDuringViewChange(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 5, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	eventChannels := SubscribeToConsensusEvents(pocketNodes)
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	// Node 2, the leader of the first round, only receives its own NEWROUND message
+	network.Partition([]cryptoPocket.Address{pocketNodes[2].Address})
+	for _, pocketNode := range pocketNodes {
+		ScheduleNextView(t, network, pocketNode, 0)
+	}
+	network.RunUntilIdle(1000)
+
+	leaderSnapshot := pocketNodes[2].GetBus().GetConsensusModule().StateSnapshot()
+	require.True(t, leaderSnapshot.IsLeader)
+	require.Equal(t, uint64(2), leaderSnapshot.LeaderId)
+	require.Equal(t, map[uint32]uint32{uint32(consensus.NewRound): 1}, leaderSnapshot.MessagePoolCounts)
+
+	// Nodes 1 and 3 time out, which is not enough to form a TimeoutQC
+	network.Heal()
+	for _, eventChannel := range eventChannels {
+		DrainConsensusEvents(eventChannel)
+	}
+	ScheduleNextView(t, network, pocketNodes[1], time.Second)
+	ScheduleNextView(t, network, pocketNodes[3], time.Second)
+	network.RunUntilIdle(1000)
+
+	require.Equal(t, []types.ConsensusEventType{types.ConsensusEventType_CONSENSUS_EVENT_TIMEOUT}, eventTypes(DrainConsensusEvents(eventChannels[1])))
+	require.Empty(t, DrainConsensusEvents(eventChannels[4]))
+	snapshot := pocketNodes[4].GetBus().GetConsensusModule().StateSnapshot()
+	require.Equal(t, uint64(0), snapshot.Round)
+	require.Equal(t, uint32(2), snapshot.TimeoutPoolCount)
+	require.Nil(t, snapshot.TimeoutQc)
+
+	// Node 4 times out as well, so every node forms a TimeoutQC and moves on to the next round
+	ScheduleNextView(t, network, pocketNodes[4], 0)
+	network.Step()
+
+	events := DrainConsensusEvents(eventChannels[4])
+	require.Equal(t, []types.ConsensusEventType{
+		types.ConsensusEventType_CONSENSUS_EVENT_TIMEOUT,
+		types.ConsensusEventType_CONSENSUS_EVENT_QC_FORMED,
+		types.ConsensusEventType_CONSENSUS_EVENT_NEW_ROUND,
+	}, eventTypes(events))
+	require.Equal(t, uint64(0), events[1].QuorumCertificate.Round)
+	require.Equal(t, uint32(3), events[1].QuorumCertificate.NumSignatures)
+	require.Empty(t, events[1].QuorumCertificate.BlockHash)
+	require.Equal(t, uint64(1), events[2].Round)
+
+	snapshot = pocketNodes[4].GetBus().GetConsensusModule().StateSnapshot()
+	require.Equal(t, uint64(1), snapshot.Round)
+	require.Equal(t, uint64(0), snapshot.TimeoutQc.Round)
+}
+
+func eventTypes(events []*types.ConsensusEvent) []types.ConsensusEventType {
+	eventTypes := make([]types.ConsensusEventType, len(events))
+	for i, event := range events {
+		eventTypes[i] = event.Type
+	}
+	return eventTypes
+}
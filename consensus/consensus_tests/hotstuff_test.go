@@ -1,13 +1,29 @@
 package consensus_tests
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/pokt-network/pocket/consensus"
+	"github.com/pokt-network/pocket/consensus/leader_election/sortition"
 	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared"
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	typesUtil "github.com/pokt-network/pocket/utility/types"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 func TestHotstuff4Nodes1BlockHappyPath(t *testing.T) {
@@ -47,6 +63,7 @@ func TestHotstuff4Nodes1BlockHappyPath(t *testing.T) {
 	// Prepare
 	prepareProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
 	require.NoError(t, err)
+	WaitForConsensusNodesStep(t, pocketNodes, 1, consensus.Prepare, 1000)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
 		require.Equal(t, uint64(1), nodeState.Height)
@@ -67,6 +84,7 @@ func TestHotstuff4Nodes1BlockHappyPath(t *testing.T) {
 
 	preCommitProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.PreCommit, consensus.Propose, 1, 1000)
 	require.NoError(t, err)
+	WaitForConsensusNodesStep(t, pocketNodes, 1, consensus.PreCommit, 1000)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
 		require.Equal(t, uint64(1), nodeState.Height)
@@ -87,6 +105,7 @@ func TestHotstuff4Nodes1BlockHappyPath(t *testing.T) {
 
 	commitProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Commit, consensus.Propose, 1, 1000)
 	require.NoError(t, err)
+	WaitForConsensusNodesStep(t, pocketNodes, 1, consensus.Commit, 1000)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
 		require.Equal(t, uint64(1), nodeState.Height)
@@ -107,6 +126,7 @@ func TestHotstuff4Nodes1BlockHappyPath(t *testing.T) {
 
 	decideProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Decide, consensus.Propose, 1, 1000)
 	require.NoError(t, err)
+	WaitForConsensusNodesStep(t, IdToNodeMapping{1: pocketNodes[1], 3: pocketNodes[3], 4: pocketNodes[4]}, 1, consensus.Decide, 1000)
 	for pocketId, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
 		// Leader has already committed the block and hence moved to the next height.
@@ -138,6 +158,1208 @@ func TestHotstuff4Nodes1BlockHappyPath(t *testing.T) {
 	}
 }
 
+func TestHotstuffReplicaRejectsForgedQuorumCertificate(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	block := placeholderBlock(t, testHeight, leader)
+
+	// All the nodes are waiting for the leader's PRECOMMIT proposal
+	for _, pocketNode := range pocketNodes {
+		consensusModImpl := GetConsensusModImplementation(pocketNode)
+		consensusModImpl.FieldByName("Height").SetUint(testHeight)
+		consensusModImpl.FieldByName("Round").SetUint(testRound)
+		consensusModImpl.FieldByName("Step").SetInt(int64(consensus.PreCommit))
+		consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+	}
+
+	// The byzantine leader pads the QC with its own partial signature to meet the optimistic threshold
+	leaderPartialSig := SignVote(t, configs[leaderId-1].PrivateKey, testHeight, consensus.Prepare, testRound, block)
+	forgedQC := &typesCons.QuorumCertificate{
+		Height:    testHeight,
+		Step:      consensus.Prepare,
+		Round:     testRound,
+		Block:     block,
+		BlockHash: block.BlockHeader.Hash,
+		ThresholdSignature: &typesCons.ThresholdSignature{
+			Signatures: []*typesCons.PartialSignature{leaderPartialSig, leaderPartialSig, leaderPartialSig},
+		},
+	}
+	preCommitProposal := &typesCons.HotstuffMessage{
+		Type:   consensus.Propose,
+		Height: testHeight,
+		Step:   consensus.PreCommit,
+		Round:  testRound,
+		Block:  block,
+		Justification: &typesCons.HotstuffMessage_QuorumCertificate{
+			QuorumCertificate: forgedQC,
+		},
+	}
+	anyMsg, err := anypb.New(preCommitProposal)
+	require.NoError(t, err)
+	P2PBroadcast(t, pocketNodes, anyMsg)
+
+	// Every replica rejects the QC and times out instead of voting
+	timeoutMessages, err := WaitForNetworkTimeoutMessages(t, testChannel, testRound, numNodes-1, 1000)
+	require.NoError(t, err)
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.PreCommit, consensus.Vote, 0, 500)
+	require.NoError(t, err)
+
+	// The timeouts of the replicas form a TimeoutQC that moves every node on to the next round
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+
+	for nodeId, pocketNode := range pocketNodes {
+		if nodeId == leaderId {
+			continue
+		}
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, testHeight, nodeState.Height)
+		require.Equal(t, uint8(consensus.NewRound), nodeState.Step)
+		require.Equal(t, uint8(testRound+1), nodeState.Round)
+	}
+}
+
+func TestHotstuffReplicaRejectsOversizedBlock(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	block := placeholderBlock(t, testHeight, leader)
+
+	// The serialized block is one byte larger than what the replicas accept
+	for _, config := range configs {
+		config.Consensus.MaxBlockBytes = uint64(proto.Size(block)) - 1
+	}
+
+	// All the nodes are waiting for the leader's PREPARE proposal
+	for _, pocketNode := range pocketNodes {
+		consensusModImpl := GetConsensusModImplementation(pocketNode)
+		consensusModImpl.FieldByName("Height").SetUint(testHeight)
+		consensusModImpl.FieldByName("Round").SetUint(testRound)
+		consensusModImpl.FieldByName("Step").SetInt(int64(consensus.Prepare))
+		consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+	}
+
+	prepareProposal := &typesCons.HotstuffMessage{
+		Type:   consensus.Propose,
+		Height: testHeight,
+		Step:   consensus.Prepare,
+		Round:  testRound,
+		Block:  block,
+	}
+	anyMsg, err := anypb.New(prepareProposal)
+	require.NoError(t, err)
+	P2PBroadcast(t, pocketNodes, anyMsg)
+
+	// Every replica rejects the block and times out instead of voting
+	timeoutMessages, err := WaitForNetworkTimeoutMessages(t, testChannel, testRound, numNodes-1, 1000)
+	require.NoError(t, err)
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, 0, 500)
+	require.NoError(t, err)
+
+	// The timeouts of the replicas form a TimeoutQC that moves every node on to the next round
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+
+	for nodeId, pocketNode := range pocketNodes {
+		if nodeId == leaderId {
+			continue
+		}
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, testHeight, nodeState.Height)
+		require.Equal(t, uint8(consensus.NewRound), nodeState.Step)
+		require.Equal(t, uint8(testRound+1), nodeState.Round)
+	}
+}
+
+func TestHotstuffLeaderBoundsMessagePoolSize(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	block := placeholderBlock(t, testHeight, leader)
+
+	// The leader proposed `block` and is waiting for PREPARE votes
+	consensusModImpl := GetConsensusModImplementation(leader)
+	consensusModImpl.FieldByName("Height").SetUint(testHeight)
+	consensusModImpl.FieldByName("Round").SetUint(testRound)
+	consensusModImpl.FieldByName("Step").SetInt(int64(consensus.Prepare))
+	consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+	consensusModImpl.FieldByName("Block").Set(reflect.ValueOf(block))
+
+	votes := make([]*typesCons.HotstuffMessage, 0, numNodes-1)
+	for nodeId := typesCons.NodeId(1); nodeId < typesCons.NodeId(numNodes); nodeId++ {
+		votes = append(votes, &typesCons.HotstuffMessage{
+			Type:   consensus.Vote,
+			Height: testHeight,
+			Step:   consensus.Prepare,
+			Round:  testRound,
+			Block:  block,
+			Justification: &typesCons.HotstuffMessage_PartialSignature{
+				PartialSignature: SignVote(t, configs[nodeId-1].PrivateKey, testHeight, consensus.Prepare, testRound, block),
+			},
+		})
+	}
+
+	// The message pool only has room for two of the three votes needed to form a PREPARE QC
+	configs[leaderId-1].Consensus.MaxMempoolBytes = uint64(proto.Size(votes[0]) + proto.Size(votes[1]))
+	for _, vote := range votes {
+		anyMsg, err := anypb.New(vote)
+		require.NoError(t, err)
+		P2PSend(t, leader, anyMsg)
+	}
+
+	// The last vote is dropped so the leader cannot propose the PRECOMMIT step
+	_, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.PreCommit, consensus.Propose, 0, 500)
+	require.NoError(t, err)
+
+	messagePoolCounts := leader.GetBus().GetConsensusModule().StateSnapshot().MessagePoolCounts
+	require.Equal(t, uint32(2), messagePoolCounts[uint32(consensus.Prepare)])
+}
+
+func TestHotstuffLeaderIgnoresDuplicateVotes(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	block := placeholderBlock(t, testHeight, leader)
+
+	// The leader proposed `block` and is waiting for PREPARE votes
+	consensusModImpl := GetConsensusModImplementation(leader)
+	consensusModImpl.FieldByName("Height").SetUint(testHeight)
+	consensusModImpl.FieldByName("Round").SetUint(testRound)
+	consensusModImpl.FieldByName("Step").SetInt(int64(consensus.Prepare))
+	consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+	consensusModImpl.FieldByName("Block").Set(reflect.ValueOf(block))
+
+	votes := make([]*typesCons.HotstuffMessage, 0, numNodes-1)
+	for nodeId := typesCons.NodeId(1); nodeId < typesCons.NodeId(numNodes); nodeId++ {
+		votes = append(votes, &typesCons.HotstuffMessage{
+			Type:   consensus.Vote,
+			Height: testHeight,
+			Step:   consensus.Prepare,
+			Round:  testRound,
+			Block:  block,
+			Justification: &typesCons.HotstuffMessage_PartialSignature{
+				PartialSignature: SignVote(t, configs[nodeId-1].PrivateKey, testHeight, consensus.Prepare, testRound, block),
+			},
+		})
+	}
+
+	// The message pool only has room for the three votes needed to form a PREPARE QC, and the first validator
+	// sends its vote several times before the others do
+	configs[leaderId-1].Consensus.MaxMempoolBytes = uint64(proto.Size(votes[0]) + proto.Size(votes[1]) + proto.Size(votes[2]))
+	for _, vote := range []*typesCons.HotstuffMessage{votes[0], votes[0], votes[0], votes[1], votes[2]} {
+		anyMsg, err := anypb.New(vote)
+		require.NoError(t, err)
+		P2PSend(t, leader, anyMsg)
+	}
+
+	// The duplicates are not aggregated, so the leader still forms the PREPARE QC
+	_, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.PreCommit, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+}
+
+func TestHotstuffLeaderReportsDoubleSignedVotes(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	block := placeholderBlock(t, testHeight, leader)
+
+	// The leader proposed `block` and is waiting for PREPARE votes
+	consensusModImpl := GetConsensusModImplementation(leader)
+	consensusModImpl.FieldByName("Height").SetUint(testHeight)
+	consensusModImpl.FieldByName("Round").SetUint(testRound)
+	consensusModImpl.FieldByName("Step").SetInt(int64(consensus.Prepare))
+	consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+	consensusModImpl.FieldByName("Block").Set(reflect.ValueOf(block))
+
+	// The byzantine validator votes for the proposed block and for a conflicting one in the same view
+	byzantineId := typesCons.NodeId(3)
+	byzantineKey := configs[byzantineId-1].PrivateKey
+	conflictingBlock := placeholderBlock(t, testHeight, pocketNodes[byzantineId])
+	for _, votedBlock := range []*types.Block{block, conflictingBlock} {
+		vote := &typesCons.HotstuffMessage{
+			Type:   consensus.Vote,
+			Height: testHeight,
+			Step:   consensus.Prepare,
+			Round:  testRound,
+			Block:  votedBlock,
+			Justification: &typesCons.HotstuffMessage_PartialSignature{
+				PartialSignature: SignVote(t, byzantineKey, testHeight, consensus.Prepare, testRound, votedBlock),
+			},
+		}
+		anyMsg, err := anypb.New(vote)
+		require.NoError(t, err)
+		P2PSend(t, leader, anyMsg)
+	}
+
+	// The leader submits evidence of the equivocation to the utility mempool
+	txs, err := WaitForUtilityTransactions(t, testChannel, 1, 1000)
+	require.NoError(t, err)
+	require.Nil(t, txs[0].ValidateBasic())
+	msg, er := txs[0].Message()
+	require.Nil(t, er)
+	doubleSign, ok := msg.(*typesUtil.MessageDoubleSign)
+	require.True(t, ok)
+	require.Nil(t, doubleSign.ValidateBasic())
+	require.Equal(t, []byte(leader.Address), doubleSign.ReporterAddress)
+	require.Equal(t, byzantineKey.PublicKey().Bytes(), doubleSign.VoteA.PublicKey)
+	require.Equal(t, int64(testHeight), doubleSign.VoteA.Height)
+
+	// The evidence is also gossiped to the rest of the network
+	utilityMessages, err := WaitForNetworkUtilityMessages(t, testChannel, 1, 1000)
+	require.NoError(t, err)
+	gossipedTx, err := typesUtil.TransactionFromBytes(utilityMessages[0].Transaction)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(txs[0], gossipedTx))
+
+	// Only the first vote counts towards the PREPARE QC
+	messagePool := consensusModImpl.FieldByName("MessagePool").MapIndex(reflect.ValueOf(consensus.Prepare))
+	require.Equal(t, 1, messagePool.Len())
+}
+
+func TestHotstuffLeaderReportsDoubleSignOnce(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	block := placeholderBlock(t, testHeight, leader)
+
+	// The leader proposed `block` and is waiting for PREPARE votes
+	consensusModImpl := GetConsensusModImplementation(leader)
+	consensusModImpl.FieldByName("Height").SetUint(testHeight)
+	consensusModImpl.FieldByName("Round").SetUint(testRound)
+	consensusModImpl.FieldByName("Step").SetInt(int64(consensus.Prepare))
+	consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+	consensusModImpl.FieldByName("Block").Set(reflect.ValueOf(block))
+
+	// The byzantine validator votes for the proposed block, and the conflicting vote is received several times
+	byzantineId := typesCons.NodeId(3)
+	byzantineKey := configs[byzantineId-1].PrivateKey
+	conflictingBlock := placeholderBlock(t, testHeight, pocketNodes[byzantineId])
+	for _, votedBlock := range []*types.Block{block, conflictingBlock, conflictingBlock, conflictingBlock} {
+		vote := &typesCons.HotstuffMessage{
+			Type:   consensus.Vote,
+			Height: testHeight,
+			Step:   consensus.Prepare,
+			Round:  testRound,
+			Block:  votedBlock,
+			Justification: &typesCons.HotstuffMessage_PartialSignature{
+				PartialSignature: SignVote(t, byzantineKey, testHeight, consensus.Prepare, testRound, votedBlock),
+			},
+		}
+		anyMsg, err := anypb.New(vote)
+		require.NoError(t, err)
+		P2PSend(t, leader, anyMsg)
+	}
+
+	// The equivocation is only submitted once, with the step it happened at
+	txs, err := WaitForUtilityTransactions(t, testChannel, 1, 1000)
+	require.NoError(t, err)
+	msg, er := txs[0].Message()
+	require.Nil(t, er)
+	doubleSign, ok := msg.(*typesUtil.MessageDoubleSign)
+	require.True(t, ok)
+	require.Equal(t, uint32(consensus.Prepare), doubleSign.VoteA.Step)
+	require.Equal(t, uint32(consensus.Prepare), doubleSign.VoteB.Step)
+
+	_, err = WaitForUtilityTransactions(t, testChannel, 1, 500)
+	require.Error(t, err)
+}
+
+func TestHotstuffTracksValidatorsMissingFromCommitQC(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.RootDir = t.TempDir()
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	absentId := typesCons.NodeId(4)
+	absentAddress := pocketNodes[absentId].Address
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare, PreCommit & Commit
+	for _, step := range []typesCons.HotstuffStep{consensus.Prepare, consensus.PreCommit, consensus.Commit} {
+		proposal, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Propose, 1, 1000)
+		require.NoError(t, err)
+		for _, message := range proposal {
+			P2PBroadcast(t, pocketNodes, message)
+		}
+
+		votes, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Vote, numNodes, 1000)
+		require.NoError(t, err)
+		for _, vote := range votes {
+			var hotstuffVote typesCons.HotstuffMessage
+			require.NoError(t, vote.UnmarshalTo(&hotstuffVote))
+			// The COMMIT vote of one validator never reaches the leader
+			if step == consensus.Commit && hotstuffVote.GetPartialSignature().Address == absentAddress.String() {
+				continue
+			}
+			P2PSend(t, leader, vote)
+		}
+	}
+
+	// Decide
+	decideProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Decide, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	for _, message := range decideProposal {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+
+	// Every node records the validator missing from the COMMIT QC alongside the committed block
+	for _, pocketNode := range pocketNodes {
+		require.Equal(t, uint64(2), GetConsensusNodeState(pocketNode).Height)
+		committedBlocks := GetConsensusModImplementation(pocketNode).FieldByName("CommittedBlocks")
+		committedBlock := committedBlocks.MapIndex(reflect.ValueOf(uint64(1))).Interface().(*typesCons.CommittedBlock)
+		require.Equal(t, [][]byte{absentAddress}, committedBlock.MissedValidators)
+	}
+
+	// The committed block is persisted alongside the validators missing from its COMMIT QC, so a restarted node
+	// still serves them to lagging nodes
+	restartedId := typesCons.NodeId(1)
+	require.NoError(t, pocketNodes[restartedId].GetBus().GetConsensusModule().Stop())
+	restartedNode := CreateTestConsensusPocketNode(t, configs[restartedId-1], testChannel)
+	StartAllTestPocketNodes(t, IdToNodeMapping{restartedId: restartedNode})
+	require.Equal(t, 0, GetConsensusModImplementation(restartedNode).FieldByName("CommittedBlocks").Len())
+
+	request, err := anypb.New(&typesCons.BlockSyncRequest{
+		Height:           1,
+		RequesterAddress: pocketNodes[absentId].Address.String(),
+	})
+	require.NoError(t, err)
+	P2PSend(t, restartedNode, request)
+
+	responses, err := WaitForNetworkBlockSyncMessages(t, testChannel, consensus.BlockSyncResponseMessage, 1, 1000)
+	require.NoError(t, err)
+	var response typesCons.BlockSyncResponse
+	require.NoError(t, responses[0].UnmarshalTo(&response))
+	require.Equal(t, int64(1), response.CommittedBlock.Block.BlockHeader.Height)
+	require.Equal(t, [][]byte{absentAddress}, response.CommittedBlock.MissedValidators)
+}
+
+func TestHotstuffLateCommitVoteIsOnlyAMissedBlock(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+	lateId := typesCons.NodeId(4)
+	lateAddress := pocketNodes[lateId].Address
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare, PreCommit & Commit
+	var lateVote *anypb.Any
+	for _, step := range []typesCons.HotstuffStep{consensus.Prepare, consensus.PreCommit, consensus.Commit} {
+		proposal, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Propose, 1, 1000)
+		require.NoError(t, err)
+		for _, message := range proposal {
+			P2PBroadcast(t, pocketNodes, message)
+		}
+
+		votes, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Vote, numNodes, 1000)
+		require.NoError(t, err)
+		for _, vote := range votes {
+			var hotstuffVote typesCons.HotstuffMessage
+			require.NoError(t, vote.UnmarshalTo(&hotstuffVote))
+			// The honest COMMIT vote of one validator only reaches the leader after the QC is formed
+			if step == consensus.Commit && hotstuffVote.GetPartialSignature().Address == lateAddress.String() {
+				lateVote = vote
+				continue
+			}
+			P2PSend(t, leader, vote)
+		}
+	}
+	require.NotNil(t, lateVote)
+
+	// Decide
+	decideProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Decide, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	P2PSend(t, leader, lateVote)
+	for _, message := range decideProposal {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+
+	// Every node records the late validator as having missed the block, which utility only counts against its
+	// liveness, and no evidence is submitted against it
+	for _, pocketNode := range pocketNodes {
+		require.Equal(t, uint64(2), GetConsensusNodeState(pocketNode).Height)
+		missedValidators := GetConsensusModImplementation(pocketNode).FieldByName("lastMissedValidators")
+		require.Equal(t, 1, missedValidators.Len())
+		require.Equal(t, []byte(lateAddress), missedValidators.Index(0).Bytes())
+	}
+	_, err = WaitForUtilityTransactions(t, testChannel, 1, 500)
+	require.Error(t, err)
+}
+
+func TestHotstuffAppliesValidatorUpdatesOnCommit(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Applying the block at height 1 removes one validator and adds a new one
+	leavingId := typesCons.NodeId(4)
+	leavingAddress := pocketNodes[leavingId].Address
+	leavingValidator := pocketNodes[leavingId].GetBus().GetConsensusModule().ValidatorMap()[leavingAddress.String()]
+
+	joiningKey, err := cryptoPocket.GeneratePrivateKey()
+	require.NoError(t, err)
+	joiningValidator := &genesis.Validator{
+		Address:      joiningKey.Address(),
+		PublicKey:    joiningKey.PublicKey().Bytes(),
+		StakedTokens: leavingValidator.StakedTokens,
+		ServiceUrl:   leavingValidator.ServiceUrl,
+		Output:       joiningKey.Address(),
+	}
+
+	testValidatorUpdates[1] = []*modules.ValidatorUpdate{
+		{Validator: leavingValidator, Removed: true},
+		{Validator: joiningValidator},
+	}
+	t.Cleanup(func() { delete(testValidatorUpdates, 1) })
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare, PreCommit & Commit
+	for _, step := range []typesCons.HotstuffStep{consensus.Prepare, consensus.PreCommit, consensus.Commit} {
+		proposal, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Propose, 1, 1000)
+		require.NoError(t, err)
+		for _, message := range proposal {
+			P2PBroadcast(t, pocketNodes, message)
+		}
+
+		votes, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Vote, numNodes, 1000)
+		require.NoError(t, err)
+		for _, vote := range votes {
+			P2PSend(t, leader, vote)
+		}
+	}
+
+	// Decide
+	decideProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Decide, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	for _, message := range decideProposal {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+
+	// Every node switches to the updated validator set and recomputes the node ids derived from it
+	expectedValidatorMap := make(modules.ValidatorMap)
+	for address, validator := range pocketNodes[leaderId].GetBus().GetConsensusModule().ValidatorMap() {
+		expectedValidatorMap[address] = validator
+	}
+	require.Len(t, expectedValidatorMap, numNodes)
+	require.NotContains(t, expectedValidatorMap, leavingAddress.String())
+	require.Contains(t, expectedValidatorMap, joiningKey.Address().String())
+	expectedValIdMap, _ := typesCons.GetValAddrToIdMap(expectedValidatorMap)
+
+	for _, pocketNode := range pocketNodes {
+		require.Equal(t, uint64(2), GetConsensusNodeState(pocketNode).Height)
+		require.Equal(t, expectedValidatorMap, pocketNode.GetBus().GetConsensusModule().ValidatorMap())
+
+		// The node that left the validator set is no longer assigned an id
+		expectedNodeId := expectedValIdMap[pocketNode.Address.String()]
+		require.Equal(t, expectedNodeId, GetConsensusNodeState(pocketNode).NodeId)
+	}
+	require.Equal(t, typesCons.NodeId(0), GetConsensusNodeState(pocketNodes[leavingId]).NodeId)
+}
+
+func TestHotstuffBlocksFormHashChain(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare, PreCommit & Commit
+	for _, step := range []typesCons.HotstuffStep{consensus.Prepare, consensus.PreCommit, consensus.Commit} {
+		proposal, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Propose, 1, 1000)
+		require.NoError(t, err)
+		for _, message := range proposal {
+			P2PBroadcast(t, pocketNodes, message)
+		}
+
+		votes, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Vote, numNodes, 1000)
+		require.NoError(t, err)
+		for _, vote := range votes {
+			P2PSend(t, leader, vote)
+		}
+	}
+
+	// Decide
+	decideProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Decide, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	for _, message := range decideProposal {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Height 2: NewRound
+	newRoundMessages, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+
+	// The leader committed the block before starting height 2. The block hash commits to the header, which is
+	// separate from the app hash returned by utility
+	committedBlocks := GetConsensusModImplementation(leader).FieldByName("CommittedBlocks")
+	committedBlock := committedBlocks.MapIndex(reflect.ValueOf(uint64(1))).Interface().(*typesCons.CommittedBlock)
+	block1Header := committedBlock.Block.BlockHeader
+	block1Hash, err := block1Header.ComputeHash()
+	require.NoError(t, err)
+	require.Equal(t, block1Hash, block1Header.Hash)
+	require.Equal(t, hex.EncodeToString(appHash), block1Header.AppHash)
+	require.Equal(t, types.TransactionsRoot(committedBlock.Block.Transactions), block1Header.TransactionsRoot)
+	require.Equal(t, block1Hash, committedBlock.CommitQc.BlockHash)
+
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Height 2: the proposed block extends the block committed at height 1 and carries its COMMIT QC
+	prepareProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	var proposal typesCons.HotstuffMessage
+	require.NoError(t, prepareProposal[0].UnmarshalTo(&proposal))
+	block2Header := proposal.Block.BlockHeader
+	require.Equal(t, int64(2), block2Header.Height)
+	require.Equal(t, block1Hash, block2Header.LastBlockHash)
+
+	var lastCommitQC typesCons.QuorumCertificate
+	require.NoError(t, proto.Unmarshal(block2Header.QuorumCertificate, &lastCommitQC))
+	require.Nil(t, lastCommitQC.Block)
+	require.Equal(t, uint64(1), lastCommitQC.Height)
+	require.Equal(t, consensus.Commit, lastCommitQC.Step)
+	require.Equal(t, block1Hash, lastCommitQC.BlockHash)
+	require.Equal(t, len(committedBlock.CommitQc.ThresholdSignature.Signatures), len(lastCommitQC.ThresholdSignature.Signatures))
+
+	// Every replica validates the chain and votes for the block
+	for _, message := range prepareProposal {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes, 1000)
+	require.NoError(t, err)
+}
+
+func TestHotstuff4NodesLeaderElection(t *testing.T) {
+	for _, leaderElection := range []config.LeaderElectionType{config.RoundRobinLeaderElection, config.VRFSortitionLeaderElection} {
+		t.Run(string(leaderElection), func(t *testing.T) {
+			testHotstuff4NodesLeaderElection(t, leaderElection)
+		})
+	}
+}
+
+func testHotstuff4NodesLeaderElection(t *testing.T, leaderElection config.LeaderElectionType) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.Consensus.LeaderElection = leaderElection
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare: with round robin, exactly one node is elected and proposes a block. With VRF sortition, a node may
+	// propose once it received the leader claims of enough stake and be outranked by a claim that arrives later.
+	prepareProposals, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	if leaderElection == config.VRFSortitionLeaderElection {
+		for {
+			moreProposals, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 200)
+			if err != nil {
+				break
+			}
+			prepareProposals = append(prepareProposals, moreProposals...)
+		}
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 0, 200)
+	require.NoError(t, err)
+
+	// The proposal carrying the highest ranked leader claim is the one every node accepts
+	var leaderProposal *anypb.Any
+	var leaderClaim *typesCons.LeaderClaim
+	for _, message := range prepareProposals {
+		var prepareProposal typesCons.HotstuffMessage
+		require.NoError(t, message.UnmarshalTo(&prepareProposal))
+		switch leaderElection {
+		case config.RoundRobinLeaderElection:
+			require.Nil(t, prepareProposal.LeaderClaim)
+			leaderProposal = message
+		case config.VRFSortitionLeaderElection:
+			require.NotNil(t, prepareProposal.LeaderClaim)
+			if leaderClaim == nil || outranksLeaderClaim(t, pocketNodes[1], prepareProposal.LeaderClaim, leaderClaim) {
+				leaderProposal, leaderClaim = message, prepareProposal.LeaderClaim
+			}
+		}
+	}
+	P2PBroadcast(t, pocketNodes, leaderProposal)
+
+	// Every node agrees on the leader and votes for its proposal
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes, 1000)
+	require.NoError(t, err)
+
+	leaderId := GetConsensusNodeState(pocketNodes[1]).LeaderId
+	leader := pocketNodes[leaderId]
+	require.NotNil(t, leader)
+	switch leaderElection {
+	case config.RoundRobinLeaderElection:
+		require.Equal(t, typesCons.NodeId(2), leaderId)
+	case config.VRFSortitionLeaderElection:
+		require.Equal(t, leader.Address.String(), leaderClaim.Address)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	for nodeId, pocketNode := range pocketNodes {
+		snapshot := pocketNode.GetBus().GetConsensusModule().StateSnapshot()
+		require.Equal(t, uint64(1), snapshot.Height)
+		require.Equal(t, uint64(0), snapshot.Round)
+		require.Equal(t, uint64(leaderId), snapshot.LeaderId, fmt.Sprintf("%d should be the current leader", leaderId))
+		require.Equal(t, nodeId == leaderId, snapshot.IsLeader)
+	}
+}
+
+// Mirrors the ranking of leader candidates in `leader_election`: candidates are ranked by their sortition result,
+// then by their VRF output and finally by their address.
+func outranksLeaderClaim(t *testing.T, pocketNode *shared.Node, claim, other *typesCons.LeaderClaim) bool {
+	const numExpectedLeaderCandidates = 3
+
+	validatorMap := pocketNode.GetBus().GetConsensusModule().ValidatorMap()
+	networkStake := uint64(0)
+	for _, validator := range validatorMap {
+		stake, err := types.StringToBigInt(validator.StakedTokens)
+		require.NoError(t, err)
+		networkStake += stake.Uint64()
+	}
+	sortitionResult := func(claim *typesCons.LeaderClaim) sortition.SortitionResult {
+		stake, err := types.StringToBigInt(validatorMap[claim.Address].StakedTokens)
+		require.NoError(t, err)
+		return sortition.Sortition(stake.Uint64(), networkStake, numExpectedLeaderCandidates, claim.VrfOutput)
+	}
+
+	if result, otherResult := sortitionResult(claim), sortitionResult(other); result != otherResult {
+		return result > otherResult
+	}
+	if cmp := bytes.Compare(claim.VrfOutput, other.VrfOutput); cmp != 0 {
+		return cmp > 0
+	}
+	return claim.Address < other.Address
+}
+
+func TestHotstuffReplicaRestoresStateFromWAL(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.RootDir = t.TempDir()
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare
+	prepareProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	for _, message := range prepareProposal {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes, 1000)
+	require.NoError(t, err)
+
+	// The replica crashes right after voting for the proposal
+	replicaId := typesCons.NodeId(1)
+	replica := pocketNodes[replicaId]
+	time.Sleep(10 * time.Millisecond)
+	stateBeforeCrash := GetConsensusNodeState(replica)
+	blockBeforeCrash := GetConsensusModImplementation(replica).FieldByName("Block").Interface().(*types.Block)
+	require.Equal(t, uint8(consensus.PreCommit), stateBeforeCrash.Step)
+	require.NotNil(t, blockBeforeCrash)
+	require.NoError(t, replica.GetBus().GetConsensusModule().Stop())
+
+	// The restarted replica restores its state from the WAL and resends its last vote
+	restartedReplica := CreateTestConsensusPocketNode(t, configs[replicaId-1], testChannel)
+	StartAllTestPocketNodes(t, IdToNodeMapping{replicaId: restartedReplica})
+
+	resentVotes, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, 1, 1000)
+	require.NoError(t, err)
+	var resentVote typesCons.HotstuffMessage
+	require.NoError(t, resentVotes[0].UnmarshalTo(&resentVote))
+	require.Equal(t, replica.Address.String(), resentVote.GetPartialSignature().Address)
+
+	stateAfterRestart := GetConsensusNodeState(restartedReplica)
+	require.Equal(t, stateBeforeCrash, stateAfterRestart)
+	blockAfterRestart := GetConsensusModImplementation(restartedReplica).FieldByName("Block").Interface().(*types.Block)
+	require.True(t, proto.Equal(blockBeforeCrash, blockAfterRestart))
+}
+
+func TestHotstuffReplicaRestoresLockFromWAL(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.RootDir = t.TempDir()
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	// NewRound
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Prepare, PreCommit and Commit: the replicas lock on the block once they receive the COMMIT proposal
+	for _, step := range []typesCons.HotstuffStep{consensus.Prepare, consensus.PreCommit, consensus.Commit} {
+		proposals, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Propose, 1, 1000)
+		require.NoError(t, err)
+		for _, message := range proposals {
+			P2PBroadcast(t, pocketNodes, message)
+		}
+		votes, err := WaitForNetworkConsensusMessages(t, testChannel, step, consensus.Vote, numNodes, 1000)
+		require.NoError(t, err)
+		if step == consensus.Commit {
+			break
+		}
+		for _, message := range votes {
+			P2PSend(t, pocketNodes[2], message)
+		}
+	}
+
+	// The replica crashes once it is locked
+	replicaId := typesCons.NodeId(1)
+	replica := pocketNodes[replicaId]
+	time.Sleep(10 * time.Millisecond)
+	lockedQCBeforeCrash := GetConsensusModImplementation(replica).FieldByName("LockedQC").Interface().(*typesCons.QuorumCertificate)
+	highPrepareQCBeforeCrash := GetConsensusModImplementation(replica).FieldByName("HighPrepareQC").Interface().(*typesCons.QuorumCertificate)
+	require.NotNil(t, lockedQCBeforeCrash)
+	require.NotNil(t, highPrepareQCBeforeCrash)
+	require.NoError(t, replica.GetBus().GetConsensusModule().Stop())
+
+	// The restarted replica is still locked on the block and resends its COMMIT vote
+	restartedReplica := CreateTestConsensusPocketNode(t, configs[replicaId-1], testChannel)
+	StartAllTestPocketNodes(t, IdToNodeMapping{replicaId: restartedReplica})
+
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Commit, consensus.Vote, 1, 1000)
+	require.NoError(t, err)
+
+	lockedQCAfterRestart := GetConsensusModImplementation(restartedReplica).FieldByName("LockedQC").Interface().(*typesCons.QuorumCertificate)
+	highPrepareQCAfterRestart := GetConsensusModImplementation(restartedReplica).FieldByName("HighPrepareQC").Interface().(*typesCons.QuorumCertificate)
+	require.True(t, proto.Equal(lockedQCBeforeCrash, lockedQCAfterRestart))
+	require.True(t, proto.Equal(highPrepareQCBeforeCrash, highPrepareQCAfterRestart))
+	require.Equal(t, uint8(consensus.Decide), GetConsensusNodeState(restartedReplica).Step)
+}
+
+func TestHotstuffReplicaDoesNotReapplyCommittedBlockFromWAL(t *testing.T) {
+	for _, committed := range []bool{false, true} {
+		// Test configs
+		numNodes := 4
+		configs := GenerateNodeConfigs(t, numNodes)
+		replicaId := typesCons.NodeId(1)
+		replicaCfg := configs[replicaId-1]
+		replicaCfg.RootDir = t.TempDir()
+
+		// The replica crashed while deciding on the block at height 1, either before or after persistence committed it
+		proposer := &shared.Node{Address: configs[1].PrivateKey.Address()}
+		block := placeholderBlock(t, 1, proposer)
+		writeWALEntry(t, replicaCfg.RootDir, &typesCons.WALEntry{
+			Height:        1,
+			Round:         0,
+			Step:          consensus.Decide,
+			Block:         block,
+			AppHash:       block.BlockHeader.AppHash,
+			LastBlockHash: block.BlockHeader.Hash,
+			Committed:     committed,
+		})
+
+		// Create & start the restarted replica
+		testChannel := make(modules.EventsChannel, 100)
+		restartedReplica := CreateTestConsensusPocketNode(t, replicaCfg, testChannel)
+		StartAllTestPocketNodes(t, IdToNodeMapping{replicaId: restartedReplica})
+
+		// A committed block is not applied again and the replica resumes at the next height; otherwise the block is
+		// applied again to a new utility context
+		nodeState := GetConsensusNodeState(restartedReplica)
+		consensusModImpl := GetConsensusModImplementation(restartedReplica)
+		if committed {
+			require.Equal(t, uint64(2), nodeState.Height)
+			require.Equal(t, uint8(consensus.NewRound), nodeState.Step)
+			require.True(t, consensusModImpl.FieldByName("Block").IsNil())
+			require.True(t, consensusModImpl.FieldByName("utilityContext").IsNil())
+		} else {
+			require.Equal(t, uint64(1), nodeState.Height)
+			require.Equal(t, uint8(consensus.Decide), nodeState.Step)
+			require.False(t, consensusModImpl.FieldByName("utilityContext").IsNil())
+		}
+		require.NoError(t, restartedReplica.GetBus().GetConsensusModule().Stop())
+	}
+}
+
+// Writes `entry` as the only entry of the consensus WAL in `rootDir`, using the length prefixed encoding of the WAL.
+func writeWALEntry(t *testing.T, rootDir string, entry *typesCons.WALEntry) {
+	entryBz, err := proto.Marshal(entry)
+	require.NoError(t, err)
+	bz := make([]byte, 4, 4+len(entryBz))
+	binary.BigEndian.PutUint32(bz, uint32(len(entryBz)))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "consensus.wal"), append(bz, entryBz...), 0600))
+}
+
+func TestHotstuffValidatorWithLockedQC(t *testing.T) {
+	numNodes := 4
+	testHeight := uint64(1)
+	lockedRound := uint64(0)
+	leaderId := typesCons.NodeId(2)
+
+	testCases := []struct {
+		name string
+		// The round the proposal is made in, and the QC justifying it, if any
+		proposalRound uint64
+		justifyQC     func(configs []*config.Config, lockedBlock, conflictingBlock *types.Block) *typesCons.QuorumCertificate
+		// Whether the byzantine leader proposes a block that conflicts with the one the replicas are locked on
+		proposeConflictingBlock bool
+		expectVotes             bool
+	}{
+		{
+			name:                    "conflicting block without a justify QC",
+			proposalRound:           1,
+			justifyQC:               func([]*config.Config, *types.Block, *types.Block) *typesCons.QuorumCertificate { return nil },
+			proposeConflictingBlock: true,
+			expectVotes:             false,
+		},
+		{
+			name:          "conflicting block with the QC of the locked block",
+			proposalRound: 1,
+			justifyQC: func(configs []*config.Config, lockedBlock, _ *types.Block) *typesCons.QuorumCertificate {
+				return SignQuorumCertificate(t, configs, testHeight, consensus.Prepare, lockedRound, lockedBlock)
+			},
+			proposeConflictingBlock: true,
+			expectVotes:             false,
+		},
+		{
+			name:          "conflicting block with a forged QC from a later view",
+			proposalRound: 2,
+			justifyQC: func(configs []*config.Config, _, conflictingBlock *types.Block) *typesCons.QuorumCertificate {
+				return SignQuorumCertificate(t, configs[:2], testHeight, consensus.Prepare, lockedRound+1, conflictingBlock)
+			},
+			proposeConflictingBlock: true,
+			expectVotes:             false,
+		},
+		{
+			name:          "conflicting block with a QC from a later view",
+			proposalRound: 2,
+			justifyQC: func(configs []*config.Config, _, conflictingBlock *types.Block) *typesCons.QuorumCertificate {
+				return SignQuorumCertificate(t, configs, testHeight, consensus.Prepare, lockedRound+1, conflictingBlock)
+			},
+			proposeConflictingBlock: true,
+			expectVotes:             true,
+		},
+		{
+			name:                    "locked block without a justify QC",
+			proposalRound:           1,
+			justifyQC:               func([]*config.Config, *types.Block, *types.Block) *typesCons.QuorumCertificate { return nil },
+			proposeConflictingBlock: false,
+			expectVotes:             true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := GenerateNodeConfigs(t, numNodes)
+
+			// Create & start test pocket nodes
+			testChannel := make(modules.EventsChannel, 100)
+			pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+			StartAllTestPocketNodes(t, pocketNodes)
+
+			// The replicas locked on a block proposed in an earlier round that was never committed
+			lockedBlock := placeholderBlock(t, testHeight, pocketNodes[1])
+			conflictingBlock := placeholderBlock(t, testHeight, pocketNodes[leaderId])
+			lockedQC := SignQuorumCertificate(t, configs, testHeight, consensus.PreCommit, lockedRound, lockedBlock)
+
+			// All the nodes are waiting for the leader's PREPARE proposal
+			for _, pocketNode := range pocketNodes {
+				consensusModImpl := GetConsensusModImplementation(pocketNode)
+				consensusModImpl.FieldByName("Height").SetUint(testHeight)
+				consensusModImpl.FieldByName("Round").SetUint(tc.proposalRound)
+				consensusModImpl.FieldByName("Step").SetInt(int64(consensus.Prepare))
+				consensusModImpl.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+				consensusModImpl.FieldByName("LockedQC").Set(reflect.ValueOf(lockedQC))
+			}
+
+			proposedBlock := lockedBlock
+			if tc.proposeConflictingBlock {
+				proposedBlock = conflictingBlock
+			}
+			prepareProposal := &typesCons.HotstuffMessage{
+				Type:   consensus.Propose,
+				Height: testHeight,
+				Step:   consensus.Prepare,
+				Round:  tc.proposalRound,
+				Block:  proposedBlock,
+			}
+			if justifyQC := tc.justifyQC(configs, lockedBlock, conflictingBlock); justifyQC != nil {
+				prepareProposal.Justification = &typesCons.HotstuffMessage_QuorumCertificate{
+					QuorumCertificate: justifyQC,
+				}
+			}
+			anyMsg, err := anypb.New(prepareProposal)
+			require.NoError(t, err)
+			P2PBroadcast(t, pocketNodes, anyMsg)
+
+			if tc.expectVotes {
+				_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes-1, 1000)
+				require.NoError(t, err)
+				return
+			}
+
+			// Every replica rejects the proposal and times out instead of voting
+			_, err = WaitForNetworkTimeoutMessages(t, testChannel, tc.proposalRound, numNodes-1, 1000)
+			require.NoError(t, err)
+			_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, 0, 500)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHotstuffLeaderProposesBlockOfHighQC(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	testHeight := uint64(1)
+	testRound := uint64(2)
+	leaderId := typesCons.NodeId(2)
+	leader := pocketNodes[leaderId]
+
+	leaderConsensusMod := GetConsensusModImplementation(leader)
+	leaderConsensusMod.FieldByName("Height").SetUint(testHeight)
+	leaderConsensusMod.FieldByName("Round").SetUint(testRound)
+	leaderConsensusMod.FieldByName("Step").SetInt(int64(consensus.NewRound))
+	leaderConsensusMod.FieldByName("LeaderId").Set(reflect.ValueOf(&leaderId))
+
+	// Part of the network formed a PREPARE QC for a block in the previous round, while a byzantine validator
+	// sends a forged QC from the same round for a different block
+	highQCBlock := placeholderBlock(t, testHeight, pocketNodes[1])
+	forgedQCBlock := placeholderBlock(t, testHeight, pocketNodes[3])
+	justifications := []*typesCons.QuorumCertificate{
+		SignQuorumCertificate(t, configs, testHeight, consensus.Prepare, testRound-2, highQCBlock),
+		SignQuorumCertificate(t, configs[:1], testHeight, consensus.Prepare, testRound-1, forgedQCBlock),
+		nil,
+	}
+	for _, justifyQC := range justifications {
+		newRoundMessage := &typesCons.HotstuffMessage{
+			Type:   consensus.Propose,
+			Height: testHeight,
+			Step:   consensus.NewRound,
+			Round:  testRound,
+		}
+		if justifyQC != nil {
+			newRoundMessage.Justification = &typesCons.HotstuffMessage_QuorumCertificate{
+				QuorumCertificate: justifyQC,
+			}
+		}
+		anyMsg, err := anypb.New(newRoundMessage)
+		require.NoError(t, err)
+		P2PSend(t, leader, anyMsg)
+	}
+
+	// The leader proposes the block of the highest valid QC again, justified by that QC
+	proposals, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+
+	var prepareProposal typesCons.HotstuffMessage
+	require.NoError(t, anypb.UnmarshalTo(proposals[0], &prepareProposal, proto.UnmarshalOptions{}))
+	require.Equal(t, highQCBlock.BlockHeader.Hash, prepareProposal.Block.BlockHeader.Hash)
+	require.NotNil(t, prepareProposal.GetQuorumCertificate())
+	require.Equal(t, highQCBlock.BlockHeader.Hash, prepareProposal.GetQuorumCertificate().BlockHash)
+	require.Equal(t, testRound-2, prepareProposal.GetQuorumCertificate().Round)
+}
+
 /*
 func TestHotstuff4Nodes1Byzantine1Block(t *testing.T) {
 	t.Skip() // TODO: Implement
@@ -182,11 +1404,186 @@ func TestHotstuff4NodesFailOnDecide(t *testing.T) {
 	t.Skip() // TODO: Implement
 }
 
-func TestHotstuffValidatorWithLockedQC(t *testing.T) {
-	t.Skip() // TODO: Implement
-}
-
 func TestHotstuffValidatorWithLockedQCMissingNewRoundMsg(t *testing.T) {
 	t.Skip() // TODO: Implement
 }
 */
+
+func TestChainedHotstuff4NodesPipelinesBlocks(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.Consensus.HotstuffMode = config.ChainedHotstuff
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	// NewRound messages are only exchanged at the first height
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Every height only takes a single round trip: the votes for a block are sent to the leader of the next
+	// height, which proposes the next block justified by their QC.
+	var lastBlockHash string
+	for height := uint64(1); height <= 5; height++ {
+		leaderId := typesCons.NodeId(height%uint64(numNodes) + 1)
+		nextLeaderId := typesCons.NodeId((height+1)%uint64(numNodes) + 1)
+
+		prepareProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+		require.NoError(t, err)
+		var proposal typesCons.HotstuffMessage
+		require.NoError(t, prepareProposal[0].UnmarshalTo(&proposal))
+
+		blockHeader := proposal.Block.BlockHeader
+		require.Equal(t, int64(height), blockHeader.Height)
+		require.Equal(t, pocketNodes[leaderId].Address, cryptoPocket.Address(blockHeader.ProposerAddress))
+		require.Equal(t, lastBlockHash, blockHeader.LastBlockHash)
+
+		// The block is justified by the QC of its parent, which is embedded in its header without the parent itself
+		if height == 1 {
+			require.Nil(t, proposal.GetQuorumCertificate())
+			require.Empty(t, blockHeader.QuorumCertificate)
+		} else {
+			justifyQC := proposal.GetQuorumCertificate()
+			require.NotNil(t, justifyQC)
+			require.Equal(t, height-1, justifyQC.Height)
+			require.Equal(t, consensus.Prepare, justifyQC.Step)
+			require.Equal(t, lastBlockHash, justifyQC.BlockHash)
+
+			var headerQC typesCons.QuorumCertificate
+			require.NoError(t, proto.Unmarshal(blockHeader.QuorumCertificate, &headerQC))
+			require.Nil(t, headerQC.Block)
+			require.Equal(t, justifyQC.BlockHash, headerQC.BlockHash)
+		}
+
+		// Blocks are applied once committed, so the header carries the app hash of the last committed block, which
+		// is the first block once the leader of height 4 forms the QC of block 3.
+		if height < 4 {
+			require.Empty(t, blockHeader.AppHash)
+		} else {
+			require.Equal(t, hex.EncodeToString(appHash), blockHeader.AppHash)
+		}
+		lastBlockHash = blockHeader.Hash
+
+		P2PBroadcast(t, pocketNodes, prepareProposal[0])
+		votes, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes, 1000)
+		require.NoError(t, err)
+		if height == 5 {
+			break
+		}
+		for _, vote := range votes {
+			P2PSend(t, pocketNodes[nextLeaderId], vote)
+		}
+	}
+
+	// Every node voted for block 5 and committed its great-grandparent (i.e. block 2) through the three-chain rule
+	for _, pocketNode := range pocketNodes {
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, uint64(5), nodeState.Height)
+		require.Equal(t, uint8(0), nodeState.Round)
+
+		consensusModImpl := GetConsensusModImplementation(pocketNode)
+		committedBlocks := consensusModImpl.FieldByName("CommittedBlocks")
+		require.Equal(t, 2, committedBlocks.Len())
+		for _, height := range []uint64{1, 2} {
+			committedBlock := committedBlocks.MapIndex(reflect.ValueOf(height)).Interface().(*typesCons.CommittedBlock)
+			require.Equal(t, int64(height), committedBlock.Block.BlockHeader.Height)
+			require.Equal(t, committedBlock.Block.BlockHeader.Hash, committedBlock.CommitQc.BlockHash)
+		}
+
+		// The QC of block 4 justifies block 5 and the QC of block 3 is the one every node is locked on
+		highPrepareQC := consensusModImpl.FieldByName("HighPrepareQC").Interface().(*typesCons.QuorumCertificate)
+		require.Equal(t, uint64(4), highPrepareQC.Height)
+		lockedQC := consensusModImpl.FieldByName("LockedQC").Interface().(*typesCons.QuorumCertificate)
+		require.Equal(t, uint64(3), lockedQC.Height)
+	}
+}
+
+func TestChainedHotstuffRecoversFromFailedLeader(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.Consensus.HotstuffMode = config.ChainedHotstuff
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering first view change
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// The proposal of the leader of round 0 never reaches the replicas
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, 1, 1000)
+	require.NoError(t, err)
+
+	// Every node times out and the TimeoutQC moves the network to round 1
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+	timeoutMessages, err := WaitForNetworkTimeoutMessages(t, testChannel, 0, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+	newRoundMessages, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 1000)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// The leader of round 1 proposes a new block at height 1, which every node votes for
+	prepareProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	var proposal typesCons.HotstuffMessage
+	require.NoError(t, prepareProposal[0].UnmarshalTo(&proposal))
+	require.Equal(t, uint64(1), proposal.Height)
+	require.Equal(t, uint64(1), proposal.Round)
+	require.Equal(t, pocketNodes[3].Address, cryptoPocket.Address(proposal.Block.BlockHeader.ProposerAddress))
+
+	P2PBroadcast(t, pocketNodes, prepareProposal[0])
+	votes, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes, 1000)
+	require.NoError(t, err)
+
+	// The leader of height 2 extends the block certified in round 1
+	for _, vote := range votes {
+		P2PSend(t, pocketNodes[3], vote)
+	}
+	prepareProposal, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+	var nextProposal typesCons.HotstuffMessage
+	require.NoError(t, prepareProposal[0].UnmarshalTo(&nextProposal))
+	require.Equal(t, uint64(2), nextProposal.Height)
+	require.Equal(t, uint64(0), nextProposal.Round)
+
+	justifyQC := nextProposal.GetQuorumCertificate()
+	require.NotNil(t, justifyQC)
+	require.Equal(t, uint64(1), justifyQC.Height)
+	require.Equal(t, uint64(1), justifyQC.Round)
+	require.Equal(t, proposal.Block.BlockHeader.Hash, justifyQC.BlockHash)
+	require.Equal(t, proposal.Block.BlockHeader.Hash, nextProposal.Block.BlockHeader.LastBlockHash)
+}
@@ -0,0 +1,53 @@
+package consensus_tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/consensus/lightclient"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	"github.com/pokt-network/pocket/shared/config"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLightClientVerifiesCommittedHeaders(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	numBlocks := uint64(3)
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 7, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	committed := RunSimulatedNetworkUntilHeight(t, network, pocketNodes, numBlocks+1, 1000)
+	require.True(t, committed)
+
+	genesisState, err := genesis.GenesisStateFromGenesisSource(&genesis.GenesisSource{
+		Source: &genesis.GenesisSource_Config{
+			Config: genesisConfig(),
+		},
+	})
+	require.NoError(t, err)
+	client, err := lightclient.NewLightClient(genesisState.Validators, config.PartialSignatureListScheme, lightclient.NewMemHeaderStore())
+	require.NoError(t, err)
+
+	// The headers committed by the validators are verified from the genesis validator set alone
+	headers := make([]*types.BlockHeader, 0, numBlocks)
+	for height := uint64(1); height <= numBlocks; height++ {
+		headers = append(headers, getCommittedBlock(t, pocketNodes[1], height).Block.BlockHeader)
+	}
+	require.NoError(t, client.VerifyHeaderChain(headers, nil))
+	lastCommittedBlock := getCommittedBlock(t, pocketNodes[2], numBlocks)
+	require.NoError(t, client.VerifyHeader(lastCommittedBlock.Block.BlockHeader, lastCommittedBlock.CommitQc, nil))
+
+	latestHeader, err := client.LatestHeader()
+	require.NoError(t, err)
+	require.Equal(t, int64(numBlocks), latestHeader.Height)
+	require.NoError(t, client.VerifyAppHash(latestHeader.Height, latestHeader.AppHash))
+}
@@ -1,18 +1,16 @@
 package consensus_tests
 
 import (
-	"encoding/hex"
 	"log"
 	"reflect"
 	"testing"
 	"time"
 
-	"github.com/pokt-network/pocket/shared/types"
-
 	"github.com/pokt-network/pocket/consensus"
 	typesCons "github.com/pokt-network/pocket/consensus/types"
 	"github.com/pokt-network/pocket/shared/modules"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -46,7 +44,7 @@ func TestTinyPacemakerTimeouts(t *testing.T) {
 	}
 
 	// paceMakerTimeout
-	_, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	_, err := WaitForNetworkNewRoundMessages(t, testChannel, 0, numNodes, 500)
 	require.NoError(t, err)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
@@ -58,8 +56,15 @@ func TestTinyPacemakerTimeouts(t *testing.T) {
 	// Cause the pacemaker to timeout
 	time.Sleep(paceMakerTimeout)
 
+	// The round only changes once the timeouts of the validators are aggregated into a TimeoutQC
+	timeoutMessages, err := WaitForNetworkTimeoutMessages(t, testChannel, 0, numNodes, 500)
+	require.NoError(t, err)
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
 	// Check that a new round starts at the same height.
-	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	_, err = WaitForNetworkNewRoundMessages(t, testChannel, 1, numNodes, 500)
 	require.NoError(t, err)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
@@ -71,8 +76,15 @@ func TestTinyPacemakerTimeouts(t *testing.T) {
 	// Cause the pacemaker to timeout
 	time.Sleep(paceMakerTimeout)
 
-	// // Check that a new round starts at the same height
-	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	// The round only changes once the timeouts of the validators are aggregated into a TimeoutQC
+	timeoutMessages, err = WaitForNetworkTimeoutMessages(t, testChannel, 1, numNodes, 500)
+	require.NoError(t, err)
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	// Check that a new round starts at the same height
+	_, err = WaitForNetworkNewRoundMessages(t, testChannel, 2, numNodes, 500)
 	require.NoError(t, err)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
@@ -84,8 +96,15 @@ func TestTinyPacemakerTimeouts(t *testing.T) {
 	// Cause the pacemaker to timeout
 	time.Sleep(paceMakerTimeout)
 
+	// The round only changes once the timeouts of the validators are aggregated into a TimeoutQC
+	timeoutMessages, err = WaitForNetworkTimeoutMessages(t, testChannel, 2, numNodes, 500)
+	require.NoError(t, err)
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
 	// Check that a new round starts at the same height.
-	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	newRoundMessages, err := WaitForNetworkNewRoundMessages(t, testChannel, 3, numNodes, 500)
 	require.NoError(t, err)
 	for _, pocketNode := range pocketNodes {
 		nodeState := GetConsensusNodeState(pocketNode)
@@ -102,11 +121,84 @@ func TestTinyPacemakerTimeouts(t *testing.T) {
 	// Confirm we are at the next step
 	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 500)
 	require.NoError(t, err)
+
+	// The replicas may still be handling the NEWROUND messages the leader already handled
+	WaitForConsensusNodesStep(t, pocketNodes, 1, consensus.Prepare, 500)
 	for _, pocketNode := range pocketNodes {
-		nodeState := GetConsensusNodeState(pocketNode)
-		require.Equal(t, uint64(1), nodeState.Height)
-		require.Equal(t, uint8(consensus.Prepare), nodeState.Step)
-		require.Equal(t, uint8(3), nodeState.Round)
+		require.Equal(t, uint64(3), pocketNode.GetBus().GetConsensusModule().StateSnapshot().Round)
+	}
+}
+
+func TestPacemakerNewRoundWithTimeoutQCAdvancesRound(t *testing.T) {
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	_, err := WaitForNetworkNewRoundMessages(t, testChannel, 0, numNodes, 500)
+	require.NoError(t, err)
+
+	testHeight := uint64(1)
+	testRound := uint64(0)
+	replicaId := typesCons.NodeId(1)
+	replica := pocketNodes[replicaId]
+
+	// A NewRound message from a later round that is not backed by a TimeoutQC of the round before it is ignored
+	forgedMsg := &typesCons.HotstuffMessage{
+		Type:          consensus.Propose,
+		Height:        testHeight,
+		Step:          consensus.NewRound,
+		Round:         testRound + 1,
+		Block:         nil,
+		Justification: nil,
+		TimeoutQc:     SignTimeoutQC(t, configs[:1], testHeight, testRound),
+	}
+	anyMsg, err := anypb.New(forgedMsg)
+	require.NoError(t, err)
+	P2PSend(t, replica, anyMsg)
+
+	_, err = WaitForNetworkNewRoundMessages(t, testChannel, testRound+1, 0, 500)
+	require.NoError(t, err)
+	snapshot := replica.GetBus().GetConsensusModule().StateSnapshot()
+	require.Equal(t, uint32(consensus.NewRound), snapshot.Step)
+	require.Equal(t, testRound, snapshot.Round)
+
+	// The replica joins the later round, like the validators that formed the TimeoutQC did, by sending its own
+	// NewRound message for it before handling the one it received
+	newRoundMsg := &typesCons.HotstuffMessage{
+		Type:          consensus.Propose,
+		Height:        testHeight,
+		Step:          consensus.NewRound,
+		Round:         testRound + 1,
+		Block:         nil,
+		Justification: nil,
+		TimeoutQc:     SignTimeoutQC(t, configs, testHeight, testRound),
+	}
+	anyMsg, err = anypb.New(newRoundMsg)
+	require.NoError(t, err)
+	P2PSend(t, replica, anyMsg)
+
+	_, err = WaitForNetworkNewRoundMessages(t, testChannel, testRound+1, 1, 500)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		snapshot = replica.GetBus().GetConsensusModule().StateSnapshot()
+		return snapshot.Height == testHeight && snapshot.Round == testRound+1
+	}, 500*time.Millisecond, 5*time.Millisecond)
+
+	// The other nodes did not receive the message, so they stay in the previous round
+	for nodeId, pocketNode := range pocketNodes {
+		if nodeId == replicaId {
+			continue
+		}
+		require.Equal(t, testRound, pocketNode.GetBus().GetConsensusModule().StateSnapshot().Round)
 	}
 }
 
@@ -129,18 +221,7 @@ func TestPacemakerCatchupSameStepDifferentRounds(t *testing.T) {
 	leaderRound := uint64(6)
 
 	// Placeholder block
-	blockHeader := &types.BlockHeader{
-		Height:            int64(testHeight),
-		Hash:              hex.EncodeToString(appHash),
-		NumTxs:            0,
-		LastBlockHash:     "",
-		ProposerAddress:   []byte(leader.Address),
-		QuorumCertificate: nil,
-	}
-	block := &types.Block{
-		BlockHeader:  blockHeader,
-		Transactions: emptyTxs,
-	}
+	block := placeholderBlock(t, testHeight, leader)
 
 	leaderConsensusMod := GetConsensusModImplementation(leader)
 	leaderConsensusMod.FieldByName("Block").Set(reflect.ValueOf(block))
@@ -166,6 +247,7 @@ func TestPacemakerCatchupSameStepDifferentRounds(t *testing.T) {
 		Round:         leaderRound,
 		Block:         block,
 		Justification: nil,
+		TimeoutQc:     SignTimeoutQC(t, configs, testHeight, leaderRound-1), // Justifies the replicas catching up to the leader's round
 	}
 	anyMsg, err := anypb.New(prepareProposal)
 	require.NoError(t, err)
@@ -191,11 +273,306 @@ func TestPacemakerCatchupSameStepDifferentRounds(t *testing.T) {
 	}
 }
 
-/*
 func TestPacemakerDifferentHeightsCatchup(t *testing.T) {
-	t.Skip() // TODO: Implement
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Starting point
+	committedHeight := uint64(1)
+	networkHeight := uint64(2)
+	testRound := uint64(0)
+
+	// The block the network committed at the previous height alongside its COMMIT QC
+	proposer := pocketNodes[typesCons.NodeId(1)]
+	block := placeholderBlock(t, committedHeight, proposer)
+	partialSigs := make([]*typesCons.PartialSignature, 0, numNodes)
+	for _, config := range configs {
+		partialSigs = append(partialSigs, SignVote(t, config.PrivateKey, committedHeight, consensus.Commit, testRound, block))
+	}
+	committedBlock := &typesCons.CommittedBlock{
+		Block: block,
+		CommitQc: &typesCons.QuorumCertificate{
+			Height:    committedHeight,
+			Step:      consensus.Commit,
+			Round:     testRound,
+			Block:     block,
+			BlockHash: block.BlockHeader.Hash,
+			ThresholdSignature: &typesCons.ThresholdSignature{
+				Signatures: partialSigs,
+			},
+		},
+	}
+
+	// Every node but the last one already committed the block and moved on to the next height
+	laggingNodeId := typesCons.NodeId(numNodes)
+	for nodeId, pocketNode := range pocketNodes {
+		consensusModImpl := GetConsensusModImplementation(pocketNode)
+		consensusModImpl.FieldByName("Round").SetUint(testRound)
+		consensusModImpl.FieldByName("Step").SetInt(int64(consensus.NewRound))
+		if nodeId == laggingNodeId {
+			consensusModImpl.FieldByName("Height").SetUint(committedHeight)
+			continue
+		}
+		consensusModImpl.FieldByName("Height").SetUint(networkHeight)
+		consensusModImpl.FieldByName("CommittedBlocks").SetMapIndex(reflect.ValueOf(committedHeight), reflect.ValueOf(committedBlock))
+	}
+
+	// A message from a later height that is not backed by a certificate of that height does not start block sync
+	forgedMsg := &typesCons.HotstuffMessage{
+		Type:          consensus.Propose,
+		Height:        networkHeight + 10,
+		Step:          consensus.NewRound,
+		Round:         testRound,
+		Block:         nil,
+		Justification: nil,
+		TimeoutQc:     SignTimeoutQC(t, configs[:1], networkHeight+10, testRound),
+	}
+	anyMsg, err := anypb.New(forgedMsg)
+	require.NoError(t, err)
+	P2PSend(t, pocketNodes[laggingNodeId], anyMsg)
+
+	_, err = WaitForNetworkBlockSyncMessages(t, testChannel, consensus.BlockSyncRequestMessage, 0, 500)
+	require.NoError(t, err)
+
+	// The lagging node notices it is behind once it receives a message from the next height, which carries the
+	// TimeoutQC of the first round of that height
+	newRoundMsg := &typesCons.HotstuffMessage{
+		Type:          consensus.Propose,
+		Height:        networkHeight,
+		Step:          consensus.NewRound,
+		Round:         testRound + 1,
+		Block:         nil,
+		Justification: nil,
+		TimeoutQc:     SignTimeoutQC(t, configs, networkHeight, testRound),
+	}
+	anyMsg, err = anypb.New(newRoundMsg)
+	require.NoError(t, err)
+	P2PSend(t, pocketNodes[laggingNodeId], anyMsg)
+
+	requests, err := WaitForNetworkBlockSyncMessages(t, testChannel, consensus.BlockSyncRequestMessage, 1, 1000)
+	require.NoError(t, err)
+	P2PBroadcast(t, pocketNodes, requests[0])
+
+	// Every node that committed the block responds, but only the first response is applied
+	responses, err := WaitForNetworkBlockSyncMessages(t, testChannel, consensus.BlockSyncResponseMessage, numNodes-1, 1000)
+	require.NoError(t, err)
+	for _, response := range responses {
+		P2PSend(t, pocketNodes[laggingNodeId], response)
+	}
+
+	// The lagging node rejoins consensus at the network height
+	_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, 1, 1000)
+	require.NoError(t, err)
+
+	nodeState := GetConsensusNodeState(pocketNodes[laggingNodeId])
+	require.Equal(t, networkHeight, nodeState.Height)
+	require.Equal(t, uint8(consensus.NewRound), nodeState.Step)
+	require.Equal(t, uint8(testRound), nodeState.Round)
 }
 
+func TestPacemakerExponentialTimeoutsSlowLeader(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	paceMakerTimeoutMsec := uint64(50)
+	paceMakerTimeoutMultiplier := float64(3)
+	leaderDelay := 100 * time.Millisecond // Longer than the timeout of the first round but shorter than the timeout of the second
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, config := range configs {
+		config.Consensus.Pacemaker.TimeoutMsec = paceMakerTimeoutMsec
+		config.Consensus.Pacemaker.TimeoutMultiplier = paceMakerTimeoutMultiplier
+		config.Consensus.Pacemaker.TimeoutMaxMsec = 1000
+		config.Consensus.Pacemaker.TimeoutJitter = 0
+	}
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+
+	// The leader of every round is too slow for the first step timeout, but the timeout grows until its proposal makes it in time.
+	for round := uint8(0); round < 2; round++ {
+		newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+		require.NoError(t, err)
+		for _, pocketNode := range pocketNodes {
+			nodeState := GetConsensusNodeState(pocketNode)
+			require.Equal(t, uint64(1), nodeState.Height)
+			require.Equal(t, uint8(consensus.NewRound), nodeState.Step)
+			require.Equal(t, round, nodeState.Round)
+		}
+		for _, message := range newRoundMessages {
+			P2PBroadcast(t, pocketNodes, message)
+		}
+
+		prepareProposal, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Propose, 1, 500)
+		require.NoError(t, err)
+		_, err = WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, 1, 500) // The leader's own vote
+		require.NoError(t, err)
+
+		// Artificially slow leader
+		time.Sleep(leaderDelay)
+
+		// Every node timed out waiting for the first proposal, so the proposal is discarded once the round changes
+		if round == 0 {
+			timeoutMessages, err := WaitForNetworkTimeoutMessages(t, testChannel, uint64(round), numNodes, 500)
+			require.NoError(t, err)
+			for _, message := range timeoutMessages {
+				P2PBroadcast(t, pocketNodes, message)
+			}
+		}
+		P2PBroadcast(t, pocketNodes, prepareProposal[0])
+	}
+
+	// The replicas did not time out before receiving the proposal of the second round, so they vote for it
+	_, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.Prepare, consensus.Vote, numNodes-1, 500)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	for _, pocketNode := range pocketNodes {
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, uint64(1), nodeState.Height)
+		require.Equal(t, uint8(1), nodeState.Round)
+		if nodeState.IsLeader {
+			require.Equal(t, uint8(consensus.Prepare), nodeState.Step)
+		} else {
+			require.Equal(t, uint8(consensus.PreCommit), nodeState.Step)
+		}
+	}
+}
+
+func TestPacemakerTimeoutQCRequiredToAdvanceRound(t *testing.T) {
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+	_, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	require.NoError(t, err)
+
+	// A single node timing out does not change the round of any node, including its own
+	TriggerNextView(t, pocketNodes[1])
+	timeoutMessages, err := WaitForNetworkTimeoutMessages(t, testChannel, 0, 1, 500)
+	require.NoError(t, err)
+	P2PBroadcast(t, pocketNodes, timeoutMessages[0])
+
+	time.Sleep(50 * time.Millisecond)
+	for _, pocketNode := range pocketNodes {
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, uint64(1), nodeState.Height)
+		require.Equal(t, uint8(0), nodeState.Round)
+	}
+
+	// Once enough validators time out, every node (including the one that did not) aggregates a TimeoutQC
+	TriggerNextView(t, pocketNodes[2])
+	TriggerNextView(t, pocketNodes[3])
+	timeoutMessages, err = WaitForNetworkTimeoutMessages(t, testChannel, 0, 2, 500)
+	require.NoError(t, err)
+	for _, message := range timeoutMessages {
+		P2PBroadcast(t, pocketNodes, message)
+	}
+
+	newRoundMessages, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	require.NoError(t, err)
+	for _, message := range newRoundMessages {
+		var newRoundMessage typesCons.HotstuffMessage
+		require.NoError(t, anypb.UnmarshalTo(message, &newRoundMessage, proto.UnmarshalOptions{}))
+		require.Equal(t, uint64(1), newRoundMessage.Round)
+
+		// The new round is justified by the timeouts of the round before it
+		timeoutQC := newRoundMessage.GetTimeoutQc()
+		require.NotNil(t, timeoutQC)
+		require.Equal(t, uint64(1), timeoutQC.Height)
+		require.Equal(t, uint64(0), timeoutQC.Round)
+		require.Len(t, timeoutQC.ThresholdSignature.Signatures, 3)
+	}
+	for _, pocketNode := range pocketNodes {
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, uint64(1), nodeState.Height)
+		require.Equal(t, uint8(consensus.NewRound), nodeState.Step)
+		require.Equal(t, uint8(1), nodeState.Round)
+	}
+}
+
+func TestPacemakerLaggingReplicaCatchesUpWithTimeoutQC(t *testing.T) {
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		TriggerNextView(t, pocketNode)
+	}
+	_, err := WaitForNetworkConsensusMessages(t, testChannel, consensus.NewRound, consensus.Propose, numNodes, 500)
+	require.NoError(t, err)
+
+	// The rest of the network moved on to a later round which the lagging node did not take part in
+	testHeight := uint64(1)
+	networkRound := uint64(2)
+	laggingNode := pocketNodes[typesCons.NodeId(numNodes)]
+
+	testCases := []struct {
+		name      string
+		timeoutQC *typesCons.TimeoutQuorumCertificate
+	}{
+		{"no TimeoutQC", nil},
+		{"TimeoutQC for the wrong round", SignTimeoutQC(t, configs, testHeight, networkRound-2)},
+		{"TimeoutQC without enough signatures", SignTimeoutQC(t, configs[:2], testHeight, networkRound-1)},
+	}
+	for _, tc := range testCases {
+		newRoundMsg := &typesCons.HotstuffMessage{
+			Type:      consensus.Propose,
+			Height:    testHeight,
+			Step:      consensus.NewRound,
+			Round:     networkRound,
+			TimeoutQc: tc.timeoutQC,
+		}
+		anyMsg, err := anypb.New(newRoundMsg)
+		require.NoError(t, err)
+		P2PSend(t, laggingNode, anyMsg)
+
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, uint64(0), laggingNode.GetBus().GetConsensusModule().StateSnapshot().Round, tc.name)
+	}
+
+	// A valid TimeoutQC proves why the rest of the network moved on
+	newRoundMsg := &typesCons.HotstuffMessage{
+		Type:      consensus.Propose,
+		Height:    testHeight,
+		Step:      consensus.NewRound,
+		Round:     networkRound,
+		TimeoutQc: SignTimeoutQC(t, configs, testHeight, networkRound-1),
+	}
+	anyMsg, err := anypb.New(newRoundMsg)
+	require.NoError(t, err)
+	P2PSend(t, laggingNode, anyMsg)
+
+	time.Sleep(50 * time.Millisecond)
+	snapshot := laggingNode.GetBus().GetConsensusModule().StateSnapshot()
+	require.Equal(t, testHeight, snapshot.Height)
+	require.Equal(t, networkRound, snapshot.Round)
+}
+
+/*
 func TestPacemakerDifferentStepsCatchup(t *testing.T) {
 	t.Skip() // TODO: Implement
 }
@@ -219,8 +596,4 @@ func TestPacemakerNoQuorum(t *testing.T) {
 func TestPacemakerNotSafeProposal(t *testing.T) {
 	t.Skip() // TODO: Implement
 }
-
-func TestPacemakerExponentialTimeouts(t *testing.T) {
-	t.Skip() // TODO: Implement
-}
 */
@@ -0,0 +1,173 @@
+package consensus_tests
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	"github.com/pokt-network/pocket/shared"
+	"github.com/pokt-network/pocket/shared/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestValidatorRefusesConflictingSignatures(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 7, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// A validator was migrated from a machine where it already signed a COMMIT vote for another block at height 1
+	guardedCfg := configs[0]
+	guardedCfg.RootDir = t.TempDir()
+	guardedCfg.Consensus.TraceFile = filepath.Join(t.TempDir(), "trace")
+	address := guardedCfg.PrivateKey.Address().String()
+	require.NoError(t, consensus.ImportLastSignedState(guardedCfg.RootDir, address, marshalLastSignedState(t, &typesCons.LastSignedState{
+		Address:   address,
+		Height:    1,
+		Round:     0,
+		Step:      consensus.Commit,
+		BlockHash: "conflicting block hash",
+	})))
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	StartSimulatedPocketNodes(t, pocketNodes)
+	var guardedNode *shared.Node
+	for _, pocketNode := range pocketNodes {
+		if pocketNode.Address.String() == address {
+			guardedNode = pocketNode
+		}
+	}
+
+	committed := RunSimulatedNetworkUntilHeight(t, network, pocketNodes, 3, 1000)
+	require.True(t, committed)
+
+	// Another node cannot run, nor can a state be imported, with the root directory of the validator while it is running
+	_, err = consensus.Create(guardedCfg)
+	require.ErrorIs(t, err, syscall.EWOULDBLOCK)
+	require.ErrorIs(t, consensus.ImportLastSignedState(guardedCfg.RootDir, address, marshalLastSignedState(t, &typesCons.LastSignedState{})), syscall.EWOULDBLOCK)
+	require.NoError(t, guardedNode.GetBus().GetConsensusModule().Stop())
+
+	// The other validators committed the first block without the validator, which only voted once it reached the next
+	// height
+	trace, err := consensus.ReadTrace(guardedCfg.Consensus.TraceFile)
+	require.NoError(t, err)
+	votesPerHeight := make(map[uint64]int)
+	for _, entry := range trace {
+		if entry.Type != typesCons.TraceEntryType_TRACE_ENTRY_OUTBOUND || entry.Message.MessageName() != consensus.HotstuffMessage {
+			continue
+		}
+		var msg typesCons.HotstuffMessage
+		require.NoError(t, entry.Message.UnmarshalTo(&msg))
+		if msg.Type == consensus.Vote {
+			votesPerHeight[msg.Height]++
+		}
+	}
+	require.Zero(t, votesPerHeight[1])
+	require.Equal(t, 3, votesPerHeight[2]) // PREPARE, PRECOMMIT & COMMIT
+
+	// The last signed state was persisted as the validator voted
+	lastSigned := unmarshalLastSignedState(t, guardedCfg.RootDir)
+	require.Equal(t, address, lastSigned.Address)
+	require.GreaterOrEqual(t, lastSigned.Height, uint64(2))
+
+	// Importing an older state keeps the latest one
+	olderState := marshalLastSignedState(t, &typesCons.LastSignedState{Address: address, Height: 1, Step: consensus.Prepare, BlockHash: "block hash"})
+	require.NoError(t, consensus.ImportLastSignedState(guardedCfg.RootDir, address, olderState))
+	require.Equal(t, lastSigned.String(), unmarshalLastSignedState(t, guardedCfg.RootDir).String())
+
+	// Importing a state that conflicts with the persisted one, or that belongs to another validator, fails
+	conflictingState := &typesCons.LastSignedState{}
+	require.NoError(t, protojson.Unmarshal(marshalLastSignedState(t, lastSigned), conflictingState))
+	conflictingState.BlockHash = "conflicting block hash"
+	require.Error(t, consensus.ImportLastSignedState(guardedCfg.RootDir, address, marshalLastSignedState(t, conflictingState)))
+	require.Error(t, consensus.ImportLastSignedState(guardedCfg.RootDir, configs[1].PrivateKey.Address().String(), marshalLastSignedState(t, lastSigned)))
+}
+
+func TestChainedValidatorRefusesConflictingSignatures(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.Consensus.HotstuffMode = config.ChainedHotstuff
+	}
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 7, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// A chained validator restarted after voting for another block at height 1
+	guardedCfg := configs[0]
+	guardedCfg.RootDir = t.TempDir()
+	guardedCfg.Consensus.TraceFile = filepath.Join(t.TempDir(), "trace")
+	address := guardedCfg.PrivateKey.Address().String()
+	require.NoError(t, consensus.ImportLastSignedState(guardedCfg.RootDir, address, marshalLastSignedState(t, &typesCons.LastSignedState{
+		Address:   address,
+		Height:    1,
+		Round:     0,
+		Step:      consensus.Prepare,
+		BlockHash: "conflicting block hash",
+	})))
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	StartSimulatedPocketNodes(t, pocketNodes)
+	var guardedNode *shared.Node
+	for _, pocketNode := range pocketNodes {
+		if pocketNode.Address.String() == address {
+			guardedNode = pocketNode
+		}
+	}
+
+	committed := RunSimulatedNetworkUntilHeight(t, network, pocketNodes, 3, 1000)
+	require.True(t, committed)
+	require.NoError(t, guardedNode.GetBus().GetConsensusModule().Stop())
+
+	// The validator did not vote at height 1 again, but voted in the following views
+	trace, err := consensus.ReadTrace(guardedCfg.Consensus.TraceFile)
+	require.NoError(t, err)
+	votesPerHeight := make(map[uint64]int)
+	for _, entry := range trace {
+		if entry.Type != typesCons.TraceEntryType_TRACE_ENTRY_OUTBOUND || entry.Message.MessageName() != consensus.HotstuffMessage {
+			continue
+		}
+		var msg typesCons.HotstuffMessage
+		require.NoError(t, entry.Message.UnmarshalTo(&msg))
+		if msg.Type == consensus.Vote {
+			votesPerHeight[msg.Height]++
+		}
+	}
+	require.Zero(t, votesPerHeight[1])
+	require.NotZero(t, votesPerHeight[2])
+
+	// The chained view of the last vote was persisted as the validator voted
+	lastSigned := unmarshalLastSignedState(t, guardedCfg.RootDir)
+	require.Equal(t, address, lastSigned.Address)
+	require.Greater(t, lastSigned.Height, uint64(2))
+	require.Equal(t, consensus.Prepare, lastSigned.Step)
+
+	// Chained validators do not keep a WAL since it cannot record the blocks that are not committed yet
+	_, err = os.Stat(filepath.Join(guardedCfg.RootDir, "consensus.wal"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func marshalLastSignedState(t *testing.T, lastSigned *typesCons.LastSignedState) []byte {
+	bz, err := protojson.Marshal(lastSigned)
+	require.NoError(t, err)
+	return bz
+}
+
+func unmarshalLastSignedState(t *testing.T, rootDir string) *typesCons.LastSignedState {
+	bz, err := consensus.ExportLastSignedState(rootDir)
+	require.NoError(t, err)
+	lastSigned := &typesCons.LastSignedState{}
+	require.NoError(t, protojson.Unmarshal(bz, lastSigned))
+	return lastSigned
+}
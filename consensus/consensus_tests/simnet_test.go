@@ -0,0 +1,105 @@
+package consensus_tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	"github.com/pokt-network/pocket/shared"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSimulatedNetworkRecoversFromPartitionedLeader(t *testing.T) {
+	firstRun := runPartitionedLeaderSimulation(t, 7)
+	secondRun := runPartitionedLeaderSimulation(t, 7)
+
+	// The same seed replays the exact same run
+	require.Equal(t, firstRun, secondRun)
+}
+
+// Partitions the leader of the first round away from the rest of the network until the others commit the first
+// block without it, and then heals the network so it catches up. Returns the stats of the network at the end of
+// the simulation.
+func runPartitionedLeaderSimulation(t *testing.T, seed int64) simnet.Stats {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{
+		Seed:        seed,
+		MinLatency:  10 * time.Millisecond,
+		MaxLatency:  50 * time.Millisecond,
+		ReorderRate: 0.1,
+	})
+	require.NoError(t, err)
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	// Node 2 is the leader of the first round
+	partitionedLeader := pocketNodes[2]
+	majority := []typesCons.NodeId{1, 3, 4}
+	network.Partition([]cryptoPocket.Address{partitionedLeader.Address})
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		ScheduleNextView(t, network, pocketNode, 0)
+	}
+	network.RunUntilIdle(1000)
+	for _, pocketNode := range pocketNodes {
+		nodeState := GetConsensusNodeState(pocketNode)
+		require.Equal(t, uint64(1), nodeState.Height)
+		require.Equal(t, uint8(0), nodeState.Round)
+	}
+
+	// Simulate the pacemaker timing out on every node so the majority moves on to the next round without the leader
+	for _, pocketNode := range pocketNodes {
+		ScheduleNextView(t, network, pocketNode, time.Second)
+	}
+	committed := network.RunUntil(func() bool {
+		for _, nodeId := range majority {
+			if GetConsensusNodeState(pocketNodes[nodeId]).Height < 2 {
+				return false
+			}
+		}
+		return true
+	}, 1000)
+	require.True(t, committed)
+	require.Equal(t, uint64(1), GetConsensusNodeState(partitionedLeader).Height)
+
+	// The leader of the second round proposed the block
+	committedBlock := getCommittedBlock(t, pocketNodes[3], 1)
+	require.Equal(t, pocketNodes[3].Address.String(), cryptoPocket.Address(committedBlock.Block.BlockHeader.ProposerAddress).String())
+	for _, nodeId := range majority {
+		require.True(t, proto.Equal(committedBlock.Block, getCommittedBlock(t, pocketNodes[nodeId], 1).Block))
+	}
+
+	// Once the partition heals, the majority starts the next height. The proposal of its first round carries the
+	// COMMIT QC of the block the former leader missed, which it syncs from the rest of the network.
+	network.Heal()
+	for _, nodeId := range majority {
+		ScheduleNextView(t, network, pocketNodes[nodeId], 0)
+	}
+	synced := network.RunUntil(func() bool {
+		return GetConsensusNodeState(partitionedLeader).Height >= 2
+	}, 1000)
+	require.True(t, synced)
+	require.True(t, proto.Equal(committedBlock.Block, getCommittedBlock(t, partitionedLeader, 1).Block))
+
+	stats := network.Stats()
+	require.Greater(t, stats.Partitioned, 0)
+	require.Zero(t, stats.Dropped)
+	return stats
+}
+
+func getCommittedBlock(t *testing.T, node *shared.Node, height uint64) *typesCons.CommittedBlock {
+	committedBlocks := GetConsensusModImplementation(node).FieldByName("CommittedBlocks")
+	committedBlock := committedBlocks.MapIndex(reflect.ValueOf(height))
+	require.True(t, committedBlock.IsValid())
+	return committedBlock.Interface().(*typesCons.CommittedBlock)
+}
@@ -0,0 +1,229 @@
+package consensus_tests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+var thresholdSignatureSchemes = []config.ThresholdSignatureScheme{
+	config.PartialSignatureListScheme,
+	config.AggregateSignatureScheme,
+}
+
+func TestThresholdSignerAggregateAndVerify(t *testing.T) {
+	validators, privKeys := generateThresholdSignerValidators(t, 10)
+	bytesSigned := []byte("vote")
+
+	for _, scheme := range thresholdSignatureSchemes {
+		t.Run(string(scheme), func(t *testing.T) {
+			signer, err := consensus.CreateThresholdSigner(scheme)
+			require.NoError(t, err)
+
+			// Validators 1, 4, 5 & 8 sign, out of order
+			signerIndices := []int{5, 1, 8, 4}
+			thresholdSig, err := signer.Aggregate(validators, bytesSigned, signPartially(t, validators, privKeys, bytesSigned, signerIndices))
+			require.NoError(t, err)
+			require.NoError(t, signer.Verify(validators, bytesSigned, thresholdSig))
+
+			signerBitmap, err := signer.SignerBitmap(validators, thresholdSig)
+			require.NoError(t, err)
+			require.Equal(t, len(signerIndices), signerBitmap.Count())
+			for i := range validators {
+				require.Equal(t, i == 1 || i == 4 || i == 5 || i == 8, signerBitmap.IsSet(i))
+			}
+
+			// Signatures over any other bytes are invalid
+			require.Error(t, signer.Verify(validators, []byte("timeout"), thresholdSig))
+
+			// A threshold signature without any signer is invalid
+			emptySig, err := signer.Aggregate(validators, bytesSigned, nil)
+			require.NoError(t, err)
+			require.ErrorIs(t, signer.Verify(validators, bytesSigned, emptySig), typesCons.ErrNilThresholdSigInQC)
+
+			// The partial signature of a validator cannot be attributed to another one
+			partialSigs := signPartially(t, validators, privKeys, bytesSigned, signerIndices)
+			partialSigs[0].Signature = partialSigs[1].Signature
+			forgedSig, err := signer.Aggregate(validators, bytesSigned, partialSigs)
+			require.NoError(t, err)
+			require.Error(t, signer.Verify(validators, bytesSigned, forgedSig))
+		})
+	}
+}
+
+func TestAggregateThresholdSignatureIsCompact(t *testing.T) {
+	validators, privKeys := generateThresholdSignerValidators(t, 100)
+	bytesSigned := []byte("vote")
+
+	signerIndices := make([]int, 67)
+	for i := range signerIndices {
+		signerIndices[i] = i
+	}
+	partialSigs := signPartially(t, validators, privKeys, bytesSigned, signerIndices)
+
+	listSigner, err := consensus.CreateThresholdSigner(config.PartialSignatureListScheme)
+	require.NoError(t, err)
+	listSig, err := listSigner.Aggregate(validators, bytesSigned, partialSigs)
+	require.NoError(t, err)
+
+	aggregateSigner, err := consensus.CreateThresholdSigner(config.AggregateSignatureScheme)
+	require.NoError(t, err)
+	aggregateSig, err := aggregateSigner.Aggregate(validators, bytesSigned, partialSigs)
+	require.NoError(t, err)
+
+	// 32 bytes per signer and a 13 byte bitmap VS a 64 byte signature and a 40 character address per signer
+	require.Empty(t, aggregateSig.Signatures)
+	require.Len(t, aggregateSig.SignerBitmap, 13)
+	require.Len(t, aggregateSig.AggregateSignature, 32*(len(signerIndices)+1))
+	require.Less(t, proto.Size(aggregateSig)*3, proto.Size(listSig))
+}
+
+func TestAggregateThresholdSignatureRejectsTampering(t *testing.T) {
+	validators, privKeys := generateThresholdSignerValidators(t, 10)
+	bytesSigned := []byte("vote")
+
+	signer, err := consensus.CreateThresholdSigner(config.AggregateSignatureScheme)
+	require.NoError(t, err)
+	thresholdSig, err := signer.Aggregate(validators, bytesSigned, signPartially(t, validators, privKeys, bytesSigned, []int{0, 2, 3, 9}))
+	require.NoError(t, err)
+	require.NoError(t, signer.Verify(validators, bytesSigned, thresholdSig))
+
+	// Claiming a validator signed without its partial signature
+	tamperedSig := proto.Clone(thresholdSig).(*typesCons.ThresholdSignature)
+	tamperedSig.SignerBitmap[0] |= 1 << 1
+	require.Error(t, signer.Verify(validators, bytesSigned, tamperedSig))
+
+	// Swapping the signers, while keeping their number
+	tamperedSig = proto.Clone(thresholdSig).(*typesCons.ThresholdSignature)
+	tamperedSig.SignerBitmap[0] ^= 1<<2 | 1<<1
+	require.ErrorIs(t, signer.Verify(validators, bytesSigned, tamperedSig), typesCons.ErrInvalidAggregateSig)
+
+	// Signers that are not in the validator set
+	tamperedSig = proto.Clone(thresholdSig).(*typesCons.ThresholdSignature)
+	tamperedSig.SignerBitmap[1] |= 1 << 4
+	require.Error(t, signer.Verify(validators, bytesSigned, tamperedSig))
+	require.Error(t, signer.Verify(validators[:8], bytesSigned, thresholdSig))
+
+	// Tampering with the aggregate itself
+	for _, offset := range []int{0, len(thresholdSig.AggregateSignature) - 1} {
+		tamperedSig = proto.Clone(thresholdSig).(*typesCons.ThresholdSignature)
+		tamperedSig.AggregateSignature[offset] ^= 1
+		require.Error(t, signer.Verify(validators, bytesSigned, tamperedSig))
+	}
+}
+
+func TestHotstuffAggregateThresholdSignatures(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+	for _, cfg := range configs {
+		cfg.Consensus.ThresholdSignature = config.AggregateSignatureScheme
+	}
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 7, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	// Debug message to start consensus by triggering next view.
+	for _, pocketNode := range pocketNodes {
+		ScheduleNextView(t, network, pocketNode, 0)
+	}
+	committed := network.RunUntil(func() bool {
+		for _, pocketNode := range pocketNodes {
+			if pocketNode.GetBus().GetConsensusModule().StateSnapshot().Height < 2 {
+				return false
+			}
+		}
+		return true
+	}, 1000)
+	require.True(t, committed)
+
+	// Every node committed the block with the same compact COMMIT QC
+	commitQC := getCommittedBlock(t, pocketNodes[1], 1).CommitQc
+	require.Empty(t, commitQC.ThresholdSignature.Signatures)
+	require.GreaterOrEqual(t, consensus.SignerBitmap(commitQC.ThresholdSignature.SignerBitmap).Count(), 3)
+	for _, pocketNode := range pocketNodes {
+		require.True(t, proto.Equal(commitQC, getCommittedBlock(t, pocketNode, 1).CommitQc))
+	}
+}
+
+func BenchmarkThresholdSigner(b *testing.B) {
+	bytesSigned := []byte("vote")
+
+	for _, numValidators := range []int{4, 100, 1000} {
+		validators, privKeys := generateThresholdSignerValidators(b, numValidators)
+		signerIndices := make([]int, numValidators*2/3+1)
+		for i := range signerIndices {
+			signerIndices[i] = i
+		}
+		partialSigs := signPartially(b, validators, privKeys, bytesSigned, signerIndices)
+
+		for _, scheme := range thresholdSignatureSchemes {
+			signer, err := consensus.CreateThresholdSigner(scheme)
+			require.NoError(b, err)
+			thresholdSig, err := signer.Aggregate(validators, bytesSigned, partialSigs)
+			require.NoError(b, err)
+
+			b.Run(fmt.Sprintf("%s/aggregate/n=%d", scheme, numValidators), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					signer.Aggregate(validators, bytesSigned, partialSigs)
+				}
+			})
+			b.Run(fmt.Sprintf("%s/verify/n=%d", scheme, numValidators), func(b *testing.B) {
+				b.ReportMetric(float64(proto.Size(thresholdSig)), "qc_bytes")
+				for i := 0; i < b.N; i++ {
+					require.NoError(b, signer.Verify(validators, bytesSigned, thresholdSig))
+				}
+			})
+		}
+	}
+}
+
+// Returns `n` validators sorted by address, alongside their private keys.
+func generateThresholdSignerValidators(t require.TestingT, n int) ([]*genesis.Validator, []cryptoPocket.PrivateKey) {
+	privKeys := make([]cryptoPocket.PrivateKey, n)
+	for i := range privKeys {
+		privKey, err := cryptoPocket.GeneratePrivateKey()
+		require.NoError(t, err)
+		privKeys[i] = privKey
+	}
+	sort.Slice(privKeys, func(i, j int) bool {
+		return privKeys[i].Address().String() < privKeys[j].Address().String()
+	})
+
+	validators := make([]*genesis.Validator, n)
+	for i, privKey := range privKeys {
+		validators[i] = &genesis.Validator{
+			Address:   privKey.Address(),
+			PublicKey: privKey.PublicKey().Bytes(),
+		}
+	}
+	return validators, privKeys
+}
+
+func signPartially(t require.TestingT, validators []*genesis.Validator, privKeys []cryptoPocket.PrivateKey, bytesToSign []byte, signerIndices []int) []*typesCons.PartialSignature {
+	partialSigs := make([]*typesCons.PartialSignature, 0, len(signerIndices))
+	for _, i := range signerIndices {
+		signature, err := privKeys[i].Sign(bytesToSign)
+		require.NoError(t, err)
+		partialSigs = append(partialSigs, &typesCons.PartialSignature{
+			Signature: signature,
+			Address:   hex.EncodeToString(validators[i].Address),
+		})
+	}
+	return partialSigs
+}
@@ -0,0 +1,111 @@
+package consensus_tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	"github.com/pokt-network/pocket/shared"
+	"github.com/pokt-network/pocket/shared/config"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReplayedTraceReproducesConsensusState(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 11, MinLatency: 10 * time.Millisecond, MaxLatency: 30 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Every node records its trace, named after its address
+	traceDir := t.TempDir()
+	for _, config := range configs {
+		config.Consensus.TraceFile = filepath.Join(traceDir, config.PrivateKey.Address().String())
+	}
+
+	// Create & start test pocket nodes
+	pocketNodes := CreateSimulatedConsensusPocketNodes(t, configs, network)
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	committed := RunSimulatedNetworkUntilHeight(t, network, pocketNodes, 2, 1000)
+	require.True(t, committed)
+	for _, pocketNode := range pocketNodes {
+		require.NoError(t, pocketNode.GetBus().GetConsensusModule().Stop())
+	}
+
+	tracedCfg := configs[0] // Configs are sorted by address, so this is the config of node 1
+	trace, err := consensus.ReadTrace(tracedCfg.Consensus.TraceFile)
+	require.NoError(t, err)
+	require.Equal(t, typesCons.TraceEntryType_TRACE_ENTRY_DEBUG, trace[0].Type)
+
+	// Node 1 sends its votes to the leader, node 2, and receives the votes of the other replicas through it
+	leaderAddress := pocketNodes[2].Address.String()
+	numVotes := 0
+	for _, entry := range trace {
+		require.NotNil(t, entry.Timestamp)
+		if entry.Type != typesCons.TraceEntryType_TRACE_ENTRY_OUTBOUND || entry.Message.MessageName() != consensus.HotstuffMessage {
+			continue
+		}
+		var msg typesCons.HotstuffMessage
+		require.NoError(t, entry.Message.UnmarshalTo(&msg))
+		if msg.Type == consensus.Vote {
+			require.Equal(t, leaderAddress, entry.Peer)
+			numVotes++
+		} else {
+			require.Empty(t, entry.Peer)
+		}
+	}
+	require.Equal(t, 3, numVotes) // PREPARE, PRECOMMIT & COMMIT
+
+	// A fresh node created with the config of a replica or of the leader reaches the same state by replaying its
+	// trace; the leader proposes the block it recorded again
+	for _, nodeId := range []typesCons.NodeId{1, 2} {
+		replayedNode := replayNodeTrace(t, configs[nodeId-1])
+		originalSnapshot := pocketNodes[nodeId].GetBus().GetConsensusModule().StateSnapshot()
+		replayedSnapshot := replayedNode.GetBus().GetConsensusModule().StateSnapshot()
+		require.True(t, proto.Equal(originalSnapshot, replayedSnapshot), "node %d original: %v, replayed: %v", nodeId, originalSnapshot, replayedSnapshot)
+		require.True(t, proto.Equal(getCommittedBlock(t, pocketNodes[nodeId], 1), getCommittedBlock(t, replayedNode, 1)))
+	}
+
+	// Traces cannot be replayed while the pacemaker runs on its own
+	automaticNode := CreateTestConsensusPocketNode(t, GenerateNodeConfigs(t, numNodes)[0], make(modules.EventsChannel, 100))
+	require.ErrorIs(t, consensus.ReplayTrace(automaticNode.GetBus().GetConsensusModule(), trace, nil), typesCons.ErrReplayRequiresManualPacemaker)
+
+	// The trace of the previous start of a node is overwritten once it starts again
+	restartedMod, err := consensus.Create(tracedCfg)
+	require.NoError(t, err)
+	trace, err = consensus.ReadTrace(tracedCfg.Consensus.TraceFile)
+	require.NoError(t, err)
+	require.Empty(t, trace)
+	require.NoError(t, restartedMod.Stop())
+}
+
+// Replays the trace recorded by the node with config `tracedCfg` into a fresh node created with the same config.
+func replayNodeTrace(t *testing.T, tracedCfg *config.Config) *shared.Node {
+	trace, err := consensus.ReadTrace(tracedCfg.Consensus.TraceFile)
+	require.NoError(t, err)
+
+	replayCfg := *tracedCfg
+	replayConsensusCfg := *tracedCfg.Consensus
+	replayPacemakerCfg := *tracedCfg.Consensus.Pacemaker
+	replayPacemakerCfg.Manual = true
+	replayConsensusCfg.Pacemaker = &replayPacemakerCfg
+	replayConsensusCfg.TraceFile = ""
+	replayCfg.Consensus = &replayConsensusCfg
+	replayedNode := CreateTestConsensusPocketNode(t, &replayCfg, make(modules.EventsChannel, 100))
+
+	numReplayed := 0
+	err = consensus.ReplayTrace(replayedNode.GetBus().GetConsensusModule(), trace, func(int, *typesCons.TraceEntry) {
+		numReplayed++
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(trace), numReplayed)
+
+	return replayedNode
+}
@@ -0,0 +1,108 @@
+package consensus_tests
+
+import (
+	"testing"
+	"time"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	typesUtil "github.com/pokt-network/pocket/utility/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestGossipedTransactionReachesEveryMempool(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	// Create & start test pocket nodes
+	testChannel := make(modules.EventsChannel, 100)
+	pocketNodes := CreateTestConsensusPocketNodes(t, configs, testChannel)
+	StartAllTestPocketNodes(t, pocketNodes)
+
+	// A transaction accepted by one of the nodes
+	sender := configs[0].PrivateKey
+	msgSend, err := anypb.New(&typesUtil.MessageSend{
+		FromAddress: sender.Address(),
+		ToAddress:   configs[1].PrivateKey.Address(),
+		Amount:      "1000",
+	})
+	require.NoError(t, err)
+	tx := &typesUtil.Transaction{
+		Msg:   msgSend,
+		Fee:   "10000",
+		Nonce: types.BigIntToString(types.RandBigInt()),
+	}
+	require.NoError(t, tx.Sign(sender))
+	txBz, err := tx.Bytes()
+	require.NoError(t, err)
+
+	anyMsg, err := anypb.New(&typesCons.UtilityMessage{Transaction: txBz})
+	require.NoError(t, err)
+	P2PBroadcast(t, pocketNodes, anyMsg)
+
+	// Every node adds the gossiped transaction to its utility mempool
+	txs, err := WaitForUtilityTransactions(t, testChannel, numNodes, 1000)
+	require.NoError(t, err)
+	for _, receivedTx := range txs {
+		require.True(t, proto.Equal(tx, receivedTx))
+	}
+
+	// The original broadcast reaches every node so the transaction is not gossiped any further
+	_, err = WaitForNetworkUtilityMessages(t, testChannel, 0, 500)
+	require.NoError(t, err)
+}
+
+func TestSubmittedTransactionReachesPeerMempool(t *testing.T) {
+	// Test configs
+	numNodes := 4
+	configs := GenerateNodeConfigs(t, numNodes)
+
+	network, err := simnet.NewNetwork(simnet.Config{Seed: 11, MinLatency: 10 * time.Millisecond, MaxLatency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Create & start test pocket nodes, each reporting the transactions added to its mempool on its own channel
+	pocketNodes := make(IdToNodeMapping, numNodes)
+	testChannels := make(map[typesCons.NodeId]modules.EventsChannel, numNodes)
+	for i, cfg := range configs {
+		nodeId := typesCons.NodeId(i + 1)
+		testChannels[nodeId] = make(modules.EventsChannel, 100)
+		pocketNodes[nodeId] = CreateSimulatedConsensusPocketNode(t, cfg, network, testChannels[nodeId])
+	}
+	StartSimulatedPocketNodes(t, pocketNodes)
+
+	// A client submits a transaction to node A
+	sender := configs[0].PrivateKey
+	msgSend, err := anypb.New(&typesUtil.MessageSend{
+		FromAddress: sender.Address(),
+		ToAddress:   configs[1].PrivateKey.Address(),
+		Amount:      "1000",
+	})
+	require.NoError(t, err)
+	tx := &typesUtil.Transaction{
+		Msg:   msgSend,
+		Fee:   "10000",
+		Nonce: types.BigIntToString(types.RandBigInt()),
+	}
+	require.NoError(t, tx.Sign(sender))
+	txBz, err := tx.Bytes()
+	require.NoError(t, err)
+
+	nodeA, nodeB := typesCons.NodeId(1), typesCons.NodeId(2)
+	require.NoError(t, pocketNodes[nodeA].GetBus().GetConsensusModule().SubmitTransaction(txBz))
+
+	// Node A checked the transaction against its mempool before gossiping it
+	txs, err := WaitForUtilityTransactions(t, testChannels[nodeA], 1, 1000)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(tx, txs[0]))
+
+	// Once the gossip is delivered, the transaction lands in the mempool of node B
+	network.RunUntilIdle(100)
+	txs, err = WaitForUtilityTransactions(t, testChannels[nodeB], 1, 1000)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(tx, txs[0]))
+}
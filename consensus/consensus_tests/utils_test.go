@@ -16,6 +16,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pokt-network/pocket/consensus"
 	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/p2p/simnet"
 	"github.com/pokt-network/pocket/shared"
 	"github.com/pokt-network/pocket/shared/config"
 	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
@@ -23,6 +24,7 @@ import (
 	modulesMock "github.com/pokt-network/pocket/shared/modules/mocks"
 	"github.com/pokt-network/pocket/shared/types"
 	"github.com/pokt-network/pocket/shared/types/genesis"
+	typesUtil "github.com/pokt-network/pocket/utility/types"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -40,10 +42,13 @@ var failOnExtraMessages bool
 // TODO(integration): These are temporary variables used in the prototype integration phase that
 // will need to be parameterized later once the test framework design matures.
 var appHash []byte
-var maxTxBytes = 90000
-var emptyByzValidators = make([][]byte, 0)
+var maxBlockBytes = uint64(4000000)
+var emptyMissedValidators = make([][]byte, 0)
 var emptyTxs = make([][]byte, 0)
 
+// The validator set changes the utility mock returns when applying the block at each height.
+var testValidatorUpdates = make(map[int64][]*modules.ValidatorUpdate)
+
 // Initialize certain unit test configurations on startup.
 func init() {
 	flag.BoolVar(&failOnExtraMessages, "failOnExtraMessages", false, "Fail if unexpected additional messages are received")
@@ -80,7 +85,7 @@ func GenerateNodeConfigs(t *testing.T, n int) (configs []*config.Config) {
 			P2P:   nil,
 			Consensus: &config.ConsensusConfig{
 				MaxMempoolBytes: 500000000,
-				MaxBlockBytes:   4000000,
+				MaxBlockBytes:   maxBlockBytes,
 				Pacemaker: &config.PacemakerConfig{
 					TimeoutMsec:               5000,
 					Manual:                    false,
@@ -139,19 +144,120 @@ func CreateTestConsensusPocketNode(
 	pocketNode := &shared.Node{
 		Address: cfg.PrivateKey.Address(),
 	}
-	pocketNode.SetBus(bus)
+	pocketNode.SetBus(&testBus{Bus: bus, started: make(chan struct{}, 1)})
+
+	return pocketNode
+}
+
+// Hands the signal published by a node once its modules are started to the test rather than to the bus, since the
+// event loop of the node reads the same bus and could otherwise consume the signal before the test does.
+type testBus struct {
+	modules.Bus
+	started chan struct{}
+}
+
+func (b *testBus) PublishEventToBus(e *types.PocketEvent) {
+	if e.Topic == types.PocketTopic_POCKET_NODE_TOPIC {
+		b.started <- struct{}{}
+		return
+	}
+	b.Bus.PublishEventToBus(e)
+}
+
+// Creates pocket nodes that exchange messages through the simulated `network` rather than a p2p mock, so that
+// every message is delivered deterministically as the test advances the virtual clock of the network. The pacemaker
+// of every node is in manual mode, so its views are only driven by the events the test schedules on the network
+// (see `ScheduleNextView`) and nodes wait at the next height once a block is committed.
+func CreateSimulatedConsensusPocketNodes(
+	t *testing.T,
+	configs []*config.Config,
+	network *simnet.Network,
+) (pocketNodes IdToNodeMapping) {
+	pocketNodes = make(IdToNodeMapping, len(configs))
+	// Transactions checked by the utility mocks are reported on a channel that simulated tests do not read
+	testChannel := make(modules.EventsChannel, 100)
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].PrivateKey.Address().String() < configs[j].PrivateKey.Address().String()
+	})
+	for i, cfg := range configs {
+		pocketNodes[typesCons.NodeId(i+1)] = CreateSimulatedConsensusPocketNode(t, cfg, network, testChannel)
+	}
+	return
+}
+
+// Creates a single node on the simulated `network` (see `CreateSimulatedConsensusPocketNodes`) whose utility and
+// persistence mocks report to `testChannel`, so tests can tell which nodes received a transaction.
+func CreateSimulatedConsensusPocketNode(
+	t *testing.T,
+	cfg *config.Config,
+	network *simnet.Network,
+	testChannel modules.EventsChannel,
+) *shared.Node {
+	cfg.Consensus.Pacemaker.Manual = true
+	consensusMod, err := consensus.Create(cfg)
+	require.NoError(t, err)
+
+	persistenceMock := basePersistenceMock(t, testChannel)
+	p2pMod := network.CreateP2PModule(cfg.PrivateKey.Address())
+	utilityMock := baseUtilityMock(t, testChannel)
+
+	bus, err := shared.CreateBus(persistenceMock, p2pMod, utilityMock, consensusMod)
+	require.NoError(t, err)
+	// The event loop of simulated nodes is not started, so the consensus events published on their bus are
+	// discarded rather than filling it up
+	go func() {
+		for {
+			bus.GetBusEvent()
+		}
+	}()
 
+	pocketNode := &shared.Node{
+		Address: cfg.PrivateKey.Address(),
+	}
+	pocketNode.SetBus(bus)
 	return pocketNode
 }
 
 func StartAllTestPocketNodes(t *testing.T, pocketNodes IdToNodeMapping) {
 	for _, pocketNode := range pocketNodes {
 		go pocketNode.Start()
-		startEvent := pocketNode.GetBus().GetBusEvent()
-		require.Equal(t, startEvent.Topic, types.PocketTopic_POCKET_NODE_TOPIC)
+		<-pocketNode.GetBus().(*testBus).started
+	}
+}
+
+// Starts the modules of nodes created through `CreateSimulatedConsensusPocketNodes` without running their event
+// loops, since the simulated network hands every message to the modules of the recipient directly.
+func StartSimulatedPocketNodes(t *testing.T, pocketNodes IdToNodeMapping) {
+	for _, pocketNode := range pocketNodes {
+		require.NoError(t, pocketNode.GetBus().GetP2PModule().Start())
+		require.NoError(t, pocketNode.GetBus().GetConsensusModule().Start())
 	}
 }
 
+// Runs the simulated network until every node reached `height`. Since the pacemakers of simulated nodes are in
+// manual mode, the next view of a node is triggered as soon as it waits at a new height.
+func RunSimulatedNetworkUntilHeight(t *testing.T, network *simnet.Network, pocketNodes IdToNodeMapping, height uint64, maxMessages int) bool {
+	triggeredHeights := make(map[typesCons.NodeId]uint64, len(pocketNodes))
+	return network.RunUntil(func() bool {
+		reachedHeight := true
+		// Nodes are visited in order so their next views are scheduled deterministically
+		for nodeId := typesCons.NodeId(1); int(nodeId) <= len(pocketNodes); nodeId++ {
+			pocketNode := pocketNodes[nodeId]
+			nodeHeight := pocketNode.GetBus().GetConsensusModule().StateSnapshot().Height
+			if triggeredHeight, ok := triggeredHeights[nodeId]; !ok || nodeHeight > triggeredHeight {
+				ScheduleNextView(t, network, pocketNode, 0)
+				// The first view moves the node from genesis to the first height
+				triggeredHeights[nodeId] = nodeHeight
+				if nodeHeight == 0 {
+					triggeredHeights[nodeId] = 1
+				}
+			}
+			reachedHeight = reachedHeight && nodeHeight >= height
+		}
+		return reachedHeight
+	}, maxMessages)
+}
+
 /*** Node Visibility/Reflection Helpers ***/
 
 // TODO(discuss): Should we use reflections inside the testing module as being done here or explicitly
@@ -165,12 +271,54 @@ func GetConsensusModImplementation(node *shared.Node) reflect.Value {
 	return reflect.ValueOf(node.GetBus().GetConsensusModule()).Elem()
 }
 
+// Waits until every node reached `step` at `height`. The nodes handle the messages of a test concurrently, so the
+// messages sent by the fastest of them can be observed before the others caught up.
+func WaitForConsensusNodesStep(t *testing.T, nodes IdToNodeMapping, height uint64, step typesCons.HotstuffStep, millis time.Duration) {
+	for _, node := range nodes {
+		require.Eventually(t, func() bool {
+			snapshot := node.GetBus().GetConsensusModule().StateSnapshot()
+			return snapshot.Height == height && snapshot.Step == uint32(step)
+		}, millis*time.Millisecond, 5*time.Millisecond)
+	}
+}
+
+// Subscribes to the consensus events of every node. The channels are large enough to hold the events of a test
+// that reads them once the network is idle.
+func SubscribeToConsensusEvents(pocketNodes IdToNodeMapping) map[typesCons.NodeId]chan *types.ConsensusEvent {
+	eventChannels := make(map[typesCons.NodeId]chan *types.ConsensusEvent, len(pocketNodes))
+	for nodeId, pocketNode := range pocketNodes {
+		eventChannels[nodeId] = make(chan *types.ConsensusEvent, 1000)
+		pocketNode.GetBus().GetConsensusModule().SubscribeToEvents(eventChannels[nodeId])
+	}
+	return eventChannels
+}
+
+// Returns the consensus events sent to `eventChannel` since the last call.
+func DrainConsensusEvents(eventChannel chan *types.ConsensusEvent) (events []*types.ConsensusEvent) {
+	for len(eventChannel) > 0 {
+		events = append(events, <-eventChannel)
+	}
+	return
+}
+
 /*** Debug/Development Message Helpers ***/
 
 func TriggerNextView(t *testing.T, node *shared.Node) {
 	triggerDebugMessage(t, node, types.DebugMessageAction_DEBUG_CONSENSUS_TRIGGER_NEXT_VIEW)
 }
 
+// Triggers the next view of `node` after `delay` on the virtual clock of the simulated network.
+func ScheduleNextView(t *testing.T, network *simnet.Network, node *shared.Node, delay time.Duration) {
+	debugMessage := &types.DebugMessage{
+		Action:  types.DebugMessageAction_DEBUG_CONSENSUS_TRIGGER_NEXT_VIEW,
+		Message: nil,
+	}
+	anyProto, err := anypb.New(debugMessage)
+	require.NoError(t, err)
+
+	require.NoError(t, network.Schedule(delay, node.Address, anyProto, types.PocketTopic_DEBUG_TOPIC))
+}
+
 func triggerDebugMessage(t *testing.T, node *shared.Node, action types.DebugMessageAction) {
 	debugMessage := &types.DebugMessage{
 		Action:  types.DebugMessageAction_DEBUG_CONSENSUS_TRIGGER_NEXT_VIEW,
@@ -183,6 +331,133 @@ func triggerDebugMessage(t *testing.T, node *shared.Node, action types.DebugMess
 	node.GetBus().PublishEventToBus(e)
 }
 
+/*** Hotstuff Message Helpers ***/
+
+// Returns a placeholder block proposed by `proposer` at the specified height, which extends the genesis block
+func placeholderBlock(t *testing.T, height uint64, proposer *shared.Node) *types.Block {
+	blockHeader := &types.BlockHeader{
+		Height:            int64(height),
+		NumTxs:            0,
+		LastBlockHash:     "",
+		ProposerAddress:   []byte(proposer.Address),
+		QuorumCertificate: nil,
+		AppHash:           hex.EncodeToString(appHash),
+		TransactionsRoot:  types.TransactionsRoot(emptyTxs),
+		ValidatorsHash:    genesisValidatorSetHash(t),
+	}
+	blockHash, err := blockHeader.ComputeHash()
+	require.NoError(t, err)
+	blockHeader.Hash = blockHash
+
+	return &types.Block{
+		BlockHeader:  blockHeader,
+		Transactions: emptyTxs,
+	}
+}
+
+// Returns the hash of the genesis validator set, which the test nodes vote with unless it is updated
+func genesisValidatorSetHash(t *testing.T) string {
+	genesisState, err := genesis.GenesisStateFromGenesisSource(&genesis.GenesisSource{
+		Source: &genesis.GenesisSource_Config{
+			Config: genesisConfig(),
+		},
+	})
+	require.NoError(t, err)
+
+	validators := genesisState.Validators
+	sort.Slice(validators, func(i, j int) bool {
+		return hex.EncodeToString(validators[i].Address) < hex.EncodeToString(validators[j].Address)
+	})
+	validatorsHash, err := consensus.ValidatorSetHash(validators)
+	require.NoError(t, err)
+	return validatorsHash
+}
+
+// Signs a vote the same way a validator would so tests can construct (possibly forged) quorum certificates
+func SignVote(
+	t *testing.T,
+	privKey cryptoPocket.PrivateKey,
+	height uint64,
+	step typesCons.HotstuffStep,
+	round uint64,
+	block *types.Block,
+) *typesCons.PartialSignature {
+	voteToSign := &typesCons.SignableVote{
+		Height:    height,
+		Round:     round,
+		Step:      step,
+		BlockHash: block.BlockHeader.Hash,
+	}
+	bytesToSign, err := proto.Marshal(voteToSign)
+	require.NoError(t, err)
+
+	signature, err := privKey.Sign(bytesToSign)
+	require.NoError(t, err)
+
+	return &typesCons.PartialSignature{
+		Signature: signature,
+		Address:   privKey.Address().String(),
+	}
+}
+
+// Returns a QC for `block` signed by the validators with the provided configs
+func SignQuorumCertificate(
+	t *testing.T,
+	configs []*config.Config,
+	height uint64,
+	step typesCons.HotstuffStep,
+	round uint64,
+	block *types.Block,
+) *typesCons.QuorumCertificate {
+	partialSigs := make([]*typesCons.PartialSignature, 0, len(configs))
+	for _, config := range configs {
+		partialSigs = append(partialSigs, SignVote(t, config.PrivateKey, height, step, round, block))
+	}
+	return &typesCons.QuorumCertificate{
+		Height:    height,
+		Step:      step,
+		Round:     round,
+		Block:     block,
+		BlockHash: block.BlockHeader.Hash,
+		ThresholdSignature: &typesCons.ThresholdSignature{
+			Signatures: partialSigs,
+		},
+	}
+}
+
+// Signs a timeout the same way a validator would so tests can construct (possibly forged) timeout certificates
+func SignTimeout(t *testing.T, privKey cryptoPocket.PrivateKey, height, round uint64) *typesCons.PartialSignature {
+	timeoutToSign := &typesCons.SignableTimeout{
+		Height: height,
+		Round:  round,
+	}
+	bytesToSign, err := proto.Marshal(timeoutToSign)
+	require.NoError(t, err)
+
+	signature, err := privKey.Sign(bytesToSign)
+	require.NoError(t, err)
+
+	return &typesCons.PartialSignature{
+		Signature: signature,
+		Address:   privKey.Address().String(),
+	}
+}
+
+// Returns a TimeoutQC for (height, round) signed by the validators with the provided configs
+func SignTimeoutQC(t *testing.T, configs []*config.Config, height, round uint64) *typesCons.TimeoutQuorumCertificate {
+	partialSigs := make([]*typesCons.PartialSignature, 0, len(configs))
+	for _, config := range configs {
+		partialSigs = append(partialSigs, SignTimeout(t, config.PrivateKey, height, round))
+	}
+	return &typesCons.TimeoutQuorumCertificate{
+		Height: height,
+		Round:  round,
+		ThresholdSignature: &typesCons.ThresholdSignature{
+			Signatures: partialSigs,
+		},
+	}
+}
+
 /*** P2P Helpers ***/
 
 func P2PBroadcast(_ *testing.T, nodes IdToNodeMapping, any *anypb.Any) {
@@ -207,6 +482,10 @@ func WaitForNetworkConsensusMessages(
 ) (messages []*anypb.Any, err error) {
 
 	includeFilter := func(m *anypb.Any) bool {
+		if string(m.MessageName()) != consensus.HotstuffMessage {
+			return false
+		}
+
 		var hotstuffMessage typesCons.HotstuffMessage
 		err := anypb.UnmarshalTo(m, &hotstuffMessage, proto.UnmarshalOptions{})
 		require.NoError(t, err)
@@ -218,6 +497,120 @@ func WaitForNetworkConsensusMessages(
 	return waitForNetworkConsensusMessagesInternal(t, testChannel, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, numMessages, millis, includeFilter, errorMessage)
 }
 
+func WaitForNetworkBlockSyncMessages(
+	t *testing.T,
+	testChannel modules.EventsChannel,
+	messageName string,
+	numMessages int,
+	millis time.Duration,
+) (messages []*anypb.Any, err error) {
+
+	includeFilter := func(m *anypb.Any) bool {
+		return string(m.MessageName()) == messageName
+	}
+
+	errorMessage := fmt.Sprintf("Block sync message: %s", messageName)
+	return waitForNetworkConsensusMessagesInternal(t, testChannel, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, numMessages, millis, includeFilter, errorMessage)
+}
+
+// Waits for transactions submitted to the utility mempool, which the utility mock forwards to the test channel
+func WaitForUtilityTransactions(
+	t *testing.T,
+	testChannel modules.EventsChannel,
+	numTransactions int,
+	millis time.Duration,
+) (transactions []*typesUtil.Transaction, err error) {
+
+	includeFilter := func(m *anypb.Any) bool {
+		return m.MessageIs(&typesUtil.Transaction{})
+	}
+
+	messages, err := waitForNetworkConsensusMessagesInternal(t, testChannel, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, numTransactions, millis, includeFilter, "Utility transactions")
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		tx := &typesUtil.Transaction{}
+		require.NoError(t, anypb.UnmarshalTo(message, tx, proto.UnmarshalOptions{}))
+		transactions = append(transactions, tx)
+	}
+	return
+}
+
+// Waits for transactions gossiped over the network by the nodes that accepted them
+func WaitForNetworkUtilityMessages(
+	t *testing.T,
+	testChannel modules.EventsChannel,
+	numMessages int,
+	millis time.Duration,
+) (utilityMessages []*typesCons.UtilityMessage, err error) {
+
+	includeFilter := func(m *anypb.Any) bool {
+		return string(m.MessageName()) == consensus.UtilityMessage
+	}
+
+	messages, err := waitForNetworkConsensusMessagesInternal(t, testChannel, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, numMessages, millis, includeFilter, "Utility messages")
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		utilityMessage := &typesCons.UtilityMessage{}
+		require.NoError(t, anypb.UnmarshalTo(message, utilityMessage, proto.UnmarshalOptions{}))
+		utilityMessages = append(utilityMessages, utilityMessage)
+	}
+	return
+}
+
+// Waits for the NewRound messages broadcast by nodes that started the specified round
+func WaitForNetworkNewRoundMessages(
+	t *testing.T,
+	testChannel modules.EventsChannel,
+	round uint64,
+	numMessages int,
+	millis time.Duration,
+) (messages []*anypb.Any, err error) {
+
+	includeFilter := func(m *anypb.Any) bool {
+		if string(m.MessageName()) != consensus.HotstuffMessage {
+			return false
+		}
+
+		var hotstuffMessage typesCons.HotstuffMessage
+		err := anypb.UnmarshalTo(m, &hotstuffMessage, proto.UnmarshalOptions{})
+		require.NoError(t, err)
+
+		return hotstuffMessage.Type == consensus.Propose && hotstuffMessage.Step == consensus.NewRound && hotstuffMessage.Round == round
+	}
+
+	errorMessage := fmt.Sprintf("NewRound messages for round: %d", round)
+	return waitForNetworkConsensusMessagesInternal(t, testChannel, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, numMessages, millis, includeFilter, errorMessage)
+}
+
+// Waits for the timeouts broadcast by nodes that gave up on the specified round
+func WaitForNetworkTimeoutMessages(
+	t *testing.T,
+	testChannel modules.EventsChannel,
+	round uint64,
+	numMessages int,
+	millis time.Duration,
+) (messages []*anypb.Any, err error) {
+
+	includeFilter := func(m *anypb.Any) bool {
+		if string(m.MessageName()) != consensus.TimeoutMessage {
+			return false
+		}
+
+		var timeoutMessage typesCons.TimeoutMessage
+		err := anypb.UnmarshalTo(m, &timeoutMessage, proto.UnmarshalOptions{})
+		require.NoError(t, err)
+
+		return timeoutMessage.Round == round
+	}
+
+	errorMessage := fmt.Sprintf("Timeout messages for round: %d", round)
+	return waitForNetworkConsensusMessagesInternal(t, testChannel, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, numMessages, millis, includeFilter, errorMessage)
+}
+
 // IMPROVE(olshansky): Translate this to use generics.
 func waitForNetworkConsensusMessagesInternal(
 	_ *testing.T,
@@ -230,7 +623,14 @@ func waitForNetworkConsensusMessagesInternal(
 ) (messages []*anypb.Any, err error) {
 	messages = make([]*anypb.Any, 0)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*millis)
+	defer cancel()
 	unused := make([]*types.PocketEvent, 0) // TODO: Move this into a pool rather than resending back to the eventbus.
+	// The events that were not waited for are sent back even if waiting fails, so later waits can still find them.
+	defer func() {
+		for _, u := range unused {
+			testChannel <- *u
+		}
+	}()
 loop:
 	for {
 		select {
@@ -259,18 +659,12 @@ loop:
 			if numMessages == 0 {
 				break loop
 			} else if numMessages > 0 {
-				cancel()
 				return nil, fmt.Errorf("Missing %s messages; missing: %d, received: %d; (%s)", topic, numMessages, len(messages), errorMessage)
 			} else {
-				cancel()
 				return nil, fmt.Errorf("Too many %s messages received; expected: %d, received: %d; (%s)", topic, numMessages+len(messages), len(messages), errorMessage)
 			}
 		}
 	}
-	cancel()
-	for _, u := range unused {
-		testChannel <- *u
-	}
 	return
 }
 
@@ -308,12 +702,13 @@ func baseP2PMock(t *testing.T, testChannel modules.EventsChannel) *modulesMock.M
 			testChannel <- *e
 		}).
 		AnyTimes()
+	p2pMock.EXPECT().UpdateAddrBook(gomock.Any()).Return(nil).AnyTimes()
 
 	return p2pMock
 }
 
 // Creates a utility module mock with mock implementations of some basic functionality
-func baseUtilityMock(t *testing.T, _ modules.EventsChannel) *modulesMock.MockUtilityModule {
+func baseUtilityMock(t *testing.T, testChannel modules.EventsChannel) *modulesMock.MockUtilityModule {
 	ctrl := gomock.NewController(t)
 	utilityMock := modulesMock.NewMockUtilityModule(ctrl)
 	utilityContextMock := modulesMock.NewMockUtilityContext(ctrl)
@@ -325,18 +720,37 @@ func baseUtilityMock(t *testing.T, _ modules.EventsChannel) *modulesMock.MockUti
 		// NewContext(int64(1)).
 		NewContext(gomock.Any()).
 		Return(utilityContextMock, nil).
-		MaxTimes(4)
+		AnyTimes()
 
 	utilityContextMock.EXPECT().GetPersistenceContext().Return(persistenceContextMock).AnyTimes()
 	utilityContextMock.EXPECT().ReleaseContext().Return().AnyTimes()
+	utilityContextMock.EXPECT().CommitContext().Return(nil).AnyTimes()
 	utilityContextMock.EXPECT().
-		GetTransactionsForProposal(gomock.Any(), maxTxBytes, gomock.AssignableToTypeOf(emptyByzValidators)).
-		Return(make([][]byte, 0), nil).
+		GetTransactionsForProposal(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(emptyMissedValidators)).
+		DoAndReturn(func(_ []byte, maxTransactionBytes int, _ [][]byte) ([][]byte, error) {
+			// The block header takes up part of the block
+			require.Less(t, maxTransactionBytes, int(maxBlockBytes))
+			require.Greater(t, maxTransactionBytes, 0)
+			return make([][]byte, 0), nil
+		}).
 		AnyTimes()
 	utilityContextMock.EXPECT().
-		// ApplyBlock(int64(1), gomock.Any(), gomock.AssignableToTypeOf(emptyTxs), gomock.AssignableToTypeOf(emptyByzValidators)).
+		// ApplyBlock(int64(1), gomock.Any(), gomock.AssignableToTypeOf(emptyTxs), gomock.AssignableToTypeOf(emptyMissedValidators)).
 		ApplyBlock(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(appHash, nil).
+		DoAndReturn(func(height int64, _ []byte, _ [][]byte, _ [][]byte) ([]byte, []*modules.ValidatorUpdate, error) {
+			return appHash, testValidatorUpdates[height], nil
+		}).
+		AnyTimes()
+	utilityContextMock.EXPECT().
+		CheckTransaction(gomock.Any()).
+		DoAndReturn(func(txBz []byte) error {
+			tx, err := typesUtil.TransactionFromBytes(txBz)
+			require.Nil(t, err)
+			anyTx, er := anypb.New(tx)
+			require.NoError(t, er)
+			testChannel <- types.PocketEvent{Topic: types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, Data: anyTx}
+			return nil
+		}).
 		AnyTimes()
 
 	persistenceContextMock.EXPECT().Commit().Return(nil).AnyTimes()
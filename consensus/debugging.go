@@ -9,6 +9,11 @@ import (
 )
 
 func (m *consensusModule) HandleDebugMessage(debugMessage *types.DebugMessage) error {
+	m.m.Lock()
+	defer m.m.Unlock()
+	defer m.publishViewChange()
+	m.traceDebugMessage(debugMessage)
+
 	switch debugMessage.Action {
 	case types.DebugMessageAction_DEBUG_CONSENSUS_RESET_TO_GENESIS:
 		m.resetToGenesis(debugMessage)
@@ -25,17 +30,13 @@ func (m *consensusModule) HandleDebugMessage(debugMessage *types.DebugMessage) e
 }
 
 func (m *consensusModule) GetNodeState() typesCons.ConsensusNodeState {
-	leaderId := typesCons.NodeId(0)
-	if m.LeaderId != nil {
-		leaderId = *m.LeaderId
-	}
 	return typesCons.ConsensusNodeState{
 		NodeId:   m.NodeId,
 		Height:   m.Height,
 		Round:    uint8(m.Round),
 		Step:     uint8(m.Step),
 		IsLeader: m.isLeader(),
-		LeaderId: leaderId,
+		LeaderId: m.getLeaderId(),
 	}
 }
 
@@ -49,9 +50,39 @@ func (m *consensusModule) resetToGenesis(_ *types.DebugMessage) {
 
 	m.HighPrepareQC = nil
 	m.LockedQC = nil
+	m.TimeoutQC = nil
+
+	m.appHash = ""
+	m.lastBlockHash = ""
+	m.lastCommitQC = nil
 
 	m.clearLeader()
 	m.clearMessagesPool()
+	m.clearTimeoutPool()
+
+	m.lastMissedValidators = make([][]byte, 0)
+	m.validatorUpdates = nil
+
+	m.CommittedBlocks = make(map[uint64]*typesCons.CommittedBlock)
+	m.syncTargetHeight = 0
+
+	m.pendingBlocks = make(map[string]*types.Block)
+
+	m.walLastVote = nil
+	m.walLastEntry = nil
+	if m.blockStore != nil {
+		if err := m.blockStore.reset(); err != nil {
+			m.nodeLogError(typesCons.ErrBlockStore.Error(), err)
+		}
+	}
+	if m.wal != nil {
+		if err := m.wal.reset(); err != nil {
+			m.nodeLogError(typesCons.ErrWriteWAL.Error(), err)
+		}
+	}
+	if err := m.signGuard.reset(); err != nil {
+		m.nodeLogError(typesCons.ErrWriteLastSignedState.Error(), err)
+	}
 }
 
 func (m *consensusModule) printNodeState(_ *types.DebugMessage) {
@@ -64,13 +95,20 @@ func (m *consensusModule) triggerNextView(_ *types.DebugMessage) {
 
 	if m.Height == 0 || (m.Step == Decide && m.paceMaker.IsManualMode()) {
 		m.paceMaker.NewHeight()
-	} else {
-		m.paceMaker.InterruptRound()
+		if m.paceMaker.IsManualMode() {
+			m.paceMaker.ForceNextView()
+		}
+		return
 	}
 
-	if m.paceMaker.IsManualMode() {
+	// In manual mode, the first view of every height waits to be triggered once the previous block is committed.
+	if m.paceMaker.IsNextViewPending() {
 		m.paceMaker.ForceNextView()
+		return
 	}
+
+	// The next round only starts once enough validators are triggered to form a TimeoutQC.
+	m.paceMaker.InterruptRound()
 }
 
 func (m *consensusModule) togglePacemakerManualMode(_ *types.DebugMessage) {
@@ -81,12 +119,18 @@ func (m *consensusModule) togglePacemakerManualMode(_ *types.DebugMessage) {
 		m.nodeLog(typesCons.DebugTogglePacemakerManualMode("AUTOMATIC"))
 	}
 	m.paceMaker.SetManualMode(newMode)
+
+	// The timer of the current step was not started in manual mode.
+	if !newMode {
+		m.paceMaker.RestartTimer()
+	}
 }
 
 // This Pacemaker interface is only used for development & debugging purposes.
 type PacemakerDebug interface {
 	SetManualMode(bool)
 	IsManualMode() bool
+	IsNextViewPending() bool
 	ForceNextView()
 }
 
@@ -95,6 +139,7 @@ type paceMakerDebug struct {
 	debugTimeBetweenStepsMsec uint64
 
 	quorumCertificate *typesCons.QuorumCertificate
+	nextViewPending   bool // The NEWROUND of the current view was held back in manual mode until it is triggered
 }
 
 func (p *paceMaker) IsManualMode() bool {
@@ -105,6 +150,10 @@ func (p *paceMaker) SetManualMode(manualMode bool) {
 	p.manualMode = manualMode
 }
 
+func (p *paceMaker) IsNextViewPending() bool {
+	return p.nextViewPending
+}
+
 func (p *paceMaker) ForceNextView() {
 	lastQC := p.quorumCertificate
 	p.startNextView(lastQC, true)
@@ -0,0 +1,217 @@
+package consensus
+
+import (
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// The events waiting to be published on the bus; see `forwardEventsToBus`.
+const consensusEventsBufferSize = 100
+
+// The (height, round, step) of the node; view transitions are published as a single event even if the node goes
+// through several steps while handling a single message (e.g. DECIDE followed by the NEWROUND of the next height).
+type consensusView struct {
+	height uint64
+	round  uint64
+	step   typesCons.HotstuffStep
+}
+
+func (m *consensusModule) SubscribeToEvents(events chan<- *types.ConsensusEvent) {
+	m.eventSubscribersMu.Lock()
+	defer m.eventSubscribersMu.Unlock()
+	m.eventSubscribers = append(m.eventSubscribers, events)
+}
+
+func (m *consensusModule) StateSnapshot() *types.ConsensusStateSnapshot {
+	m.stateSnapshotMu.Lock()
+	defer m.stateSnapshotMu.Unlock()
+	return proto.Clone(m.stateSnapshot).(*types.ConsensusStateSnapshot)
+}
+
+// The snapshot is copied from the consensus state rather than read from it on demand, so it can be queried
+// concurrently with the module handling messages.
+func (m *consensusModule) refreshStateSnapshot() {
+	messagePoolCounts := make(map[uint32]uint32, len(m.MessagePool))
+	for step, msgs := range m.MessagePool {
+		if len(msgs) > 0 {
+			messagePoolCounts[uint32(step)] = uint32(len(msgs))
+		}
+	}
+
+	snapshot := &types.ConsensusStateSnapshot{
+		NodeId:   uint64(m.NodeId),
+		Height:   m.Height,
+		Round:    m.Round,
+		Step:     uint32(m.Step),
+		LeaderId: uint64(m.getLeaderId()),
+		IsLeader: m.isLeader(),
+
+		HighPrepareQc: m.summarizeQC(m.HighPrepareQC),
+		LockedQc:      m.summarizeQC(m.LockedQC),
+		TimeoutQc:     m.summarizeTimeoutQC(m.TimeoutQC),
+
+		MessagePoolCounts: messagePoolCounts,
+		TimeoutPoolCount:  uint32(len(m.timeoutPool)),
+
+		LastBlockHash:    m.lastBlockHash,
+		AppHash:          m.appHash,
+		SyncTargetHeight: m.syncTargetHeight,
+	}
+
+	m.stateSnapshotMu.Lock()
+	defer m.stateSnapshotMu.Unlock()
+	m.stateSnapshot = snapshot
+}
+
+// Publishes the view the node moved to since the last event, if any, and refreshes the state snapshot. This is
+// called whenever the node is done handling a message or a pacemaker timeout, with the lock of the module held, and
+// before any other event is published so the events are observed in the order they happened.
+func (m *consensusModule) publishViewChange() {
+	defer m.refreshStateSnapshot()
+
+	view := m.currentView()
+	if view == m.publishedView {
+		return
+	}
+
+	var eventType types.ConsensusEventType
+	switch {
+	case view.height != m.publishedView.height:
+		eventType = types.ConsensusEventType_CONSENSUS_EVENT_NEW_HEIGHT
+	case view.round != m.publishedView.round:
+		eventType = types.ConsensusEventType_CONSENSUS_EVENT_NEW_ROUND
+	default:
+		eventType = types.ConsensusEventType_CONSENSUS_EVENT_STEP_CHANGED
+	}
+	m.publishedView = view
+	m.publishEvent(m.newConsensusEvent(eventType))
+}
+
+func (m *consensusModule) publishLeaderElected() {
+	m.publishViewChange()
+	m.publishEvent(m.newConsensusEvent(types.ConsensusEventType_CONSENSUS_EVENT_LEADER_ELECTED))
+}
+
+func (m *consensusModule) publishQCFormed(qc *types.QuorumCertificateSummary) {
+	m.publishViewChange()
+	event := m.newConsensusEvent(types.ConsensusEventType_CONSENSUS_EVENT_QC_FORMED)
+	event.QuorumCertificate = qc
+	m.publishEvent(event)
+}
+
+func (m *consensusModule) publishBlockCommitted(blockHash string) {
+	m.publishViewChange()
+	event := m.newConsensusEvent(types.ConsensusEventType_CONSENSUS_EVENT_BLOCK_COMMITTED)
+	event.BlockHash = blockHash
+	m.publishEvent(event)
+}
+
+func (m *consensusModule) publishTimeout() {
+	m.publishViewChange()
+	m.publishEvent(m.newConsensusEvent(types.ConsensusEventType_CONSENSUS_EVENT_TIMEOUT))
+}
+
+func (m *consensusModule) newConsensusEvent(eventType types.ConsensusEventType) *types.ConsensusEvent {
+	return &types.ConsensusEvent{
+		Type:     eventType,
+		NodeId:   uint64(m.NodeId),
+		Height:   m.Height,
+		Round:    m.Round,
+		Step:     uint32(m.Step),
+		LeaderId: uint64(m.getLeaderId()),
+	}
+}
+
+// Events are published while the node handles a message or a pacemaker timeout, so they are dropped rather than
+// blocking the state machine if the bus or a subscriber does not keep up.
+func (m *consensusModule) publishEvent(event *types.ConsensusEvent) {
+	if m.busEvents != nil {
+		select {
+		case m.busEvents <- event:
+		default:
+			m.nodeLog(typesCons.WarnDroppedConsensusEvent(event.Type.String(), "the bus"))
+		}
+	}
+
+	m.eventSubscribersMu.Lock()
+	defer m.eventSubscribersMu.Unlock()
+	for _, events := range m.eventSubscribers {
+		select {
+		case events <- event:
+		default:
+			m.nodeLog(typesCons.WarnDroppedConsensusEvent(event.Type.String(), "a subscriber"))
+		}
+	}
+}
+
+// Publishes the events on the bus under `CONSENSUS_EVENT_TOPIC` in the order they happened. The events are handed
+// over through a buffer rather than published directly, since the event loop of the node that reads the bus is also
+// the one handling the messages the events are published from.
+func (m *consensusModule) forwardEventsToBus(events <-chan *types.ConsensusEvent, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case event := <-events:
+			anyEvent, err := anypb.New(event)
+			if err != nil {
+				m.nodeLogError(typesCons.ErrPublishConsensusEvent.Error(), err)
+				continue
+			}
+			m.GetBus().PublishEventToBus(&types.PocketEvent{Topic: types.PocketTopic_CONSENSUS_EVENT_TOPIC, Data: anyEvent})
+		}
+	}
+}
+
+func (m *consensusModule) currentView() consensusView {
+	return consensusView{
+		height: m.Height,
+		round:  m.Round,
+		step:   m.Step,
+	}
+}
+
+func (m *consensusModule) getLeaderId() typesCons.NodeId {
+	if m.LeaderId == nil {
+		return 0
+	}
+	return *m.LeaderId
+}
+
+func (m *consensusModule) summarizeQC(qc *typesCons.QuorumCertificate) *types.QuorumCertificateSummary {
+	if qc == nil {
+		return nil
+	}
+	return &types.QuorumCertificateSummary{
+		Height:        qc.Height,
+		Round:         qc.Round,
+		Step:          uint32(qc.Step),
+		BlockHash:     qc.BlockHash,
+		NumSignatures: m.countSigners(qc.GetThresholdSignature()),
+	}
+}
+
+func (m *consensusModule) summarizeTimeoutQC(timeoutQC *typesCons.TimeoutQuorumCertificate) *types.QuorumCertificateSummary {
+	if timeoutQC == nil {
+		return nil
+	}
+	return &types.QuorumCertificateSummary{
+		Height:        timeoutQC.Height,
+		Round:         timeoutQC.Round,
+		NumSignatures: m.countSigners(timeoutQC.GetThresholdSignature()),
+	}
+}
+
+// Only used to summarize QCs, so a threshold signature that does not match the validator set has no signers.
+func (m *consensusModule) countSigners(thresholdSig *typesCons.ThresholdSignature) uint32 {
+	if thresholdSig == nil {
+		return 0
+	}
+	signerBitmap, err := m.thresholdSigner.SignerBitmap(m.getValidatorList(), thresholdSig)
+	if err != nil {
+		return 0
+	}
+	return uint32(signerBitmap.Count())
+}
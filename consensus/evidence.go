@@ -0,0 +1,140 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/types"
+	typesUtil "github.com/pokt-network/pocket/utility/types"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// TECHDEBT: Utility charges the governance fee for a `MessageDoubleSign` regardless of the value in the
+// transaction, so consensus only needs to provide a valid amount until the fee is exposed to other modules.
+const doubleSignEvidenceFee = "0"
+
+// Identifies an equivocation: a validator can only double sign once per view.
+type doubleSignKey struct {
+	address string
+	view    consensusView
+}
+
+// Returns the vote already in the message pool that conflicts with `msg`, if there is one. Two votes conflict
+// if they were signed by the same validator for the same (height, step, round) but for different block hashes.
+func (m *consensusModule) findConflictingVote(msg *typesCons.HotstuffMessage) *typesCons.HotstuffMessage {
+	if msg.Type != Vote || msg.GetPartialSignature() == nil || msg.Block == nil {
+		return nil
+	}
+
+	address := msg.GetPartialSignature().Address
+	for _, poolMsg := range m.MessagePool[msg.Step] {
+		if poolMsg.GetPartialSignature() == nil || poolMsg.GetPartialSignature().Address != address {
+			continue
+		}
+		if poolMsg.Height != msg.Height || poolMsg.Round != msg.Round || poolMsg.Block == nil {
+			continue
+		}
+		if poolMsg.Block.GetBlockHeader().GetHash() != msg.Block.GetBlockHeader().GetHash() {
+			return poolMsg
+		}
+	}
+	return nil
+}
+
+// Wraps both votes in a `MessageDoubleSign` transaction signed by this node and submits it to the utility
+// mempool, and through it to the rest of the network, so the double signer is burnt once the evidence is included in a block.
+// Every equivocation is only reported once, however many copies of the conflicting vote are received.
+func (m *consensusModule) submitDoubleSignEvidence(voteA, voteB *typesCons.HotstuffMessage) error {
+	address := voteA.GetPartialSignature().Address
+	key := doubleSignKey{address: address, view: consensusView{height: voteA.Height, round: voteA.Round, step: voteA.Step}}
+	if _, ok := m.reportedDoubleSigns[key]; ok {
+		return nil
+	}
+	validator, ok := m.validatorMap[address]
+	if !ok {
+		return typesCons.ErrMissingValidator(address, m.ValAddrToIdMap[address])
+	}
+	m.nodeLog(typesCons.DoubleSignDetected(address, m.ValAddrToIdMap[address], voteB))
+
+	evidenceA, err := newDoubleSignEvidenceVote(voteA, validator.PublicKey)
+	if err != nil {
+		return err
+	}
+	evidenceB, err := newDoubleSignEvidenceVote(voteB, validator.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	anyMsg, err := anypb.New(&typesUtil.MessageDoubleSign{
+		VoteA:           evidenceA,
+		VoteB:           evidenceB,
+		ReporterAddress: m.privateKey.Address(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tx := &typesUtil.Transaction{
+		Msg:   anyMsg,
+		Fee:   doubleSignEvidenceFee,
+		Nonce: doubleSignEvidenceNonce(evidenceA, evidenceB),
+	}
+	if err := tx.Sign(m.privateKey); err != nil {
+		return err
+	}
+	txBz, err := tx.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if err := m.submitTransaction(txBz); err != nil {
+		return err
+	}
+	m.markDoubleSignReported(key)
+	return nil
+}
+
+// Equivocations from previous heights can no longer be received, so they are forgotten as new ones are recorded.
+func (m *consensusModule) markDoubleSignReported(key doubleSignKey) {
+	for reported := range m.reportedDoubleSigns {
+		if reported.view.height < m.Height {
+			delete(m.reportedDoubleSigns, reported)
+		}
+	}
+	m.reportedDoubleSigns[key] = struct{}{}
+}
+
+// The nonce is derived from the equivocation rather than picked at random, so the evidence of the same double sign
+// produces the same transaction (ed25519 signatures are deterministic) and duplicates collide in the mempool.
+func doubleSignEvidenceNonce(voteA, voteB *typesUtil.Vote) string {
+	hashA, hashB := voteA.BlockHash, voteB.BlockHash
+	if bytes.Compare(hashA, hashB) > 0 {
+		hashA, hashB = hashB, hashA
+	}
+	view := make([]byte, 16)
+	binary.BigEndian.PutUint64(view[:8], uint64(voteA.Height))
+	binary.BigEndian.PutUint32(view[8:12], voteA.Round)
+	binary.BigEndian.PutUint32(view[12:], voteA.Step)
+	preimage := bytes.Join([][]byte{voteA.PublicKey, view, hashA, hashB}, nil)
+	return types.BigIntToString(new(big.Int).SetBytes(crypto.SHA3Hash(preimage)))
+}
+
+// The utility vote identifies the block by its hash, which is what the validator signed.
+func newDoubleSignEvidenceVote(msg *typesCons.HotstuffMessage, publicKey []byte) (*typesUtil.Vote, error) {
+	blockHash, err := hex.DecodeString(msg.Block.GetBlockHeader().GetHash())
+	if err != nil {
+		return nil, err
+	}
+	return &typesUtil.Vote{
+		PublicKey: publicKey,
+		Height:    int64(msg.Height),
+		Round:     uint32(msg.Round),
+		Step:      uint32(msg.Step),
+		Type:      typesUtil.DoubleSignEvidenceType,
+		BlockHash: blockHash,
+	}, nil
+}
@@ -2,13 +2,17 @@ package consensus
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"log"
+	"math/big"
 
 	"google.golang.org/protobuf/proto"
 
 	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/config"
 	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -25,21 +29,25 @@ const (
 	ByzantineThreshold = float64(2) / float64(3)
 	HotstuffMessage    = "consensus.HotstuffMessage"
 	UtilityMessage     = "consensus.UtilityMessage"
+	TimeoutMessage     = "consensus.TimeoutMessage"
 	Propose            = typesCons.HotstuffMessageType_HOTSTUFF_MESAGE_PROPOSE
 	Vote               = typesCons.HotstuffMessageType_HOTSTUFF_MESSAGE_VOTE
 )
 
+const (
+	BlockSyncRequestMessage  = "consensus.BlockSyncRequest"
+	BlockSyncResponseMessage = "consensus.BlockSyncResponse"
+)
+
 var (
 	HotstuffSteps = [...]typesCons.HotstuffStep{NewRound, Prepare, PreCommit, Commit, Decide}
-
-	maxTxBytes        = 90000             // TODO(olshansky): Move this to config.json.
-	lastByzValidators = make([][]byte, 0) // TODO(olshansky): Retrieve this from persistence
 )
 
 // ** Hotstuff Helpers ** //
 
 func (m *consensusModule) getQuorumCertificate(height uint64, step typesCons.HotstuffStep, round uint64) (*typesCons.QuorumCertificate, error) {
 	var pss []*typesCons.PartialSignature
+	signers := make(map[string]struct{}, len(m.MessagePool[step]))
 	for _, msg := range m.MessagePool[step] {
 		// TODO(olshansky): Add tests for this
 		if msg.GetPartialSignature() == nil {
@@ -51,12 +59,23 @@ func (m *consensusModule) getQuorumCertificate(height uint64, step typesCons.Hot
 			m.nodeLog(typesCons.WarnUnexpectedMessageInPool(msg, height, step, round))
 			continue
 		}
+		// Votes for any other block than the one being certified cannot be aggregated into its QC.
+		if blockHash := msg.Block.GetBlockHeader().GetHash(); blockHash != m.Block.GetBlockHeader().GetHash() {
+			m.nodeLog(typesCons.WarnUnexpectedBlockInPool(msg, blockHash))
+			continue
+		}
 		ps := msg.GetPartialSignature()
 
 		if ps.Signature == nil || len(ps.Address) == 0 {
 			m.nodeLog(typesCons.WarnIncompletePartialSig(ps, msg))
 			continue
 		}
+		// A validator may resend its vote, but it can only be counted once towards the QC.
+		if _, ok := signers[ps.Address]; ok {
+			m.nodeLog(typesCons.WarnDuplicatePartialSig(ps.Address, step))
+			continue
+		}
+		signers[ps.Address] = struct{}{}
 		pss = append(pss, msg.GetPartialSignature())
 	}
 
@@ -64,38 +83,82 @@ func (m *consensusModule) getQuorumCertificate(height uint64, step typesCons.Hot
 		return nil, err
 	}
 
-	thresholdSig, err := getThresholdSignature(pss)
+	if err := m.isStakeThresholdMet(signers); err != nil {
+		return nil, err
+	}
+
+	bytesSigned, err := getVoteSignableBytes(m.Height, step, m.Round, m.Block.GetBlockHeader().GetHash())
 	if err != nil {
 		return nil, err
 	}
 
-	return &typesCons.QuorumCertificate{
+	thresholdSig, err := m.thresholdSigner.Aggregate(m.getValidatorList(), bytesSigned, pss)
+	if err != nil {
+		return nil, err
+	}
+
+	qc := &typesCons.QuorumCertificate{
 		Height:             m.Height,
 		Step:               step,
 		Round:              m.Round,
 		Block:              m.Block,
+		BlockHash:          m.Block.GetBlockHeader().GetHash(),
 		ThresholdSignature: thresholdSig,
-	}, nil
+	}
+	m.publishQCFormed(m.summarizeQC(qc))
+
+	return qc, nil
 }
 
-func (m *consensusModule) findHighQC(step typesCons.HotstuffStep) (qc *typesCons.QuorumCertificate) {
-	for _, m := range m.MessagePool[step] {
-		if m.GetQuorumCertificate() == nil {
+// Returns the QC from the latest view amongst the leader's own HighPrepareQC and those carried by the messages in
+// the pool for `step`. Invalid QCs are ignored so a single byzantine validator cannot force the leader to propose
+// a block the rest of the network would reject.
+func (m *consensusModule) findHighQC(step typesCons.HotstuffStep) (highQC *typesCons.QuorumCertificate) {
+	candidates := []*typesCons.QuorumCertificate{m.HighPrepareQC}
+	for _, msg := range m.MessagePool[step] {
+		candidates = append(candidates, msg.GetQuorumCertificate())
+	}
+
+	for _, qc := range candidates {
+		if qc == nil {
+			continue
+		}
+		if err := m.validateHighQC(qc); err != nil {
+			m.nodeLog(typesCons.WarnInvalidHighQC(err.Error()))
 			continue
 		}
-		if qc == nil || m.GetQuorumCertificate().Height > qc.Height {
-			qc = m.GetQuorumCertificate()
+		if highQC == nil || isHigherView(qc, highQC) {
+			highQC = qc
 		}
 	}
 	return
 }
 
-func getThresholdSignature(
-	partialSigs []*typesCons.PartialSignature) (*typesCons.ThresholdSignature, error) {
-	thresholdSig := new(typesCons.ThresholdSignature)
-	thresholdSig.Signatures = make([]*typesCons.PartialSignature, len(partialSigs))
-	copy(thresholdSig.Signatures, partialSigs)
-	return thresholdSig, nil
+// A high QC is a valid PREPARE QC from an earlier round of the current height, which certifies a block that
+// extends the last committed block.
+func (m *consensusModule) validateHighQC(qc *typesCons.QuorumCertificate) error {
+	if qc.Step != Prepare {
+		return typesCons.ErrQCStepMismatch(Prepare, qc.Step)
+	}
+
+	if qc.Height != m.Height || qc.Round >= m.Round {
+		return typesCons.ErrInvalidHighQCView(qc.Height, qc.Round, m.Height, m.Round)
+	}
+
+	if err := m.validateQuorumCertificate(qc); err != nil {
+		return err
+	}
+
+	if lastBlockHash := qc.Block.GetBlockHeader().GetLastBlockHash(); lastBlockHash != m.lastBlockHash {
+		return typesCons.ErrInvalidLastBlockHash(lastBlockHash, m.lastBlockHash)
+	}
+
+	return nil
+}
+
+// Returns true if `qc1` was formed in a later view (i.e. height and round) than `qc2`.
+func isHigherView(qc1, qc2 *typesCons.QuorumCertificate) bool {
+	return qc1.Height > qc2.Height || (qc1.Height == qc2.Height && qc1.Round > qc2.Round)
 }
 
 func isSignatureValid(m *typesCons.HotstuffMessage, pubKeyBz []byte, signature []byte) bool {
@@ -116,14 +179,69 @@ func (m *consensusModule) didReceiveEnoughMessageForStep(step typesCons.Hotstuff
 	return m.isOptimisticThresholdMet(len(m.MessagePool[step]))
 }
 
+// A leader elected through VRF sortition may still be outranked by a NEWROUND message that is in flight, so the
+// first round of every height waits for the leader claims of validators controlling more than `ByzantineThreshold`
+// of the stake before proposing. Later rounds only require the regular NEWROUND quorum.
+// DISCUSS: A higher ranked claim from the remaining stake can still arrive late, in which case replicas reject the
+// proposal and the round times out.
+func (m *consensusModule) didReceiveEnoughLeaderClaims() error {
+	if m.consCfg.LeaderElection != config.VRFSortitionLeaderElection || m.Round > 0 {
+		return nil
+	}
+
+	claimants := make(map[string]struct{}, len(m.MessagePool[NewRound]))
+	for _, msg := range m.MessagePool[NewRound] {
+		if claim := msg.GetLeaderClaim(); claim != nil {
+			claimants[claim.Address] = struct{}{}
+		}
+	}
+
+	if err := m.isStakeThresholdMet(claimants); err != nil {
+		return typesCons.ErrMissingLeaderClaims(len(claimants), len(m.validatorMap), err)
+	}
+	return nil
+}
+
 func (m *consensusModule) isOptimisticThresholdMet(n int) error {
-	numValidators := len(m.validatorMap)
+	return isOptimisticThresholdMet(n, len(m.validatorMap))
+}
+
+func isOptimisticThresholdMet(n, numValidators int) error {
 	if !(float64(n) > ByzantineThreshold*float64(numValidators)) {
 		return typesCons.ErrByzantineThresholdCheck(n, ByzantineThreshold*float64(numValidators))
 	}
 	return nil
 }
 
+// Verifies that the validators in `signers` (keyed by hex encoded address) control more than
+// `ByzantineThreshold` of the total stake in the current validator set.
+func (m *consensusModule) isStakeThresholdMet(signers map[string]struct{}) error {
+	return isStakeThresholdMet(signers, m.getValidatorList())
+}
+
+func isStakeThresholdMet(signers map[string]struct{}, validators []*genesis.Validator) error {
+	signedStake, totalStake := big.NewInt(0), big.NewInt(0)
+	for _, validator := range validators {
+		address := hex.EncodeToString(validator.Address)
+		stake, err := types.StringToBigInt(validator.StakedTokens)
+		if err != nil {
+			return typesCons.ErrInvalidValidatorStake(address, validator.StakedTokens)
+		}
+		totalStake.Add(totalStake, stake)
+		if _, ok := signers[address]; ok {
+			signedStake.Add(signedStake, stake)
+		}
+	}
+
+	// Integer equivalent of `signedStake > ByzantineThreshold * totalStake` to avoid floating point precision issues
+	lhs := new(big.Int).Mul(signedStake, big.NewInt(3))
+	rhs := new(big.Int).Mul(totalStake, big.NewInt(2))
+	if lhs.Cmp(rhs) <= 0 {
+		return typesCons.ErrByzantineStakeThresholdCheck(signedStake.String(), totalStake.String())
+	}
+	return nil
+}
+
 func protoHash(m proto.Message) string {
 	b, err := proto.Marshal(m)
 	if err != nil {
@@ -141,14 +259,28 @@ func (m *consensusModule) sendToNode(msg *typesCons.HotstuffMessage) {
 		return
 	}
 
-	m.nodeLog(typesCons.SendingMessage(msg, *m.LeaderId))
+	m.sendToNodeId(msg, *m.LeaderId)
+}
+
+func (m *consensusModule) sendToNodeId(msg *typesCons.HotstuffMessage, nodeId typesCons.NodeId) {
+	m.nodeLog(typesCons.SendingMessage(msg, nodeId))
 	anyConsensusMessage, err := anypb.New(msg)
 	if err != nil {
 		m.nodeLogError(typesCons.ErrCreateConsensusMessage.Error(), err)
 		return
 	}
 
-	if err := m.GetBus().GetP2PModule().Send(cryptoPocket.AddressFromString(m.IdToValAddrMap[*m.LeaderId]), anyConsensusMessage, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC); err != nil {
+	var vote *typesCons.HotstuffMessage
+	if msg.Type == Vote {
+		vote = msg
+	}
+	if err := m.writeToWAL(vote); err != nil {
+		m.nodeLogError(typesCons.ErrWriteWAL.Error(), err)
+		return
+	}
+
+	m.traceOutbound(m.IdToValAddrMap[nodeId], anyConsensusMessage)
+	if err := m.GetBus().GetP2PModule().Send(cryptoPocket.AddressFromString(m.IdToValAddrMap[nodeId]), anyConsensusMessage, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC); err != nil {
 		m.nodeLogError(typesCons.ErrSendMessage.Error(), err)
 		return
 	}
@@ -162,20 +294,56 @@ func (m *consensusModule) broadcastToNodes(msg *typesCons.HotstuffMessage) {
 		return
 	}
 
+	if err := m.writeToWAL(nil); err != nil {
+		m.nodeLogError(typesCons.ErrWriteWAL.Error(), err)
+		return
+	}
+
+	m.traceOutbound("", anyConsensusMessage)
 	if err := m.GetBus().GetP2PModule().Broadcast(anyConsensusMessage, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC); err != nil {
 		m.nodeLogError(typesCons.ErrBroadcastMessage.Error(), err)
 		return
 	}
 }
 
+// The state the node timed out in is recorded by the pacemaker before the timeout is created. Resending or omitting
+// a timeout after a crash cannot conflict with anything the node already signed.
+func (m *consensusModule) broadcastTimeout(msg *typesCons.TimeoutMessage) {
+	m.nodeLog(typesCons.BroadcastingTimeout(msg))
+	anyTimeoutMessage, err := anypb.New(msg)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrCreateConsensusMessage.Error(), err)
+		return
+	}
+
+	m.traceOutbound("", anyTimeoutMessage)
+	if err := m.GetBus().GetP2PModule().Broadcast(anyTimeoutMessage, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC); err != nil {
+		m.nodeLogError(typesCons.ErrBroadcastMessage.Error(), err)
+		return
+	}
+}
+
 /*** Persistence Helpers ***/
 
 func (m *consensusModule) clearMessagesPool() {
 	for _, step := range HotstuffSteps {
-		m.MessagePool[step] = make([]*typesCons.HotstuffMessage, 0)
+		m.clearMessagesPoolForStep(step)
 	}
 }
 
+func (m *consensusModule) clearMessagesPoolForStep(step typesCons.HotstuffStep) {
+	m.MessagePool[step] = make([]*typesCons.HotstuffMessage, 0)
+	m.messagePoolBytes[step] = 0
+}
+
+// Returns the total serialized size of the messages in the pool.
+func (m *consensusModule) getMessagePoolBytes() (poolBytes uint64) {
+	for _, stepBytes := range m.messagePoolBytes {
+		poolBytes += stepBytes
+	}
+	return
+}
+
 /*** Leader Election Helpers ***/
 
 func (m *consensusModule) isLeader() bool {
@@ -186,6 +354,12 @@ func (m *consensusModule) isReplica() bool {
 	return !m.isLeader()
 }
 
+// Returns true if the message carries the leader claim of a validator other than this node.
+func (m *consensusModule) isOtherLeaderClaim(msg *typesCons.HotstuffMessage) bool {
+	claim := msg.GetLeaderClaim()
+	return claim != nil && claim.Address != m.privateKey.Address().String()
+}
+
 func (m *consensusModule) clearLeader() {
 	m.logPrefix = DefaultLogPrefix
 	m.LeaderId = nil
@@ -199,6 +373,11 @@ func (m *consensusModule) electNextLeader(message *typesCons.HotstuffMessage) {
 		return
 	}
 
+	// NEWROUND messages carrying leader claims may re-elect the same leader several times in a single view.
+	if m.LeaderId != nil && *m.LeaderId == leaderId {
+		return
+	}
+
 	m.LeaderId = &leaderId
 
 	if m.LeaderId != nil && *m.LeaderId == m.NodeId {
@@ -208,6 +387,7 @@ func (m *consensusModule) electNextLeader(message *typesCons.HotstuffMessage) {
 		m.logPrefix = "REPLICA"
 		m.nodeLog(typesCons.ElectedNewLeader(m.IdToValAddrMap[*m.LeaderId], *m.LeaderId, m.Height, m.Round))
 	}
+	m.publishLeaderElected()
 }
 
 /*** General Infrastructure Helpers ***/
@@ -0,0 +1,591 @@
+package consensus
+
+import (
+	"encoding/hex"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/config"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+/*
+In chained mode, every view only runs the PREPARE step of basic HotStuff. Replicas send their votes for a block to
+the leader of the next height, which aggregates them into a QC and immediately proposes the next block justified
+by it. The QC of a block therefore doubles as the PRECOMMIT QC of its parent and the COMMIT QC of its grandparent:
+  - the QC of the newest certified block (i.e. the generic QC) is extended by the next proposal (i.e. HighPrepareQC)
+  - the QC of its parent is the one replicas lock on (i.e. LockedQC)
+  - its grandparent is committed (i.e. the three-chain rule)
+
+Since a block is only committed two heights after it is proposed, its transactions are applied once it is committed
+and the app hash in its header is the one after the last block committed by its proposer.
+
+`Height`, `Round` and `Step` track the view the node votes in, which is ahead of the last committed block. `Step` is
+NEWROUND until the node votes in the view and PRECOMMIT afterwards.
+
+Votes go through the same sign guard as in basic mode, so the (height, round) of the last vote is persisted in the
+root directory and a restarted validator never votes for a conflicting block in a view it already voted in. The
+WAL is not used though, so it rejoins the network from its last committed height and does not vote again until it
+reaches a view past its last vote.
+
+TODO(design): Block sync and crash recovery of the uncommitted blocks are not supported in chained mode yet.
+*/
+
+func (m *consensusModule) handleChainedHotstuffMessage(msg *typesCons.HotstuffMessage) {
+	switch {
+	case msg.Step == NewRound:
+		m.handleChainedNewRoundMessage(msg)
+	case msg.Step == Prepare && msg.Type == Propose:
+		m.handleChainedProposal(msg)
+	case msg.Step == Prepare && msg.Type == Vote:
+		m.handleChainedVote(msg)
+	default:
+		m.nodeLog(typesCons.WarnDiscardHotstuffMessage(msg, typesCons.ErrUnexpectedChainedMessage.Error()))
+	}
+}
+
+// NEWROUND messages are only exchanged at the first height and after a round times out, when the leader of the new
+// view cannot rely on the QC of the previous view being formed by itself.
+func (m *consensusModule) handleChainedNewRoundMessage(msg *typesCons.HotstuffMessage) {
+	if err := m.validateChainedView(msg); err != nil {
+		m.nodeLog(typesCons.WarnDiscardHotstuffMessage(msg, err.Error()))
+		return
+	}
+
+	if !m.isLeader() || m.Step != NewRound {
+		return
+	}
+
+	m.aggregateMessage(msg)
+	if err := m.didReceiveEnoughMessageForStep(NewRound); err != nil {
+		m.nodeLog(typesCons.OptimisticVoteCountWaiting(NewRound, err.Error()))
+		return
+	}
+	m.nodeLog(typesCons.OptimisticVoteCountPassed(NewRound))
+
+	// The leader extends the highest block certified by any of the validators it heard from.
+	height := m.Height
+	for _, newRoundMsg := range m.MessagePool[NewRound] {
+		qc := newRoundMsg.GetQuorumCertificate()
+		if qc == nil {
+			continue
+		}
+		if err := m.validateGenericQC(qc); err != nil {
+			m.nodeLog(typesCons.WarnInvalidHighQC(err.Error()))
+			continue
+		}
+		m.processGenericQC(qc)
+	}
+
+	// The node learnt about a QC for the block of the current height, so the view it was leading is obsolete.
+	if m.Height != height {
+		return
+	}
+
+	m.proposeChainedBlock()
+}
+
+func (m *consensusModule) handleChainedProposal(msg *typesCons.HotstuffMessage) {
+	if err := m.validateChainedProposal(msg); err != nil {
+		m.nodeLog(typesCons.WarnDiscardHotstuffMessage(msg, err.Error()))
+		return
+	}
+
+	m.pendingBlocks[msg.Block.BlockHeader.Hash] = msg.Block
+	m.Block = msg.Block
+	m.paceMaker.RestartTimer()
+
+	m.voteForChainedBlock()
+}
+
+// Votes are aggregated by the leader of the next height, which proposes the next block as soon as they form a QC.
+func (m *consensusModule) handleChainedVote(msg *typesCons.HotstuffMessage) {
+	if msg.Height != m.Height {
+		m.nodeLog(typesCons.WarnDiscardHotstuffMessage(msg, typesCons.ErrPacemakerUnexpectedMessageHeight(typesCons.ErrOlderMessage, m.Height, msg.Height).Error()))
+		return
+	}
+
+	if msg.Round != m.Round {
+		m.nodeLog(typesCons.WarnDiscardHotstuffMessage(msg, typesCons.ErrPacemakerUnexpectedMessageStepRound(typesCons.ErrOlderStepRound, m.Step, m.Round, msg).Error()))
+		return
+	}
+
+	nextLeaderId, err := m.getChainedLeader(m.Height+1, 0)
+	if err != nil || nextLeaderId != m.NodeId {
+		m.nodeLog(typesCons.WarnDiscardHotstuffMessage(msg, typesCons.ErrNotNextLeader.Error()))
+		return
+	}
+
+	if err := m.validatePartialSignature(msg); err != nil {
+		m.nodeLogError(typesCons.ErrHotstuffValidation.Error(), err)
+		return
+	}
+	m.aggregateMessage(msg)
+
+	// Votes may arrive before the proposal itself, but the QC can only be formed for the block this node voted for.
+	if m.Step != PreCommit {
+		return
+	}
+
+	if err := m.didReceiveEnoughMessageForStep(Prepare); err != nil {
+		m.nodeLog(typesCons.OptimisticVoteCountWaiting(Prepare, err.Error()))
+		return
+	}
+
+	qc, err := m.getQuorumCertificate(m.Height, Prepare, m.Round)
+	if err != nil {
+		m.nodeLog(typesCons.OptimisticVoteCountWaiting(Prepare, err.Error()))
+		return
+	}
+	m.nodeLog(typesCons.OptimisticVoteCountPassed(Prepare))
+
+	m.processGenericQC(qc)
+	if m.isLeader() {
+		m.proposeChainedBlock()
+	}
+}
+
+// Proposes a block that extends the block certified by the HighPrepareQC of the leader and votes for it.
+func (m *consensusModule) proposeChainedBlock() {
+	block, err := m.prepareChainedBlock(m.HighPrepareQC)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrPrepareBlock.Error(), err)
+		m.paceMaker.InterruptRound()
+		return
+	}
+
+	m.pendingBlocks[block.BlockHeader.Hash] = block
+	m.Block = block
+	m.clearMessagesPoolForStep(NewRound)
+	m.paceMaker.RestartTimer()
+
+	prepareProposeMessage, err := CreateProposeMessage(m, Prepare, m.HighPrepareQC)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrCreateProposeMessage(Prepare).Error(), err)
+		m.paceMaker.InterruptRound()
+		return
+	}
+	m.broadcastToNodes(prepareProposeMessage)
+
+	// Leader also acts like a replica
+	m.voteForChainedBlock()
+}
+
+func (m *consensusModule) voteForChainedBlock() {
+	m.Step = PreCommit
+
+	prepareVoteMessage, err := CreateVoteMessage(m, Prepare, m.Block)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrCreateVoteMessage(Prepare).Error(), err)
+		return // TODO(olshansky): Should we interrupt the round here?
+	}
+	m.sendChainedVote(prepareVoteMessage)
+}
+
+func (m *consensusModule) sendChainedVote(vote *typesCons.HotstuffMessage) {
+	nextLeaderId, err := m.getChainedLeader(vote.Height+1, 0)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrLeaderElection(vote).Error(), err)
+		return
+	}
+	m.sendToNodeId(vote, nextLeaderId)
+}
+
+func (m *consensusModule) validateChainedProposal(msg *typesCons.HotstuffMessage) error {
+	if msg.Block == nil {
+		return typesCons.ErrNilBlock
+	}
+
+	if msg.Block.BlockHeader == nil {
+		return typesCons.ErrNilBlockHeader
+	}
+
+	// The QC justifying the proposal certifies its parent. It is processed first since it proves the rest of the
+	// network moved on to the height of the proposal even if this node missed the QC itself.
+	justifyQC := msg.GetQuorumCertificate()
+	if justifyQC != nil {
+		if err := m.validateGenericQC(justifyQC); err != nil {
+			return err
+		}
+		m.processGenericQC(justifyQC)
+	}
+
+	if err := m.validateChainedView(msg); err != nil {
+		return err
+	}
+
+	if m.isLeader() {
+		return typesCons.ErrSelfProposal
+	}
+
+	if m.Step != NewRound {
+		return typesCons.ErrAlreadyVoted
+	}
+
+	if m.LeaderId == nil {
+		return typesCons.ErrNilLeaderId
+	}
+
+	if proposer := hex.EncodeToString(msg.Block.BlockHeader.ProposerAddress); proposer != m.IdToValAddrMap[*m.LeaderId] {
+		return typesCons.ErrInvalidProposer(proposer, m.IdToValAddrMap[*m.LeaderId])
+	}
+
+	if err := m.validateChainedBlock(msg.Block, justifyQC); err != nil {
+		return err
+	}
+
+	return m.isSafeNode(msg.Block, justifyQC)
+}
+
+// Unlike `paceMaker.ValidateMessage`, messages are never from a later step since every view only has one step.
+// The leader of the view is elected as soon as the node is in it since it is known ahead of time.
+func (m *consensusModule) validateChainedView(msg *typesCons.HotstuffMessage) error {
+	if msg.Height < m.Height {
+		return typesCons.ErrPacemakerUnexpectedMessageHeight(typesCons.ErrOlderMessage, m.Height, msg.Height)
+	}
+
+	// DISCUSS: A node can only move to a later height by processing the QC of the block at its current height.
+	if msg.Height > m.Height {
+		return typesCons.ErrPacemakerUnexpectedMessageHeight(typesCons.ErrFutureMessage, m.Height, msg.Height)
+	}
+
+	if msg.Round < m.Round {
+		return typesCons.ErrPacemakerUnexpectedMessageStepRound(typesCons.ErrOlderStepRound, m.Step, m.Round, msg)
+	}
+
+	if msg.Round > m.Round {
+		if err := m.validateTimeoutQuorumCertificate(msg.TimeoutQc, msg.Height, msg.Round-1); err != nil {
+			return err
+		}
+		m.nodeLog(typesCons.PacemakerCatchup(m.Height, uint64(m.Step), m.Round, msg.Height, uint64(NewRound), msg.Round))
+		m.Round = msg.Round
+		m.Step = NewRound
+		m.TimeoutQC = msg.TimeoutQc
+		m.clearLeader()
+		m.clearMessagesPool()
+	}
+
+	if m.LeaderId == nil {
+		m.electChainedLeader()
+	}
+
+	return nil
+}
+
+// Validates that the block header commits to the contents of the block and that the block is the child of the
+// block certified by `justifyQC`, or of the last committed block if the QC is nil.
+func (m *consensusModule) validateChainedBlock(block *types.Block, justifyQC *typesCons.QuorumCertificate) error {
+	header := block.BlockHeader
+
+	if blockSize := uint64(proto.Size(block)); blockSize > m.consCfg.MaxBlockBytes {
+		return typesCons.ErrInvalidBlockSize(blockSize, m.consCfg.MaxBlockBytes)
+	}
+
+	parentHeight, parentHash := m.lastCommitQC.GetHeight(), m.lastBlockHash
+	if justifyQC != nil {
+		parentHeight, parentHash = justifyQC.Height, justifyQC.BlockHash
+	}
+
+	if header.Height != int64(m.Height) || header.Height != int64(parentHeight+1) {
+		return typesCons.ErrInvalidBlockHeight(header.Height, parentHeight+1)
+	}
+
+	blockHash, err := header.ComputeHash()
+	if err != nil {
+		return err
+	}
+	if header.Hash != blockHash {
+		return typesCons.ErrInvalidBlockHash(header.Hash, blockHash)
+	}
+
+	if header.NumTxs != uint32(len(block.Transactions)) || header.TransactionsRoot != types.TransactionsRoot(block.Transactions) {
+		return typesCons.ErrInvalidTransactionsRoot
+	}
+
+	if header.LastBlockHash != parentHash {
+		return typesCons.ErrInvalidLastBlockHash(header.LastBlockHash, parentHash)
+	}
+
+	validatorsHash, err := m.getValidatorSetHash()
+	if err != nil {
+		return err
+	}
+	if header.ValidatorsHash != validatorsHash {
+		return typesCons.ErrInvalidValidatorsHash(header.ValidatorsHash, validatorsHash)
+	}
+
+	headerQC, err := bytesToQC(header.QuorumCertificate)
+	if err != nil {
+		return err
+	}
+	if !proto.Equal(headerQC, stripBlockFromQC(justifyQC)) {
+		return typesCons.ErrInvalidJustifyQC
+	}
+
+	if header.AppHash != m.appHash {
+		return typesCons.ErrInvalidAppHash(header.AppHash, m.appHash)
+	}
+
+	pendingTxs := m.getPendingTransactions(parentHash)
+	for _, tx := range block.Transactions {
+		if _, ok := pendingTxs[string(tx)]; ok {
+			return typesCons.ErrDuplicatePendingTransaction
+		}
+	}
+
+	return nil
+}
+
+// A generic QC certifies a block proposed in the PREPARE step of any view.
+func (m *consensusModule) validateGenericQC(qc *typesCons.QuorumCertificate) error {
+	if qc.Step != Prepare {
+		return typesCons.ErrQCStepMismatch(Prepare, qc.Step)
+	}
+
+	if err := m.validateQuorumCertificate(qc); err != nil {
+		return err
+	}
+
+	if height := qc.Block.GetBlockHeader().GetHeight(); height != int64(qc.Height) {
+		return typesCons.ErrInvalidBlockHeight(height, qc.Height)
+	}
+
+	return nil
+}
+
+// Updates the HighPrepareQC and LockedQC of the node with a valid generic QC, commits the blocks it finalizes and
+// moves the node to the next height if `qc` certifies the block of its current height.
+func (m *consensusModule) processGenericQC(qc *typesCons.QuorumCertificate) {
+	if m.HighPrepareQC != nil && !isHigherView(qc, m.HighPrepareQC) {
+		return
+	}
+
+	m.pendingBlocks[qc.BlockHash] = qc.Block
+	m.HighPrepareQC = qc
+
+	parentQC, err := bytesToQC(qc.Block.BlockHeader.QuorumCertificate)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrQCInvalid(Prepare).Error(), err)
+	}
+
+	if parentQC != nil {
+		if m.LockedQC == nil || isHigherView(parentQC, m.LockedQC) {
+			m.LockedQC = parentQC
+		}
+
+		if parent, ok := m.pendingBlocks[parentQC.BlockHash]; ok {
+			grandparentQC, err := bytesToQC(parent.BlockHeader.QuorumCertificate)
+			if err != nil {
+				m.nodeLogError(typesCons.ErrQCInvalid(Prepare).Error(), err)
+			}
+			if grandparentQC != nil {
+				if err := m.commitChainedBlocks(grandparentQC); err != nil {
+					m.nodeLogError(typesCons.ErrCommitBlock.Error(), err)
+				}
+			}
+		}
+	}
+
+	if qc.Height >= m.Height {
+		m.enterChainedHeight(qc.Height + 1)
+	}
+}
+
+// Commits the block certified by `commitQC` along with any of its ancestors that are not committed yet.
+func (m *consensusModule) commitChainedBlocks(commitQC *typesCons.QuorumCertificate) error {
+	lastCommittedHeight := m.lastCommitQC.GetHeight()
+	if commitQC.Height <= lastCommittedHeight {
+		return nil
+	}
+
+	// The blocks are collected from the newest to the oldest, but committed in the opposite order.
+	blocks := make([]*types.Block, 0, commitQC.Height-lastCommittedHeight)
+	blockHash := commitQC.BlockHash
+	for height := commitQC.Height; height > lastCommittedHeight; height-- {
+		block, ok := m.pendingBlocks[blockHash]
+		if !ok {
+			return typesCons.ErrMissingPendingBlock(height)
+		}
+		blocks = append(blocks, block)
+		blockHash = block.BlockHeader.LastBlockHash
+	}
+	if blockHash != m.lastBlockHash {
+		return typesCons.ErrInvalidLastBlockHash(blockHash, m.lastBlockHash)
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		// Every block is certified by the QC in the header of its child, apart from the last one.
+		qc := commitQC
+		if i > 0 {
+			childQC, err := bytesToQC(blocks[i-1].BlockHeader.QuorumCertificate)
+			if err != nil {
+				return err
+			}
+			qc = childQC
+		}
+		if err := m.commitChainedBlock(blocks[i], qc); err != nil {
+			return err
+		}
+	}
+
+	// Blocks from forks that can no longer be committed are discarded.
+	for hash, block := range m.pendingBlocks {
+		if block.BlockHeader.Height <= int64(commitQC.Height) {
+			delete(m.pendingBlocks, hash)
+		}
+	}
+
+	return nil
+}
+
+func (m *consensusModule) commitChainedBlock(block *types.Block, commitQC *typesCons.QuorumCertificate) error {
+	height := block.BlockHeader.Height
+	if err := m.updateUtilityContextAtHeight(height); err != nil {
+		return err
+	}
+
+	appHash, validatorUpdates, err := m.utilityContext.ApplyBlock(height, block.BlockHeader.ProposerAddress, block.Transactions, m.lastMissedValidators)
+	if err != nil {
+		return err
+	}
+	m.validatorUpdates = validatorUpdates
+
+	if err := m.commitBlock(block, commitQC); err != nil {
+		return err
+	}
+
+	// The header of the block carries the app hash prior to applying it, which the next proposal must not reuse.
+	m.appHash = hex.EncodeToString(appHash)
+
+	return nil
+}
+
+func (m *consensusModule) enterChainedHeight(height uint64) {
+	m.nodeLog(typesCons.PacemakerNewHeight(height))
+
+	m.Height = height
+	m.Round = 0
+	m.Step = NewRound
+	m.Block = nil
+
+	m.TimeoutQC = nil
+	m.clearTimeoutPool()
+	m.clearMessagesPool()
+
+	m.clearLeader()
+	m.electChainedLeader()
+
+	m.paceMaker.RestartTimer()
+}
+
+// This is a helper function intended to be called by the leader of the current view. Transactions are only applied
+// once the block is committed, so the utility context is solely used to reap the mempool.
+func (m *consensusModule) prepareChainedBlock(parentQC *typesCons.QuorumCertificate) (*types.Block, error) {
+	if m.isReplica() {
+		return nil, typesCons.ErrReplicaPrepareBlock
+	}
+	if block := m.getReplayedProposal(); block != nil {
+		return block, nil
+	}
+
+	parentHash := m.lastBlockHash
+	if parentQC != nil {
+		parentHash = parentQC.BlockHash
+	}
+
+	parentQCBz, err := qcToBytes(stripBlockFromQC(parentQC))
+	if err != nil {
+		return nil, err
+	}
+
+	validatorsHash, err := m.getValidatorSetHash()
+	if err != nil {
+		return nil, err
+	}
+
+	blockHeader := &types.BlockHeader{
+		Height:            int64(m.Height),
+		Time:              timestamppb.Now(),
+		LastBlockHash:     parentHash,
+		ProposerAddress:   m.privateKey.Address(),
+		QuorumCertificate: parentQCBz,
+		AppHash:           m.appHash,
+		ValidatorsHash:    validatorsHash,
+	}
+
+	maxTxBytes, err := m.getMaxTransactionBytes(blockHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.updateUtilityContext(); err != nil {
+		return nil, err
+	}
+	mempoolTxs, err := m.utilityContext.GetTransactionsForProposal(m.privateKey.Address(), maxTxBytes, m.lastMissedValidators)
+	m.utilityContext.ReleaseContext()
+	m.utilityContext = nil
+	if err != nil {
+		return nil, err
+	}
+
+	// The transactions of the uncommitted ancestors are still in the mempool until those blocks are committed.
+	pendingTxs := m.getPendingTransactions(parentHash)
+	txs := make([][]byte, 0, len(mempoolTxs))
+	for _, tx := range mempoolTxs {
+		if _, ok := pendingTxs[string(tx)]; !ok {
+			txs = append(txs, tx)
+		}
+	}
+
+	blockHeader.NumTxs = uint32(len(txs))
+	blockHeader.TransactionsRoot = types.TransactionsRoot(txs)
+	blockHeader.Hash, err = blockHeader.ComputeHash()
+	if err != nil {
+		return nil, err
+	}
+
+	block := &types.Block{
+		BlockHeader:  blockHeader,
+		Transactions: txs,
+	}
+
+	if blockSize := uint64(proto.Size(block)); blockSize > m.consCfg.MaxBlockBytes {
+		return nil, typesCons.ErrInvalidBlockSize(blockSize, m.consCfg.MaxBlockBytes)
+	}
+
+	return block, nil
+}
+
+// Returns the transactions in the block with hash `blockHash` and in its uncommitted ancestors.
+func (m *consensusModule) getPendingTransactions(blockHash string) map[string]struct{} {
+	txs := make(map[string]struct{})
+	for block, ok := m.pendingBlocks[blockHash]; ok; block, ok = m.pendingBlocks[block.BlockHeader.LastBlockHash] {
+		for _, tx := range block.Transactions {
+			txs[string(tx)] = struct{}{}
+		}
+	}
+	return txs
+}
+
+// The leader of every view is known ahead of time in chained mode, so the votes of the current height can be sent
+// to the leader of the next one.
+func (m *consensusModule) getChainedLeader(height, round uint64) (typesCons.NodeId, error) {
+	return m.leaderElectionMod.ElectNextLeader(chainedView(height, round))
+}
+
+func (m *consensusModule) electChainedLeader() {
+	m.electNextLeader(chainedView(m.Height, m.Round))
+}
+
+func chainedView(height, round uint64) *typesCons.HotstuffMessage {
+	return &typesCons.HotstuffMessage{
+		Height: height,
+		Round:  round,
+		Step:   NewRound,
+	}
+}
+
+func (m *consensusModule) isChainedHotstuff() bool {
+	return m.consCfg.HotstuffMode == config.ChainedHotstuff
+}
@@ -2,9 +2,9 @@ package consensus
 
 import (
 	"encoding/hex"
-	"unsafe"
 
 	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -32,6 +32,10 @@ func (handler *HotstuffLeaderMessageHandler) HandleNewRoundMessage(m *consensusM
 		m.nodeLog(typesCons.OptimisticVoteCountWaiting(NewRound, err.Error()))
 		return
 	}
+	if err := m.didReceiveEnoughLeaderClaims(); err != nil {
+		m.nodeLog(typesCons.OptimisticVoteCountWaiting(NewRound, err.Error()))
+		return
+	}
 
 	// TODO(olshansky): Do we need to pause for `MinBlockFreqMSec` here to let more transactions come in?
 	m.nodeLog(typesCons.OptimisticVoteCountPassed(NewRound))
@@ -39,8 +43,7 @@ func (handler *HotstuffLeaderMessageHandler) HandleNewRoundMessage(m *consensusM
 	// Likely to be `nil` if blockchain is progressing well.
 	highPrepareQC := m.findHighQC(NewRound)
 
-	// TODO(olshansky): Add more unit tests for these checks...
-	if highPrepareQC == nil || highPrepareQC.Height < m.Height || highPrepareQC.Round < m.Round {
+	if highPrepareQC == nil {
 		block, err := m.prepareBlock()
 		if err != nil {
 			m.nodeLogError(typesCons.ErrPrepareBlock.Error(), err)
@@ -49,12 +52,18 @@ func (handler *HotstuffLeaderMessageHandler) HandleNewRoundMessage(m *consensusM
 		}
 		m.Block = block
 	} else {
-		// TODO(discuss): Do we need to validate highPrepareQC here?
+		// Replicas may be locked on the block certified by the highest QC, so it is proposed again instead of
+		// a new block that they would reject.
+		if err := m.executeBlock(highPrepareQC.Block); err != nil {
+			m.nodeLogError(typesCons.ErrApplyBlock.Error(), err)
+			m.paceMaker.InterruptRound()
+			return
+		}
 		m.Block = highPrepareQC.Block
 	}
 
 	m.Step = Prepare
-	m.MessagePool[NewRound] = nil
+	m.clearMessagesPoolForStep(NewRound)
 	m.paceMaker.RestartTimer()
 
 	prepareProposeMessage, err := CreateProposeMessage(m, Prepare, highPrepareQC)
@@ -96,7 +105,8 @@ func (handler *HotstuffLeaderMessageHandler) HandlePrepareMessage(m *consensusMo
 
 	m.Step = PreCommit
 	m.HighPrepareQC = prepareQC
-	m.MessagePool[Prepare] = nil
+	m.writeStateToWAL()
+	m.clearMessagesPoolForStep(Prepare)
 	m.paceMaker.RestartTimer()
 
 	precommitProposeMessages, err := CreateProposeMessage(m, PreCommit, prepareQC)
@@ -138,7 +148,8 @@ func (handler *HotstuffLeaderMessageHandler) HandlePrecommitMessage(m *consensus
 
 	m.Step = Commit
 	m.LockedQC = preCommitQC
-	m.MessagePool[PreCommit] = nil
+	m.writeStateToWAL()
+	m.clearMessagesPoolForStep(PreCommit)
 	m.paceMaker.RestartTimer()
 
 	commitProposeMessage, err := CreateProposeMessage(m, Commit, preCommitQC)
@@ -179,7 +190,7 @@ func (handler *HotstuffLeaderMessageHandler) HandleCommitMessage(m *consensusMod
 	}
 
 	m.Step = Decide
-	m.MessagePool[Commit] = nil
+	m.clearMessagesPoolForStep(Commit)
 	m.paceMaker.RestartTimer()
 
 	decideProposeMessage, err := CreateProposeMessage(m, Decide, commitQC)
@@ -190,7 +201,7 @@ func (handler *HotstuffLeaderMessageHandler) HandleCommitMessage(m *consensusMod
 	}
 	m.broadcastToNodes(decideProposeMessage)
 
-	if err := m.commitBlock(m.Block); err != nil {
+	if err := m.commitBlock(m.Block, commitQC); err != nil {
 		m.nodeLogError(typesCons.ErrCommitBlock.Error(), err)
 		m.paceMaker.InterruptRound()
 		return
@@ -260,14 +271,49 @@ func (m *consensusModule) validatePartialSignature(msg *typesCons.HotstuffMessag
 }
 
 func (m *consensusModule) aggregateMessage(msg *typesCons.HotstuffMessage) {
-	// TODO(olshansky): Add proper tests for this when we figure out where the mempool should live.
-	// NOTE: This is just a placeholder at the moment. It doesn't actually work because SizeOf returns
-	// the size of the map pointer, and does not recursively determine the size of all the underlying elements.
-	if m.consCfg.MaxMempoolBytes < uint64(unsafe.Sizeof(m.MessagePool)) {
+	// An equivocating vote is reported rather than aggregated so it cannot count towards a QC.
+	if conflictingVote := m.findConflictingVote(msg); conflictingVote != nil {
+		if err := m.submitDoubleSignEvidence(conflictingVote, msg); err != nil {
+			m.nodeLogError(typesCons.ErrSubmitDoubleSignEvidence.Error(), err)
+		}
+		return
+	}
+
+	// A validator only contributes one message per view and step, so resending it cannot fill the pool.
+	if m.isDuplicateMessage(msg) {
+		m.nodeLog(typesCons.WarnDuplicateMessageInPool(msg))
+		return
+	}
+
+	msgBytes := uint64(proto.Size(msg))
+	if m.getMessagePoolBytes()+msgBytes > m.consCfg.MaxMempoolBytes {
 		m.nodeLogError(typesCons.DisregardHotstuffMessage, typesCons.ErrConsensusMempoolFull)
 		return
 	}
 
 	// Only the leader needs to aggregate consensus related messages.
 	m.MessagePool[msg.Step] = append(m.MessagePool[msg.Step], msg)
+	m.messagePoolBytes[msg.Step] += msgBytes
+}
+
+// Returns true if the pool already holds a message from the same validator for the same height, round and step.
+// Votes are identified by their partial signature and NEWROUND messages by their leader claim, if they carry one.
+func (m *consensusModule) isDuplicateMessage(msg *typesCons.HotstuffMessage) bool {
+	signer := getMessageSigner(msg)
+	if signer == "" {
+		return false
+	}
+	for _, poolMsg := range m.MessagePool[msg.Step] {
+		if getMessageSigner(poolMsg) == signer && poolMsg.Height == msg.Height && poolMsg.Round == msg.Round {
+			return true
+		}
+	}
+	return false
+}
+
+func getMessageSigner(msg *typesCons.HotstuffMessage) string {
+	if partialSig := msg.GetPartialSignature(); partialSig != nil {
+		return partialSig.Address
+	}
+	return msg.GetLeaderClaim().GetAddress()
 }
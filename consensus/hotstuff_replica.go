@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/types"
 )
 
 type HotstuffReplicaMessageHandler struct{}
@@ -38,13 +39,17 @@ func (handler *HotstuffReplicaMessageHandler) HandlePrepareMessage(m *consensusM
 		m.nodeLogError(typesCons.ErrHotstuffValidation.Error(), err)
 		return
 	}
-	// TODO(olshansky): add step specific validation
 	if err := m.validateProposal(msg); err != nil {
 		m.nodeLogError(fmt.Sprintf("Invalid proposal in %s message", Prepare), err)
 		m.paceMaker.InterruptRound()
 		return
 	}
 
+	// The proposer's leader claim was validated above and may outrank the leader elected from NEWROUND messages.
+	if msg.GetLeaderClaim() != nil {
+		m.electNextLeader(msg)
+	}
+
 	if err := m.applyBlock(msg.Block); err != nil {
 		m.nodeLogError(typesCons.ErrApplyBlock.Error(), err)
 		m.paceMaker.InterruptRound()
@@ -52,6 +57,7 @@ func (handler *HotstuffReplicaMessageHandler) HandlePrepareMessage(m *consensusM
 	}
 
 	m.Step = PreCommit
+	m.Block = msg.Block
 	m.paceMaker.RestartTimer()
 
 	prepareVoteMessage, err := CreateVoteMessage(m, Prepare, msg.Block)
@@ -70,14 +76,15 @@ func (handler *HotstuffReplicaMessageHandler) HandlePrecommitMessage(m *consensu
 		return
 	}
 	// TODO(olshansky): add step specific validation
-	if err := m.validateQuorumCertificate(msg.GetQuorumCertificate()); err != nil {
+	if err := m.validateJustifiedProposal(msg); err != nil {
 		m.nodeLogError(typesCons.ErrQCInvalid(PreCommit).Error(), err)
 		m.paceMaker.InterruptRound()
 		return
 	}
 
 	m.Step = Commit
-	m.HighPrepareQC = msg.GetQuorumCertificate() // TODO(discuss): Why are we never using this for validation?
+	m.HighPrepareQC = msg.GetQuorumCertificate() // Sent to the next leader in NEWROUND messages; see `findHighQC`
+	m.writeStateToWAL()
 	m.paceMaker.RestartTimer()
 
 	preCommitVoteMessage, err := CreateVoteMessage(m, PreCommit, msg.Block)
@@ -96,7 +103,7 @@ func (handler *HotstuffReplicaMessageHandler) HandleCommitMessage(m *consensusMo
 		return
 	}
 	// TODO(olshansky): add step specific validation
-	if err := m.validateQuorumCertificate(msg.GetQuorumCertificate()); err != nil {
+	if err := m.validateJustifiedProposal(msg); err != nil {
 		m.nodeLogError(typesCons.ErrQCInvalid(Commit).Error(), err)
 		m.paceMaker.InterruptRound()
 		return
@@ -104,6 +111,7 @@ func (handler *HotstuffReplicaMessageHandler) HandleCommitMessage(m *consensusMo
 
 	m.Step = Decide
 	m.LockedQC = msg.GetQuorumCertificate() // TODO(discuss): How do the replica recover if it's locked? Replica `formally` agrees on the QC while the rest of the network `verbally` agrees on the QC.
+	m.writeStateToWAL()
 	m.paceMaker.RestartTimer()
 
 	commitVoteMessage, err := CreateVoteMessage(m, Commit, msg.Block)
@@ -122,13 +130,13 @@ func (handler *HotstuffReplicaMessageHandler) HandleDecideMessage(m *consensusMo
 		return
 	}
 	// TODO(olshansky): add step specific validation
-	if err := m.validateQuorumCertificate(msg.GetQuorumCertificate()); err != nil {
+	if err := m.validateJustifiedProposal(msg); err != nil {
 		m.nodeLogError(typesCons.ErrQCInvalid(Decide).Error(), err)
 		m.paceMaker.InterruptRound()
 		return
 	}
 
-	if err := m.commitBlock(msg.Block); err != nil {
+	if err := m.commitBlock(msg.Block, msg.GetQuorumCertificate()); err != nil {
 		m.nodeLogError("Could not commit block: %v", err)
 		m.paceMaker.InterruptRound()
 		return
@@ -151,36 +159,70 @@ func (m *consensusModule) validateProposal(msg *typesCons.HotstuffMessage) error
 		return err
 	}
 
-	// TODO(discuss): A nil QC implies a successfull CommitQC or TimeoutQC, which have been omitted intentionally since
-	// they are not needed for consensus validity. However, if a QC is specified, it must be valid.
-	if msg.GetQuorumCertificate() != nil {
-		if err := m.validateQuorumCertificate(msg.GetQuorumCertificate()); err != nil {
+	if err := m.leaderElectionMod.ValidateLeaderClaim(msg); err != nil {
+		return err
+	}
+
+	// A nil QC means the leader did not find a QC from an earlier round of the current height (e.g. in the first
+	// round of every height). Otherwise, the leader must propose the block certified by the QC again.
+	justifyQC := msg.GetQuorumCertificate()
+	if justifyQC != nil {
+		if err := m.validateHighQC(justifyQC); err != nil {
 			return err
 		}
+		if justifyQC.BlockHash != msg.Block.BlockHeader.Hash {
+			return typesCons.ErrQCBlockMismatch
+		}
 	}
 
-	lockedQC := m.LockedQC
-	justifyQC := msg.GetQuorumCertificate()
+	return m.isSafeNode(msg.Block, justifyQC)
+}
 
-	// Safety: not locked
+// The HotStuff `safeNode` predicate. A replica only votes for a proposal that extends the block it is locked on
+// (safety), or that is justified by a QC from a later view than its lock (liveness), which proves that enough
+// validators moved past the locked block for it to never be committed.
+func (m *consensusModule) isSafeNode(block *types.Block, justifyQC *typesCons.QuorumCertificate) error {
+	lockedQC := m.LockedQC
 	if lockedQC == nil {
 		m.nodeLog(typesCons.NotLockedOnQC)
 		return nil
 	}
 
-	// Safety: check the hash of the locked QC
-	// TODO(olshansky): Extend implementation to adopt `ExtendsFrom` as described in the Hotstuff whitepaper.
-	if protoHash(lockedQC.Block) == protoHash(justifyQC.Block) { // && lockedQC.Block.ExtendsFrom(justifyQC.Block)
+	if m.extendsBlock(block, lockedQC.BlockHash) {
 		m.nodeLog(typesCons.ProposalBlockExtends)
 		return nil
 	}
 
-	// Liveness: node is locked on a QC from the past. [TODO]: Do we want to set `m.LockedQC = nil` here or something else?
-	if justifyQC.Height > lockedQC.Height || (justifyQC.Height == lockedQC.Height && justifyQC.Round > lockedQC.Round) {
-		return typesCons.ErrNodeIsLockedOnPastQC
+	if justifyQC != nil && isHigherView(justifyQC, lockedQC) {
+		m.nodeLog(typesCons.ProposalJustifiedByHigherQC)
+		return nil
+	}
+
+	return typesCons.ErrUnsafeProposal(lockedQC.Height, lockedQC.Round)
+}
+
+// Blocks are chained through the hash of their parent, so a block extends the block with hash `blockHash` if it
+// is that block or one of its descendants. In chained mode, the ancestors of a block may not be committed yet.
+func (m *consensusModule) extendsBlock(block *types.Block, blockHash string) bool {
+	for header := block.GetBlockHeader(); header != nil; {
+		if header.Hash == blockHash || header.LastBlockHash == blockHash {
+			return true
+		}
+		parent, ok := m.pendingBlocks[header.LastBlockHash]
+		if !ok {
+			return false
+		}
+		header = parent.BlockHeader
 	}
+	return false
+}
 
-	return typesCons.ErrUnhandledProposalCase
+// Validates the QC justifying a PRECOMMIT, COMMIT or DECIDE proposal from the leader
+func (m *consensusModule) validateJustifiedProposal(msg *typesCons.HotstuffMessage) error {
+	if err := m.validateQuorumCertificate(msg.GetQuorumCertificate()); err != nil {
+		return err
+	}
+	return m.validateQuorumCertificateBinding(msg)
 }
 
 func (m *consensusModule) validateQuorumCertificate(qc *typesCons.QuorumCertificate) error {
@@ -192,42 +234,49 @@ func (m *consensusModule) validateQuorumCertificate(qc *typesCons.QuorumCertific
 		return typesCons.ErrNilBlockInQC
 	}
 
-	if qc.ThresholdSignature == nil || len(qc.ThresholdSignature.Signatures) == 0 {
+	if qc.Block.GetBlockHeader().GetHash() != qc.BlockHash {
+		return typesCons.ErrQCBlockMismatch
+	}
+
+	if qc.ThresholdSignature == nil {
 		return typesCons.ErrNilThresholdSigInQC
 	}
 
-	msgToJustify := qcToHotstuffMessage(qc)
-	numValid := 0
-	for _, partialSig := range qc.ThresholdSignature.Signatures {
-		validator, ok := m.validatorMap[partialSig.Address]
-		if !ok {
-			m.nodeLogError(typesCons.ErrMissingValidator(partialSig.Address, m.ValAddrToIdMap[partialSig.Address]).Error(), nil)
-			continue
-		}
-		// TODO(olshansky): Every call to `IsSignatureValid` does a serialization and should be optimized. We can
-		// just serialize `Message` once and verify each signature without re-serializing every time.
-		if !isSignatureValid(msgToJustify, validator.PublicKey, partialSig.Signature) {
-			m.nodeLog(typesCons.WarnInvalidPartialSigInQC(partialSig.Address, m.ValAddrToIdMap[partialSig.Address]))
-			continue
-		}
-		numValid++
+	_, err := VerifyQuorumCertificateSignature(m.thresholdSigner, m.getValidatorList(), qc)
+	return err
+}
+
+// Verifies that `thresholdSig` is only made of partial signatures over `bytesToVerify` and that its signers meet
+// both the optimistic and the stake byzantine thresholds of the current validator set.
+func (m *consensusModule) validateThresholdSignature(thresholdSig *typesCons.ThresholdSignature, bytesToVerify []byte) error {
+	_, err := verifyThresholdSignature(m.thresholdSigner, m.getValidatorList(), thresholdSig, bytesToVerify)
+	return err
+}
+
+// Verifies that the QC justifying a leader's proposal certifies the proposed block at the same (height, round),
+// and that it was formed during the step immediately preceding the one being proposed.
+func (m *consensusModule) validateQuorumCertificateBinding(msg *typesCons.HotstuffMessage) error {
+	qc := msg.GetQuorumCertificate()
+	if qc == nil {
+		return typesCons.ErrNilQC
 	}
 
-	if err := m.isOptimisticThresholdMet(numValid); err != nil {
-		return err
+	if qc.Height != msg.Height || qc.Round != msg.Round {
+		return typesCons.ErrQCHeightRoundMismatch(qc.Height, qc.Round, msg.Height, msg.Round)
 	}
 
-	return nil
-}
+	if expectedStep := msg.Step - 1; qc.Step != expectedStep {
+		return typesCons.ErrQCStepMismatch(expectedStep, qc.Step)
+	}
+
+	if protoHash(qc.Block) != protoHash(msg.Block) {
+		return typesCons.ErrQCBlockMismatch
+	}
 
-func qcToHotstuffMessage(qc *typesCons.QuorumCertificate) *typesCons.HotstuffMessage {
-	return &typesCons.HotstuffMessage{
-		Height: qc.Height,
-		Step:   qc.Step,
-		Round:  qc.Round,
-		Block:  qc.Block,
-		Justification: &typesCons.HotstuffMessage_QuorumCertificate{
-			QuorumCertificate: qc,
-		},
+	// The replica must only continue voting for the block it validated during the PREPARE step.
+	if m.Block != nil && protoHash(qc.Block) != protoHash(m.Block) {
+		return typesCons.ErrQCBlockMismatch
 	}
+
+	return nil
 }
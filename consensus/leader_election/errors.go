@@ -0,0 +1,49 @@
+package leader_election
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	NilLeaderClaimError            = "a leader claim is required when leaders are elected through VRF sortition"
+	NoLeaderCandidatesError        = "no valid leader candidates for height %d and round %d"
+	UnknownLeaderCandidateError    = "leader claim from %s does not belong to a known validator"
+	InvalidLeaderClaimSigError     = "leader claim from %s has an invalid signature"
+	InvalidLeaderClaimVRFError     = "leader claim from %s has an invalid VRF proof"
+	InvalidValidatorStakeError     = "validator %s has an invalid stake: %s"
+	LeaderClaimOutrankedError      = "leader claim from %s is outranked by the leader claim from %s"
+	UnknownLeaderElectionTypeError = "unknown leader election type: %s"
+)
+
+var (
+	ErrNilLeaderClaim = errors.New(NilLeaderClaimError)
+)
+
+func ErrNoLeaderCandidates(height, round uint64) error {
+	return fmt.Errorf(NoLeaderCandidatesError, height, round)
+}
+
+func ErrUnknownLeaderCandidate(address string) error {
+	return fmt.Errorf(UnknownLeaderCandidateError, address)
+}
+
+func ErrInvalidLeaderClaimSig(address string) error {
+	return fmt.Errorf(InvalidLeaderClaimSigError, address)
+}
+
+func ErrInvalidLeaderClaimVRF(address string) error {
+	return fmt.Errorf(InvalidLeaderClaimVRFError, address)
+}
+
+func ErrInvalidValidatorStake(address, stake string) error {
+	return fmt.Errorf(InvalidValidatorStakeError, address, stake)
+}
+
+func ErrLeaderClaimOutranked(address, leaderAddress string) error {
+	return fmt.Errorf(LeaderClaimOutrankedError, address, leaderAddress)
+}
+
+func ErrUnknownLeaderElectionType(electionType string) error {
+	return fmt.Errorf(UnknownLeaderElectionTypeError, electionType)
+}
@@ -2,27 +2,47 @@ package leader_election
 
 import (
 	"log"
+	"sync"
 
 	typesCons "github.com/pokt-network/pocket/consensus/types"
 	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 	"github.com/pokt-network/pocket/shared/modules"
 )
 
 type LeaderElectionModule interface {
 	modules.Module
 	ElectNextLeader(*typesCons.HotstuffMessage) (typesCons.NodeId, error)
+	// Returns the claim this node attaches to its NEWROUND and PROPOSE messages for the specified view,
+	// or nil if the leader election strategy does not require one.
+	CreateLeaderClaim(height, round uint64) (*typesCons.LeaderClaim, error)
+	// Verifies that the leader claim attached to a PROPOSE message elects its sender as the leader.
+	ValidateLeaderClaim(*typesCons.HotstuffMessage) error
 }
 
 var _ leaderElectionModule = leaderElectionModule{}
 
 type leaderElectionModule struct {
 	bus modules.Bus
+
+	electionType config.LeaderElectionType
+	privateKey   cryptoPocket.PrivateKey
+
+	// VRF Sortition
+	candidates map[view]map[string]*leaderCandidate // Valid leader candidates of each view keyed by their hex encoded address
+	ownClaims  map[view]*typesCons.LeaderClaim
+	mu         sync.Mutex // Claims are created by the pacemaker's timer and received through the bus concurrently
 }
 
 func Create(
 	config *config.Config,
 ) (LeaderElectionModule, error) {
-	return &leaderElectionModule{}, nil
+	return &leaderElectionModule{
+		electionType: config.Consensus.LeaderElection,
+		privateKey:   config.PrivateKey,
+		candidates:   make(map[view]map[string]*leaderCandidate),
+		ownClaims:    make(map[view]*typesCons.LeaderClaim),
+	}, nil
 }
 
 func (m *leaderElectionModule) Start() error {
@@ -46,7 +66,30 @@ func (m *leaderElectionModule) GetBus() modules.Bus {
 }
 
 func (m *leaderElectionModule) ElectNextLeader(message *typesCons.HotstuffMessage) (typesCons.NodeId, error) {
-	return m.electNextLeaderDeterministicRoundRobin(message), nil
+	switch m.electionType {
+	case config.RoundRobinLeaderElection:
+		return m.electNextLeaderDeterministicRoundRobin(message), nil
+	case config.VRFSortitionLeaderElection:
+		return m.electNextLeaderVRFSortition(message)
+	default:
+		return 0, ErrUnknownLeaderElectionType(string(m.electionType))
+	}
+}
+
+func (m *leaderElectionModule) CreateLeaderClaim(height, round uint64) (*typesCons.LeaderClaim, error) {
+	if m.electionType != config.VRFSortitionLeaderElection {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.getOwnLeaderClaim(view{height, round})
+}
+
+func (m *leaderElectionModule) ValidateLeaderClaim(message *typesCons.HotstuffMessage) error {
+	if m.electionType != config.VRFSortitionLeaderElection {
+		return nil
+	}
+	return m.validateLeaderClaimVRFSortition(message)
 }
 
 func (m *leaderElectionModule) electNextLeaderDeterministicRoundRobin(message *typesCons.HotstuffMessage) typesCons.NodeId {
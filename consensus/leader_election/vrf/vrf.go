@@ -41,7 +41,7 @@ func CreateVRFRandReader(lastBlockHash string, privKey crypto.PrivateKey) (io.Re
 
 	seed := make([]byte, crypto.SeedSize)
 	copy(seed, privKeySeed)
-	copy(seed, blockHashSeed)
+	copy(seed[crypto.SeedSize/2:], blockHashSeed)
 
 	return bytes.NewReader(seed), nil
 }
@@ -60,6 +60,23 @@ func GenerateVRFKeys(reader io.Reader) (*SecretKey, *VerificationKey, error) {
 	return (*SecretKey)(privateKey), (*VerificationKey)(publicKey), nil
 }
 
+// The VRF keys of a validator are its ed25519 keys, since ECVRF-EDWARDS25519 uses the same curve and key encoding.
+// This binds the VRF keys to the validator's staked public key, so a validator cannot grind through VRF keys.
+func SecretKeyFromPrivateKey(privKey crypto.PrivateKey) (*SecretKey, error) {
+	if privKey == nil {
+		return nil, ErrNilPrivateKey
+	}
+	secretKey, err := ecvrf.NewPrivateKey(privKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return (*SecretKey)(secretKey), nil
+}
+
+func VerificationKeyFromPublicKey(pubKey crypto.PublicKey) (*VerificationKey, error) {
+	return VerificationKeyFromBytes(pubKey.Bytes())
+}
+
 func VerificationKeyFromBytes(data []byte) (*VerificationKey, error) {
 	key, err := ecvrf.NewPublicKey(data)
 	if err != nil {
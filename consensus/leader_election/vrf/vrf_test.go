@@ -31,8 +31,8 @@ func TestVRFKeygenWithSeed(t *testing.T) {
 	sk, vk, err := GenerateVRFKeys(reader)
 	require.Nil(t, err)
 
-	require.Equal(t, "4f6c7368616e736b7920776f6e64657200000000000000000000000000000000c8491df826eccf7557467c74f7a93bb324a15efd2359dc27e3eba940127ff8a2", hex.EncodeToString(sk.Bytes()))
-	require.Equal(t, "c8491df826eccf7557467c74f7a93bb324a15efd2359dc27e3eba940127ff8a2", hex.EncodeToString(vk.Bytes()))
+	require.Equal(t, "4f6c7368616e736b7920776f6e6465724f6c7368616e736b7920776f6e64657255919e28756b1bac938a8667cbe28d0177cdd1340883511c7eca46b062df9d1a", hex.EncodeToString(sk.Bytes()))
+	require.Equal(t, "55919e28756b1bac938a8667cbe28d0177cdd1340883511c7eca46b062df9d1a", hex.EncodeToString(vk.Bytes()))
 }
 
 func TestVRFKeygenProveAndVerify(t *testing.T) {
@@ -84,8 +84,8 @@ func TestVRFKeygenProveAndVerifyWithSeed(t *testing.T) {
 
 	vrfOut, vrfProof, err := sk.Prove(msg)
 	require.Nil(t, err)
-	require.Equal(t, "d4c95d83e26323ec6e86801d810071aefbface10ac59c250e58096f18a72b56c8d9166cfc8252bbb80def11f438d5ce484373f718261555b59eb6f6d9af9370a", hex.EncodeToString(vrfOut))
-	require.Equal(t, "3d277cbd2d7ecde326e2cd3cf3d7787997c52fe7bf98c18e8417f4b5e2e7d78368ef28822f2e4b3d806ed4e5cbc492c67d9bcb86b09c9c49978712041d2ffd7aa433dc7a326362fe70657a66af3a220d", hex.EncodeToString(vrfProof))
+	require.Equal(t, "84687daad31621c574ccd2fe602e67b93a49509943a518f4aeca033ef922a72e3241569e3edc8619d0d2df1304b72305f49599b7a64c3124584629ae99e22c1b", hex.EncodeToString(vrfOut))
+	require.Equal(t, "56eecc656612662a6c313e13e9ee6670194c3148bc56d0280a9cb2a052123d93439fd98706740ef74b765dd07ab26a90820219e2fd38ed503aa13682e2eb905806a91f70877d8d443eec459a72757b0d", hex.EncodeToString(vrfProof))
 
 	// Successfull verification
 	verified, err := vk.Verify(msg, vrfProof, vrfOut)
@@ -107,3 +107,38 @@ func TestVRFKeygenProveAndVerifyWithSeed(t *testing.T) {
 	require.Nil(t, err)
 	require.False(t, verified)
 }
+
+func TestVRFKeysFromValidatorKeys(t *testing.T) {
+	msg := []byte("Proving this with the keys I staked with")
+
+	privKey, err := crypto.GeneratePrivateKey()
+	require.Nil(t, err)
+	otherPrivKey, err := crypto.GeneratePrivateKey()
+	require.Nil(t, err)
+
+	sk, err := SecretKeyFromPrivateKey(privKey)
+	require.Nil(t, err)
+	vrfOut, vrfProof, err := sk.Prove(msg)
+	require.Nil(t, err)
+
+	// The verification key is the validator's public key
+	vk, err := VerificationKeyFromPublicKey(privKey.PublicKey())
+	require.Nil(t, err)
+	skVerificationKey, err := sk.VerificationKey()
+	require.Nil(t, err)
+	require.Equal(t, privKey.PublicKey().Bytes(), skVerificationKey.Bytes())
+
+	verified, err := vk.Verify(msg, vrfProof, vrfOut)
+	require.Nil(t, err)
+	require.True(t, verified)
+
+	// A proof does not verify against the public key of another validator
+	otherVk, err := VerificationKeyFromPublicKey(otherPrivKey.PublicKey())
+	require.Nil(t, err)
+	verified, err = otherVk.Verify(msg, vrfProof, vrfOut)
+	require.Nil(t, err)
+	require.False(t, verified)
+
+	_, err = SecretKeyFromPrivateKey(nil)
+	require.Equal(t, ErrNilPrivateKey, err)
+}
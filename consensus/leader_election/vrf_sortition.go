@@ -0,0 +1,229 @@
+package leader_election
+
+import (
+	"bytes"
+
+	"github.com/pokt-network/pocket/consensus/leader_election/sortition"
+	"github.com/pokt-network/pocket/consensus/leader_election/vrf"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// TODO(olshansky): Move this to config.json.
+// The number of uPOKT expected to be selected through sortition in every view. Candidates are ranked by
+// their sortition result, so several candidates are selected to reduce the chances of there being no leader.
+const numExpectedLeaderCandidates = uint64(3)
+
+type view struct {
+	height uint64
+	round  uint64
+}
+
+type leaderCandidate struct {
+	nodeId          typesCons.NodeId
+	address         string
+	sortitionResult sortition.SortitionResult
+	vrfOut          vrf.VRFOutput
+}
+
+// Candidates are ranked by their sortition result. Ties, including the case where no uPOKT were
+// selected for any candidate, are broken using the VRF output and finally the address.
+func (c *leaderCandidate) outranks(other *leaderCandidate) bool {
+	if other == nil {
+		return true
+	}
+	if c.sortitionResult != other.sortitionResult {
+		return c.sortitionResult > other.sortitionResult
+	}
+	if cmp := bytes.Compare(c.vrfOut, other.vrfOut); cmp != 0 {
+		return cmp > 0
+	}
+	return c.address < other.address
+}
+
+// Every NEWROUND message carries the sender's leader claim, so the elected leader is the highest ranked
+// candidate this node has seen so far for the message's view. The node's own claim is always considered.
+func (m *leaderElectionModule) electNextLeaderVRFSortition(message *typesCons.HotstuffMessage) (typesCons.NodeId, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v := view{message.Height, message.Round}
+	m.pruneViews(message.Height)
+
+	if _, err := m.getOwnLeaderClaim(v); err != nil {
+		return 0, err
+	}
+
+	// Invalid claims are not considered; PROPOSE messages carrying them are rejected by `ValidateLeaderClaim`.
+	if claim := message.GetLeaderClaim(); claim != nil {
+		if candidate, err := m.verifyLeaderClaim(v, claim); err == nil {
+			m.candidates[v][candidate.address] = candidate
+		}
+	}
+
+	leader := m.getHighestRankedCandidate(v)
+	if leader == nil {
+		return 0, ErrNoLeaderCandidates(v.height, v.round)
+	}
+	return leader.nodeId, nil
+}
+
+func (m *leaderElectionModule) validateLeaderClaimVRFSortition(message *typesCons.HotstuffMessage) error {
+	claim := message.GetLeaderClaim()
+	if claim == nil {
+		return ErrNilLeaderClaim
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v := view{message.Height, message.Round}
+	if _, err := m.getOwnLeaderClaim(v); err != nil {
+		return err
+	}
+
+	candidate, err := m.verifyLeaderClaim(v, claim)
+	if err != nil {
+		return err
+	}
+	m.candidates[v][candidate.address] = candidate
+
+	if leader := m.getHighestRankedCandidate(v); leader.address != candidate.address {
+		return ErrLeaderClaimOutranked(candidate.address, leader.address)
+	}
+	return nil
+}
+
+// Returns this node's claim for the specified view, creating and registering it as a candidate if needed.
+func (m *leaderElectionModule) getOwnLeaderClaim(v view) (*typesCons.LeaderClaim, error) {
+	if claim, ok := m.ownClaims[v]; ok {
+		return claim, nil
+	}
+	if _, ok := m.candidates[v]; !ok {
+		m.candidates[v] = make(map[string]*leaderCandidate)
+	}
+
+	lastBlockHash := m.GetBus().GetConsensusModule().BlockHash()
+	secretKey, err := vrf.SecretKeyFromPrivateKey(m.privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	vrfOut, vrfProof, err := secretKey.Prove(sortition.FormatSeed(v.height, v.round, lastBlockHash))
+	if err != nil {
+		return nil, err
+	}
+
+	claim := &typesCons.LeaderClaim{
+		Address:   m.privateKey.Address().String(),
+		VrfOutput: vrfOut,
+		VrfProof:  vrfProof,
+	}
+	signableBytes, err := getLeaderClaimSignableBytes(claim)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Signature, err = m.privateKey.Sign(signableBytes); err != nil {
+		return nil, err
+	}
+	m.ownClaims[v] = claim
+
+	// Nodes that are not validators still create a claim, but it never makes them a candidate.
+	if candidate, err := m.verifyLeaderClaim(v, claim); err == nil {
+		m.candidates[v][candidate.address] = candidate
+	}
+
+	return claim, nil
+}
+
+// The VRF proof is verified against the public key the validator staked with, so the only input a validator
+// controls is the choice of whether to claim at all. The seed changes with every block through the last block hash.
+func (m *leaderElectionModule) verifyLeaderClaim(v view, claim *typesCons.LeaderClaim) (*leaderCandidate, error) {
+	consensusMod := m.GetBus().GetConsensusModule()
+	validatorMap := consensusMod.ValidatorMap()
+
+	validator, ok := validatorMap[claim.Address]
+	if !ok {
+		return nil, ErrUnknownLeaderCandidate(claim.Address)
+	}
+
+	pubKey, err := cryptoPocket.NewPublicKeyFromBytes(validator.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signableBytes, err := getLeaderClaimSignableBytes(claim)
+	if err != nil {
+		return nil, err
+	}
+	if !pubKey.Verify(signableBytes, claim.Signature) {
+		return nil, ErrInvalidLeaderClaimSig(claim.Address)
+	}
+
+	verificationKey, err := vrf.VerificationKeyFromPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	seed := sortition.FormatSeed(v.height, v.round, consensusMod.BlockHash())
+	if verified, err := verificationKey.Verify(seed, claim.VrfProof, claim.VrfOutput); err != nil || !verified {
+		return nil, ErrInvalidLeaderClaimVRF(claim.Address)
+	}
+
+	validatorStake, networkStake, err := getStakes(validatorMap, claim.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	valAddrToIdMap, _ := typesCons.GetValAddrToIdMap(validatorMap)
+	return &leaderCandidate{
+		nodeId:          valAddrToIdMap[claim.Address],
+		address:         claim.Address,
+		sortitionResult: sortition.Sortition(validatorStake, networkStake, numExpectedLeaderCandidates, claim.VrfOutput),
+		vrfOut:          claim.VrfOutput,
+	}, nil
+}
+
+func (m *leaderElectionModule) getHighestRankedCandidate(v view) (leader *leaderCandidate) {
+	for _, candidate := range m.candidates[v] {
+		if candidate.outranks(leader) {
+			leader = candidate
+		}
+	}
+	return
+}
+
+// Claims from previous heights can no longer elect a leader.
+func (m *leaderElectionModule) pruneViews(height uint64) {
+	for v := range m.candidates {
+		if v.height < height {
+			delete(m.candidates, v)
+		}
+	}
+	for v := range m.ownClaims {
+		if v.height < height {
+			delete(m.ownClaims, v)
+		}
+	}
+}
+
+func getLeaderClaimSignableBytes(claim *typesCons.LeaderClaim) ([]byte, error) {
+	unsignedClaim := proto.Clone(claim).(*typesCons.LeaderClaim)
+	unsignedClaim.Signature = nil
+	return proto.Marshal(unsignedClaim)
+}
+
+func getStakes(validatorMap modules.ValidatorMap, address string) (validatorStake, networkStake uint64, err error) {
+	for addr, validator := range validatorMap {
+		stake, err := types.StringToBigInt(validator.StakedTokens)
+		if err != nil || !stake.IsUint64() {
+			return 0, 0, ErrInvalidValidatorStake(addr, validator.StakedTokens)
+		}
+		if addr == address {
+			validatorStake = stake.Uint64()
+		}
+		networkStake += stake.Uint64()
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	NilHeaderError              = "block header cannot be nil"
+	NilCommitQCError            = "a commit QC is required to verify a block header"
+	EmptyValidatorSetError      = "the trusted validator set cannot be empty"
+	UnexpectedHeaderHeightError = "expected a block header at height %d but got %d"
+	InvalidHeaderHashError      = "block header hash is invalid: %s != %s"
+	InvalidLastBlockHashError   = "block header does not extend the last verified header: %s != %s"
+	InvalidValidatorsHashError  = "validator set does not match the one in the block header: %s != %s"
+	CommitQCMismatchError       = "commit QC at (height, step): (%d, %s) does not certify the block header %s"
+	InvalidCommitQCError        = "commit QC is invalid: %s"
+	UntrustedValidatorSetError  = "the validators of the trusted set that signed the commit QC control %s out of %s staked tokens, which is not more than 1/3"
+	InvalidValidatorStakeError  = "validator %s has an invalid stake: %s"
+	HeaderNotFoundError         = "no verified block header at height %d"
+	AppHashMismatchError        = "app hash does not match the verified block header at height %d: %s != %s"
+	NoVerifiedHeadersError      = "no block header has been verified yet"
+	ChainedHeaderError          = "block header is certified by a PREPARE QC; headers produced in chained hotstuff mode are not supported"
+)
+
+var (
+	ErrNilHeader         = errors.New(NilHeaderError)
+	ErrNilCommitQC       = errors.New(NilCommitQCError)
+	ErrEmptyValidatorSet = errors.New(EmptyValidatorSetError)
+	ErrNoVerifiedHeaders = errors.New(NoVerifiedHeadersError)
+	ErrChainedHeader     = errors.New(ChainedHeaderError)
+)
+
+func ErrUnexpectedHeaderHeight(expected, actual int64) error {
+	return fmt.Errorf(UnexpectedHeaderHeightError, expected, actual)
+}
+
+func ErrInvalidHeaderHash(hash, computedHash string) error {
+	return fmt.Errorf(InvalidHeaderHashError, hash, computedHash)
+}
+
+func ErrInvalidLastBlockHash(lastBlockHash, expectedLastBlockHash string) error {
+	return fmt.Errorf(InvalidLastBlockHashError, lastBlockHash, expectedLastBlockHash)
+}
+
+func ErrInvalidValidatorsHash(validatorsHash, headerValidatorsHash string) error {
+	return fmt.Errorf(InvalidValidatorsHashError, validatorsHash, headerValidatorsHash)
+}
+
+func ErrCommitQCMismatch(height uint64, step, blockHash string) error {
+	return fmt.Errorf(CommitQCMismatchError, height, step, blockHash)
+}
+
+func ErrInvalidCommitQC(err error) error {
+	return fmt.Errorf(InvalidCommitQCError, err.Error())
+}
+
+func ErrUntrustedValidatorSet(trustedSignedStake, trustedTotalStake string) error {
+	return fmt.Errorf(UntrustedValidatorSetError, trustedSignedStake, trustedTotalStake)
+}
+
+func ErrInvalidValidatorStake(address, stake string) error {
+	return fmt.Errorf(InvalidValidatorStakeError, address, stake)
+}
+
+func ErrHeaderNotFound(height int64) error {
+	return fmt.Errorf(HeaderNotFoundError, height)
+}
+
+func ErrAppHashMismatch(height int64, appHash, verifiedAppHash string) error {
+	return fmt.Errorf(AppHashMismatchError, height, appHash, verifiedAppHash)
+}
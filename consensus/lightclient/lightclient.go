@@ -0,0 +1,230 @@
+package lightclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/config"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"google.golang.org/protobuf/proto"
+)
+
+// Verifies the block headers of a chain one height after the other, starting from a trusted genesis validator set,
+// so an external process can trust chain data served by full nodes without running one itself.
+//
+// A header is verified if it extends the last verified header and is certified by a COMMIT QC signed by more than
+// 2/3 of the validator set that voted on it, which the header commits to through its validators hash. The validator
+// set of a header is only trusted if it is the same as the one of the previous header, or if validators of the
+// previous set controlling more than 1/3 of its stake also signed the commit QC, since at least one of them is honest.
+// TODO(research): Headers produced in chained Hotstuff mode are rejected since their QCs are formed in the PREPARE
+// step, a block is only committed once its grandchild is certified and their app hash is the state before the block.
+type LightClient struct {
+	m sync.Mutex
+
+	thresholdSigner consensus.ThresholdSigner
+	store           HeaderStore
+
+	latest         *types.BlockHeader   // The last verified header; nil until the header at height 1 is verified
+	validators     []*genesis.Validator // The validator set that voted on the last verified header, sorted by address
+	validatorsHash string
+}
+
+// `scheme` must be the threshold signature scheme the chain's validators form QCs with.
+func NewLightClient(genesisValidators []*genesis.Validator, scheme config.ThresholdSignatureScheme, store HeaderStore) (*LightClient, error) {
+	if len(genesisValidators) == 0 {
+		return nil, ErrEmptyValidatorSet
+	}
+
+	thresholdSigner, err := consensus.CreateThresholdSigner(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	validators := sortValidators(genesisValidators)
+	validatorsHash, err := consensus.ValidatorSetHash(validators)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LightClient{
+		thresholdSigner: thresholdSigner,
+		store:           store,
+		latest:          nil,
+		validators:      validators,
+		validatorsHash:  validatorsHash,
+	}, nil
+}
+
+// Verifies the header following the last verified one and adds it to the header store. `commitQC` is the COMMIT QC
+// of the block, which is served by full nodes alongside committed blocks and embedded in the header of the next one.
+// `validators` is the validator set that voted on the block, which is only required if it differs from the set that
+// voted on the last verified header.
+func (c *LightClient) VerifyHeader(header *types.BlockHeader, commitQC *typesCons.QuorumCertificate, validators []*genesis.Validator) error {
+	if header == nil {
+		return ErrNilHeader
+	}
+	if commitQC == nil {
+		return ErrNilCommitQC
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	expectedHeight, lastBlockHash := int64(1), ""
+	if c.latest != nil {
+		expectedHeight, lastBlockHash = c.latest.Height+1, c.latest.Hash
+	}
+	if header.Height != expectedHeight {
+		return ErrUnexpectedHeaderHeight(expectedHeight, header.Height)
+	}
+
+	headerHash, err := header.ComputeHash()
+	if err != nil {
+		return err
+	}
+	if header.Hash != headerHash {
+		return ErrInvalidHeaderHash(header.Hash, headerHash)
+	}
+
+	if header.LastBlockHash != lastBlockHash {
+		return ErrInvalidLastBlockHash(header.LastBlockHash, lastBlockHash)
+	}
+
+	validatorsHash := c.validatorsHash
+	if validators == nil {
+		validators = c.validators
+	} else {
+		validators = sortValidators(validators)
+		if validatorsHash, err = consensus.ValidatorSetHash(validators); err != nil {
+			return err
+		}
+	}
+	if header.ValidatorsHash != validatorsHash {
+		return ErrInvalidValidatorsHash(validatorsHash, header.ValidatorsHash)
+	}
+
+	// The app hash of a chained header is the state of the last committed block, so it is not the root the state
+	// proofs of its height must be verified against.
+	if commitQC.Step == consensus.Prepare {
+		return ErrChainedHeader
+	}
+	if commitQC.Height != uint64(header.Height) || commitQC.Step != consensus.Commit || commitQC.BlockHash != header.Hash {
+		return ErrCommitQCMismatch(commitQC.Height, commitQC.Step.String(), header.Hash)
+	}
+	signerBitmap, err := consensus.VerifyQuorumCertificateSignature(c.thresholdSigner, validators, commitQC)
+	if err != nil {
+		return ErrInvalidCommitQC(err)
+	}
+
+	if validatorsHash != c.validatorsHash {
+		if err := c.verifyValidatorSetChange(validators, signerBitmap); err != nil {
+			return err
+		}
+	}
+
+	if err := c.store.Put(header); err != nil {
+		return err
+	}
+	c.latest = header
+	c.validators = validators
+	c.validatorsHash = validatorsHash
+
+	return nil
+}
+
+// Verifies consecutive `headers` using the commit QC of every header embedded in the header that follows it, so the
+// last header is not verified itself. `validatorSets` maps the heights at which the validator set changes to the
+// validator set that voted on the header at that height.
+func (c *LightClient) VerifyHeaderChain(headers []*types.BlockHeader, validatorSets map[int64][]*genesis.Validator) error {
+	for i := 0; i+1 < len(headers); i++ {
+		commitQCBz := headers[i+1].GetQuorumCertificate()
+		if len(commitQCBz) == 0 {
+			return ErrNilCommitQC
+		}
+		commitQC := &typesCons.QuorumCertificate{}
+		if err := proto.Unmarshal(commitQCBz, commitQC); err != nil {
+			return err
+		}
+
+		if err := c.VerifyHeader(headers[i], commitQC, validatorSets[headers[i].GetHeight()]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the verified header at `height`.
+func (c *LightClient) TrustedHeader(height int64) (*types.BlockHeader, error) {
+	return c.store.Get(height)
+}
+
+// Returns the last verified header.
+func (c *LightClient) LatestHeader() (*types.BlockHeader, error) {
+	return c.store.Latest()
+}
+
+// Returns the validator set that voted on the last verified header, sorted by address.
+func (c *LightClient) TrustedValidators() []*genesis.Validator {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.validators
+}
+
+// Verifies that `appHash` is the app hash of the verified header at `height`, i.e. the root the state proofs of
+// that height must be verified against.
+func (c *LightClient) VerifyAppHash(height int64, appHash string) error {
+	header, err := c.store.Get(height)
+	if err != nil {
+		return err
+	}
+	if header.AppHash != appHash {
+		return ErrAppHashMismatch(height, appHash, header.AppHash)
+	}
+	return nil
+}
+
+// Verifies that validators of the trusted set controlling more than 1/3 of its stake signed the commit QC with the
+// same key. Since less than 1/3 of the trusted set is byzantine, at least one honest validator vouches for the header
+// and therefore for the new validator set it commits to.
+func (c *LightClient) verifyValidatorSetChange(validators []*genesis.Validator, signerBitmap consensus.SignerBitmap) error {
+	signerKeys := make(map[string][]byte, signerBitmap.Count())
+	for i, validator := range validators {
+		if signerBitmap.IsSet(i) {
+			signerKeys[hex.EncodeToString(validator.Address)] = validator.PublicKey
+		}
+	}
+
+	signedStake, totalStake := big.NewInt(0), big.NewInt(0)
+	for _, trustedValidator := range c.validators {
+		address := hex.EncodeToString(trustedValidator.Address)
+		stake, err := types.StringToBigInt(trustedValidator.StakedTokens)
+		if err != nil {
+			return ErrInvalidValidatorStake(address, trustedValidator.StakedTokens)
+		}
+		totalStake.Add(totalStake, stake)
+		if signerKey, ok := signerKeys[address]; ok && bytes.Equal(signerKey, trustedValidator.PublicKey) {
+			signedStake.Add(signedStake, stake)
+		}
+	}
+
+	if new(big.Int).Mul(signedStake, big.NewInt(3)).Cmp(totalStake) <= 0 {
+		return ErrUntrustedValidatorSet(signedStake.String(), totalStake.String())
+	}
+	return nil
+}
+
+// Returns a copy of `validators` sorted by address, which is the order QCs and validator set hashes refer to.
+func sortValidators(validators []*genesis.Validator) []*genesis.Validator {
+	sorted := make([]*genesis.Validator, len(validators))
+	copy(sorted, validators)
+	sort.Slice(sorted, func(i, j int) bool {
+		return hex.EncodeToString(sorted[i].Address) < hex.EncodeToString(sorted[j].Address)
+	})
+	return sorted
+}
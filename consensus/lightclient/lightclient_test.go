@@ -0,0 +1,235 @@
+package lightclient
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pokt-network/pocket/consensus"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const testValidatorStake = "1000000000"
+
+type testValidator struct {
+	privKey   cryptoPocket.PrivateKey
+	validator *genesis.Validator
+}
+
+func TestLightClientVerifiesHeaderChain(t *testing.T) {
+	for _, scheme := range []config.ThresholdSignatureScheme{config.PartialSignatureListScheme, config.AggregateSignatureScheme} {
+		t.Run(string(scheme), func(t *testing.T) {
+			genesisSet := newTestValidatorSet(t, 4)
+			headers, commitQCs := newTestChain(t, scheme, genesisSet, 3)
+
+			client, err := NewLightClient(validatorsOf(genesisSet), scheme, NewMemHeaderStore())
+			require.NoError(t, err)
+			_, err = client.LatestHeader()
+			require.ErrorIs(t, err, ErrNoVerifiedHeaders)
+
+			// Headers must be verified in order
+			require.Error(t, client.VerifyHeader(headers[1], commitQCs[1], nil))
+
+			// The commit QC of every header but the last one is embedded in the header that follows it
+			require.NoError(t, client.VerifyHeaderChain(headers, nil))
+			latest, err := client.LatestHeader()
+			require.NoError(t, err)
+			require.Equal(t, headers[1], latest)
+
+			require.NoError(t, client.VerifyHeader(headers[2], commitQCs[2], nil))
+			for _, header := range headers {
+				trustedHeader, err := client.TrustedHeader(header.Height)
+				require.NoError(t, err)
+				require.Equal(t, header, trustedHeader)
+				require.NoError(t, client.VerifyAppHash(header.Height, header.AppHash))
+			}
+			require.Error(t, client.VerifyAppHash(2, headers[0].AppHash))
+			_, err = client.TrustedHeader(4)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLightClientRejectsInvalidHeaders(t *testing.T) {
+	scheme := config.PartialSignatureListScheme
+	genesisSet := newTestValidatorSet(t, 4)
+	headers, commitQCs := newTestChain(t, scheme, genesisSet, 2)
+
+	client, err := NewLightClient(validatorsOf(genesisSet), scheme, NewMemHeaderStore())
+	require.NoError(t, err)
+
+	// A header whose content does not match its hash
+	tamperedHeader := proto.Clone(headers[0]).(*types.BlockHeader)
+	tamperedHeader.AppHash = "00"
+	require.Error(t, client.VerifyHeader(tamperedHeader, commitQCs[0], nil))
+
+	// A consistent header that is not the one the validators committed
+	tamperedHeader.Hash, err = tamperedHeader.ComputeHash()
+	require.NoError(t, err)
+	require.Error(t, client.VerifyHeader(tamperedHeader, commitQCs[0], nil))
+
+	// A commit QC that is not signed by enough validators
+	weakQC := commitQC(t, scheme, genesisSet, genesisSet[:2], headers[0])
+	require.Error(t, client.VerifyHeader(headers[0], weakQC, nil))
+
+	// A QC from another step
+	preCommitQC := proto.Clone(commitQCs[0]).(*typesCons.QuorumCertificate)
+	preCommitQC.Step = consensus.PreCommit
+	require.Error(t, client.VerifyHeader(headers[0], preCommitQC, nil))
+
+	// A header certified in chained hotstuff mode
+	prepareQC := proto.Clone(commitQCs[0]).(*typesCons.QuorumCertificate)
+	prepareQC.Step = consensus.Prepare
+	require.ErrorIs(t, client.VerifyHeader(headers[0], prepareQC, nil), ErrChainedHeader)
+
+	// A validator set the header does not commit to
+	require.Error(t, client.VerifyHeader(headers[0], commitQCs[0], validatorsOf(genesisSet[:3])))
+
+	// None of the above were trusted
+	require.NoError(t, client.VerifyHeader(headers[0], commitQCs[0], nil))
+	require.NoError(t, client.VerifyHeader(headers[1], commitQCs[1], nil))
+}
+
+func TestLightClientValidatorSetChanges(t *testing.T) {
+	scheme := config.AggregateSignatureScheme
+	genesisSet := newTestValidatorSet(t, 4)
+
+	// Two of the four validators are replaced, which the remaining half of the stake vouches for
+	nextSet := append(newTestValidatorSet(t, 2), genesisSet[:2]...)
+	header1 := newTestHeader(t, 1, "", validatorsOf(genesisSet))
+	header2 := newTestHeader(t, 2, header1.Hash, validatorsOf(nextSet))
+
+	client, err := NewLightClient(validatorsOf(genesisSet), scheme, NewMemHeaderStore())
+	require.NoError(t, err)
+	require.NoError(t, client.VerifyHeader(header1, commitQC(t, scheme, genesisSet, genesisSet, header1), nil))
+
+	// The new validator set is required to verify the header
+	require.Error(t, client.VerifyHeader(header2, commitQC(t, scheme, nextSet, nextSet, header2), nil))
+
+	// A validator set that none of the trusted validators vouch for, even if it meets the threshold itself
+	forgedSet := newTestValidatorSet(t, 4)
+	forgedHeader := newTestHeader(t, 2, header1.Hash, validatorsOf(forgedSet))
+	err = client.VerifyHeader(forgedHeader, commitQC(t, scheme, forgedSet, forgedSet, forgedHeader), validatorsOf(forgedSet))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not more than 1/3")
+
+	// A quorum of the new validator set in which the trusted validators control less than 1/3 of the trusted stake
+	err = client.VerifyHeader(header2, commitQC(t, scheme, nextSet, nextSet[:3], header2), validatorsOf(nextSet))
+	require.Error(t, err)
+
+	require.NoError(t, client.VerifyHeader(header2, commitQC(t, scheme, nextSet, nextSet, header2), validatorsOf(nextSet)))
+	require.ElementsMatch(t, validatorsOf(nextSet), client.TrustedValidators())
+
+	// Once trusted, the new validator set is used for the following headers
+	header3 := newTestHeader(t, 3, header2.Hash, validatorsOf(nextSet))
+	require.NoError(t, client.VerifyHeader(header3, commitQC(t, scheme, nextSet, nextSet, header3), nil))
+}
+
+// Returns `numHeaders` consecutive headers voted on by `validatorSet`, where the header at every height embeds the
+// commit QC of the previous one, alongside their commit QCs.
+func newTestChain(t *testing.T, scheme config.ThresholdSignatureScheme, validatorSet []*testValidator, numHeaders int) ([]*types.BlockHeader, []*typesCons.QuorumCertificate) {
+	headers := make([]*types.BlockHeader, 0, numHeaders)
+	commitQCs := make([]*typesCons.QuorumCertificate, 0, numHeaders)
+	lastBlockHash, lastCommitQCBz := "", []byte(nil)
+	for height := int64(1); height <= int64(numHeaders); height++ {
+		header := &types.BlockHeader{
+			Height:            height,
+			LastBlockHash:     lastBlockHash,
+			QuorumCertificate: lastCommitQCBz,
+			AppHash:           hex.EncodeToString(cryptoPocket.SHA3Hash([]byte{byte(height)})),
+			ValidatorsHash:    validatorSetHash(t, validatorsOf(validatorSet)),
+		}
+		var err error
+		header.Hash, err = header.ComputeHash()
+		require.NoError(t, err)
+
+		qc := commitQC(t, scheme, validatorSet, validatorSet, header)
+		lastBlockHash = header.Hash
+		lastCommitQCBz, err = proto.Marshal(qc)
+		require.NoError(t, err)
+
+		headers = append(headers, header)
+		commitQCs = append(commitQCs, qc)
+	}
+	return headers, commitQCs
+}
+
+func newTestHeader(t *testing.T, height int64, lastBlockHash string, validators []*genesis.Validator) *types.BlockHeader {
+	header := &types.BlockHeader{
+		Height:         height,
+		LastBlockHash:  lastBlockHash,
+		ValidatorsHash: validatorSetHash(t, validators),
+	}
+	var err error
+	header.Hash, err = header.ComputeHash()
+	require.NoError(t, err)
+	return header
+}
+
+// Returns a COMMIT QC for `header` signed by `signers`, which are validators of `validatorSet`.
+func commitQC(t *testing.T, scheme config.ThresholdSignatureScheme, validatorSet, signers []*testValidator, header *types.BlockHeader) *typesCons.QuorumCertificate {
+	bytesToSign, err := proto.Marshal(&typesCons.SignableVote{
+		Height:    uint64(header.Height),
+		Step:      consensus.Commit,
+		BlockHash: header.Hash,
+	})
+	require.NoError(t, err)
+
+	partialSigs := make([]*typesCons.PartialSignature, 0, len(signers))
+	for _, signer := range signers {
+		signature, err := signer.privKey.Sign(bytesToSign)
+		require.NoError(t, err)
+		partialSigs = append(partialSigs, &typesCons.PartialSignature{
+			Signature: signature,
+			Address:   signer.privKey.Address().String(),
+		})
+	}
+
+	thresholdSigner, err := consensus.CreateThresholdSigner(scheme)
+	require.NoError(t, err)
+	thresholdSig, err := thresholdSigner.Aggregate(sortValidators(validatorsOf(validatorSet)), bytesToSign, partialSigs)
+	require.NoError(t, err)
+
+	return &typesCons.QuorumCertificate{
+		Height:             uint64(header.Height),
+		Step:               consensus.Commit,
+		BlockHash:          header.Hash,
+		ThresholdSignature: thresholdSig,
+	}
+}
+
+func newTestValidatorSet(t *testing.T, n int) []*testValidator {
+	validatorSet := make([]*testValidator, n)
+	for i := range validatorSet {
+		privKey, err := cryptoPocket.GeneratePrivateKey()
+		require.NoError(t, err)
+		validatorSet[i] = &testValidator{
+			privKey: privKey,
+			validator: &genesis.Validator{
+				Address:      privKey.Address(),
+				PublicKey:    privKey.PublicKey().Bytes(),
+				StakedTokens: testValidatorStake,
+			},
+		}
+	}
+	return validatorSet
+}
+
+func validatorsOf(validatorSet []*testValidator) []*genesis.Validator {
+	validators := make([]*genesis.Validator, len(validatorSet))
+	for i, testValidator := range validatorSet {
+		validators[i] = testValidator.validator
+	}
+	return validators
+}
+
+func validatorSetHash(t *testing.T, validators []*genesis.Validator) string {
+	validatorsHash, err := consensus.ValidatorSetHash(sortValidators(validators))
+	require.NoError(t, err)
+	return validatorsHash
+}
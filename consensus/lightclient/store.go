@@ -0,0 +1,61 @@
+package lightclient
+
+import (
+	"sync"
+
+	"github.com/pokt-network/pocket/shared/types"
+)
+
+// Stores the block headers verified by a `LightClient`, which services can rely on without running a full node
+// (e.g. to check that a state proof served by a full node is rooted in the app hash of a verified header).
+type HeaderStore interface {
+	Put(header *types.BlockHeader) error
+	Get(height int64) (*types.BlockHeader, error)
+	Latest() (*types.BlockHeader, error) // Returns `ErrNoVerifiedHeaders` if the store is empty
+}
+
+var _ HeaderStore = &memHeaderStore{}
+
+type memHeaderStore struct {
+	m       sync.RWMutex
+	headers map[int64]*types.BlockHeader
+	latest  *types.BlockHeader
+}
+
+func NewMemHeaderStore() HeaderStore {
+	return &memHeaderStore{
+		headers: make(map[int64]*types.BlockHeader),
+	}
+}
+
+func (s *memHeaderStore) Put(header *types.BlockHeader) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.headers[header.Height] = header
+	if s.latest == nil || header.Height > s.latest.Height {
+		s.latest = header
+	}
+	return nil
+}
+
+func (s *memHeaderStore) Get(height int64) (*types.BlockHeader, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	header, ok := s.headers[height]
+	if !ok {
+		return nil, ErrHeaderNotFound(height)
+	}
+	return header, nil
+}
+
+func (s *memHeaderStore) Latest() (*types.BlockHeader, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	if s.latest == nil {
+		return nil, ErrNoVerifiedHeaders
+	}
+	return s.latest, nil
+}
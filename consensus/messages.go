@@ -26,8 +26,15 @@ func CreateProposeMessage(
 		Round:         m.Round,
 		Block:         m.Block,
 		Justification: nil, // QC is set below if it is non-nil
+		TimeoutQc:     m.TimeoutQC,
 	}
 
+	leaderClaim, err := m.leaderElectionMod.CreateLeaderClaim(m.Height, m.Round)
+	if err != nil {
+		return nil, err
+	}
+	msg.LeaderClaim = leaderClaim
+
 	// TODO(olshansky): Add unit tests for this
 	if qc == nil && step != Prepare {
 		return nil, typesCons.ErrNilQCProposal
@@ -40,6 +47,10 @@ func CreateProposeMessage(
 		}
 	}
 
+	if err := m.signGuard.guard(m.Height, m.Round, step, m.Block.BlockHeader.Hash); err != nil {
+		return nil, err
+	}
+
 	return msg, nil
 }
 
@@ -61,6 +72,10 @@ func CreateVoteMessage(
 		Justification: nil, // signature is computed below
 	}
 
+	if err := m.signGuard.guard(m.Height, m.Round, step, block.BlockHeader.Hash); err != nil {
+		return nil, err
+	}
+
 	msg.Justification = &typesCons.HotstuffMessage_PartialSignature{
 		PartialSignature: &typesCons.PartialSignature{
 			Signature: getMessageSignature(msg, m.privateKey),
@@ -71,6 +86,27 @@ func CreateVoteMessage(
 	return msg, nil
 }
 
+// Returns a timeout for the node's current (height, round), which is signed so it can be aggregated into a TimeoutQC
+func CreateTimeoutMessage(m *consensusModule) (*typesCons.TimeoutMessage, error) {
+	bytesToSign, err := getTimeoutSignableBytes(m.Height, m.Round)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := m.privateKey.Sign(bytesToSign)
+	if err != nil {
+		return nil, err
+	}
+
+	return &typesCons.TimeoutMessage{
+		Height: m.Height,
+		Round:  m.Round,
+		PartialSignature: &typesCons.PartialSignature{
+			Signature: signature,
+			Address:   m.privateKey.PublicKey().Address().String(),
+		},
+	}, nil
+}
+
 // Returns a "partial" signature of the hotstuff message from one of the validators
 func getMessageSignature(m *typesCons.HotstuffMessage, privKey crypto.PrivateKey) []byte {
 	bytesToSign, err := getSignableBytes(m)
@@ -87,11 +123,25 @@ func getMessageSignature(m *typesCons.HotstuffMessage, privKey crypto.PrivateKey
 
 // Signature should only be over a subset of the fields in a HotstuffMessage
 func getSignableBytes(m *typesCons.HotstuffMessage) ([]byte, error) {
-	msgToSign := &typesCons.HotstuffMessage{
-		Height: m.Height,
-		Step:   m.Step,
-		Round:  m.Round,
-		Block:  m.Block,
+	return getVoteSignableBytes(m.Height, m.Step, m.Round, m.GetBlock().GetBlockHeader().GetHash())
+}
+
+// The block is identified by its hash, which commits to the rest of the block, so a QC remains verifiable
+// once the block is stripped from it.
+func getVoteSignableBytes(height uint64, step typesCons.HotstuffStep, round uint64, blockHash string) ([]byte, error) {
+	voteToSign := &typesCons.SignableVote{
+		Height:    height,
+		Round:     round,
+		Step:      step,
+		BlockHash: blockHash,
+	}
+	return proto.Marshal(voteToSign)
+}
+
+func getTimeoutSignableBytes(height, round uint64) ([]byte, error) {
+	timeoutToSign := &typesCons.SignableTimeout{
+		Height: height,
+		Round:  round,
 	}
-	return proto.Marshal(msgToSign)
+	return proto.Marshal(timeoutToSign)
 }
@@ -3,6 +3,8 @@ package consensus
 import (
 	"encoding/hex"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/pokt-network/pocket/shared/types"
 
@@ -29,32 +31,76 @@ type consensusModule struct {
 	privateKey cryptoPocket.Ed25519PrivateKey
 	consCfg    *config.ConsensusConfig
 
+	// Serializes the node's event loop and the pacemaker timer, which both drive the Hotstuff state machine
+	m sync.Mutex
+
 	// Hotstuff
 	Height uint64
 	Round  uint64
 	Step   typesCons.HotstuffStep
 	Block  *types.Block // The current block being voted on prior to committing to finality
 
-	HighPrepareQC *typesCons.QuorumCertificate // Highest QC for which replica voted PRECOMMIT
-	LockedQC      *typesCons.QuorumCertificate // Highest QC for which replica voted COMMIT
+	HighPrepareQC *typesCons.QuorumCertificate        // Highest QC for which replica voted PRECOMMIT
+	LockedQC      *typesCons.QuorumCertificate        // Highest QC for which replica voted COMMIT
+	TimeoutQC     *typesCons.TimeoutQuorumCertificate // Justifies the current round; nil in the first round of every height
 
 	// Leader Election
 	LeaderId       *typesCons.NodeId
 	NodeId         typesCons.NodeId
-	ValAddrToIdMap typesCons.ValAddrToIdMap // TODO(design): This needs to be updated every time the ValMap is modified
-	IdToValAddrMap typesCons.IdToValAddrMap // TODO(design): This needs to be updated every time the ValMap is modified
+	ValAddrToIdMap typesCons.ValAddrToIdMap // Recomputed every time the validator set is updated
+	IdToValAddrMap typesCons.IdToValAddrMap // Recomputed every time the validator set is updated
 
 	// Consensus State
-	appHash      string
-	validatorMap map[string]*genesis.Validator
+	appHash              string
+	lastBlockHash        string
+	lastCommitQC         *typesCons.QuorumCertificate // Stripped of its block; embedded in the header of the next block
+	validatorMap         map[string]*genesis.Validator
+	lastMissedValidators [][]byte                   // Validators that did not sign the COMMIT QC of the last committed block; a liveness fault, not a byzantine one
+	validatorUpdates     []*modules.ValidatorUpdate // Applied to the validator set once the block being voted on is committed
 
 	// Module Dependencies
 	utilityContext    modules.UtilityContext
 	paceMaker         Pacemaker
 	leaderElectionMod leader_election.LeaderElectionModule
+	thresholdSigner   ThresholdSigner
 
 	logPrefix   string                                                  // TODO(design): Remove later when we build a shared/proper/injected logger
 	MessagePool map[typesCons.HotstuffStep][]*typesCons.HotstuffMessage // TODO(design): Move this over to the persistence module or elsewhere?
+
+	messagePoolBytes map[typesCons.HotstuffStep]uint64    // The serialized size of the messages in the pool; bounded by `MaxMempoolBytes`
+	timeoutPool      map[string]*typesCons.TimeoutMessage // The latest timeout of every validator at the current height
+
+	reportedDoubleSigns map[doubleSignKey]struct{} // Equivocations already submitted as evidence, so each one is only reported once
+
+	// Block Sync
+	CommittedBlocks   map[uint64]*typesCons.CommittedBlock // The last `committedBlocksWindow` committed blocks
+	syncTargetHeight  uint64                               // The height the rest of the network is at while this node is catching up
+	syncRequestHeight uint64
+	syncRequestTime   time.Time
+
+	// Crash Recovery
+	wal          *consensusWAL              // Only set if the node has a root directory
+	blockStore   *blockStore                // Only set if the node has a root directory
+	walLastVote  *typesCons.HotstuffMessage // The last vote sent at the current height; resent once the module is started after a crash
+	walLastEntry *typesCons.WALEntry        // The last entry written to the WAL, so unchanged states are not written again
+	trace        *traceRecorder             // Only set if a trace file is specified in the consensus config
+	signGuard    *signGuard                 // Refuses to sign messages that conflict with the last signed state
+
+	// The blocks the node proposed in the trace it replays, keyed by the view of their PREPARE proposal; only set
+	// while a trace is replayed (see `ReplayTrace`)
+	replayedProposals map[consensusView]*types.Block
+
+	// Chained Hotstuff
+	pendingBlocks map[string]*types.Block // Certified or proposed blocks that are not committed yet, keyed by their hash
+
+	// Observability
+	publishedView      consensusView                 // The view of the node as of the last consensus event published
+	stateSnapshot      *types.ConsensusStateSnapshot // Refreshed every time the node is done handling a message
+	stateSnapshotMu    sync.Mutex
+	eventSubscribers   []chan<- *types.ConsensusEvent
+	eventSubscribersMu sync.Mutex
+	busEvents          chan *types.ConsensusEvent // Published on the bus by `forwardEventsToBus`; nil until the module is started
+	stopEvents         chan struct{}              // Closed once the module is stopped
 }
 
 func Create(cfg *config.Config) (modules.ConsensusModule, error) {
@@ -69,6 +115,11 @@ func Create(cfg *config.Config) (modules.ConsensusModule, error) {
 		return nil, err
 	}
 
+	thresholdSigner, err := CreateThresholdSigner(cfg.Consensus.ThresholdSignature)
+	if err != nil {
+		return nil, err
+	}
+
 	valMap := validatorListToMap(cfg.GenesisSource.GetState().Validators)
 
 	address := cfg.PrivateKey.Address().String()
@@ -86,30 +137,88 @@ func Create(cfg *config.Config) (modules.ConsensusModule, error) {
 
 		HighPrepareQC: nil,
 		LockedQC:      nil,
+		TimeoutQC:     nil,
 
 		NodeId:         valIdMap[address],
 		LeaderId:       nil,
 		ValAddrToIdMap: valIdMap,
 		IdToValAddrMap: idValMap,
 
-		appHash:      "",
-		validatorMap: valMap,
+		appHash:              "",
+		lastBlockHash:        "",
+		lastCommitQC:         nil,
+		validatorMap:         valMap,
+		lastMissedValidators: make([][]byte, 0),
 
 		utilityContext:    nil,
 		paceMaker:         paceMaker,
 		leaderElectionMod: leaderElectionMod,
+		thresholdSigner:   thresholdSigner,
 
 		logPrefix:   DefaultLogPrefix,
 		MessagePool: make(map[typesCons.HotstuffStep][]*typesCons.HotstuffMessage),
+
+		messagePoolBytes: make(map[typesCons.HotstuffStep]uint64),
+		timeoutPool:      make(map[string]*typesCons.TimeoutMessage),
+
+		reportedDoubleSigns: make(map[doubleSignKey]struct{}),
+
+		CommittedBlocks:   make(map[uint64]*typesCons.CommittedBlock),
+		syncTargetHeight:  0,
+		syncRequestHeight: 0,
+		syncRequestTime:   time.Time{},
+
+		pendingBlocks: make(map[string]*types.Block),
 	}
 
 	// TODO(olshansky): Look for a way to avoid doing this.
 	paceMaker.SetConsensusModule(m)
 
+	// The last signed state is locked first, so another node running with the same root directory is stopped before
+	// it touches any of the files of this one.
+	if m.signGuard, err = openSignGuard(cfg.RootDir, m.privateKey.Address().String()); err != nil {
+		return nil, err
+	}
+
+	// TODO(design): Consider moving the WAL over to the persistence module.
+	// The WAL and the block store do not record the blocks chained hotstuff did not commit yet, so a restarted chained
+	// validator only keeps its last signed state and rejoins the network from its last committed height.
+	if cfg.RootDir != "" && cfg.Consensus.HotstuffMode != config.ChainedHotstuff {
+		if m.wal, err = openWAL(cfg.RootDir); err != nil {
+			return nil, err
+		}
+		if m.blockStore, err = openBlockStore(cfg.RootDir); err != nil {
+			return nil, err
+		}
+		if err := m.restoreFromWAL(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Consensus.TraceFile != "" {
+		if m.trace, err = openTraceRecorder(cfg.Consensus.TraceFile); err != nil {
+			return nil, err
+		}
+	}
+
+	m.publishedView = m.currentView()
+	m.refreshStateSnapshot()
+
 	return m, nil
 }
 
 func (m *consensusModule) Start() error {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.busEvents = make(chan *types.ConsensusEvent, consensusEventsBufferSize)
+	m.stopEvents = make(chan struct{})
+	go m.forwardEventsToBus(m.busEvents, m.stopEvents)
+
+	if err := m.replayWAL(); err != nil {
+		return err
+	}
+
 	if err := m.paceMaker.Start(); err != nil {
 		return err
 	}
@@ -122,6 +231,20 @@ func (m *consensusModule) Start() error {
 }
 
 func (m *consensusModule) Stop() error {
+	if m.stopEvents != nil {
+		close(m.stopEvents)
+	}
+	if m.trace != nil {
+		if err := m.trace.close(); err != nil {
+			return err
+		}
+	}
+	if err := m.signGuard.close(); err != nil {
+		return err
+	}
+	if m.wal != nil {
+		return m.wal.close()
+	}
 	return nil
 }
 
@@ -166,6 +289,11 @@ type HotstuffMessageHandler interface {
 }
 
 func (m *consensusModule) HandleMessage(message *anypb.Any) error {
+	m.m.Lock()
+	defer m.m.Unlock()
+	defer m.publishViewChange()
+	m.traceInbound(message)
+
 	switch message.MessageName() {
 	case HotstuffMessage:
 		var hotstuffMessage typesCons.HotstuffMessage
@@ -174,8 +302,34 @@ func (m *consensusModule) HandleMessage(message *anypb.Any) error {
 			return err
 		}
 		m.handleHotstuffMessage(&hotstuffMessage)
+	case BlockSyncRequestMessage:
+		var blockSyncRequest typesCons.BlockSyncRequest
+		err := anypb.UnmarshalTo(message, &blockSyncRequest, proto.UnmarshalOptions{})
+		if err != nil {
+			return err
+		}
+		m.handleBlockSyncRequest(&blockSyncRequest)
+	case BlockSyncResponseMessage:
+		var blockSyncResponse typesCons.BlockSyncResponse
+		err := anypb.UnmarshalTo(message, &blockSyncResponse, proto.UnmarshalOptions{})
+		if err != nil {
+			return err
+		}
+		m.handleBlockSyncResponse(&blockSyncResponse)
 	case UtilityMessage:
-		panic("[WARN] UtilityMessage handling is not implemented by consensus yet...")
+		var utilityMessage typesCons.UtilityMessage
+		err := anypb.UnmarshalTo(message, &utilityMessage, proto.UnmarshalOptions{})
+		if err != nil {
+			return err
+		}
+		m.handleUtilityMessage(&utilityMessage)
+	case TimeoutMessage:
+		var timeoutMessage typesCons.TimeoutMessage
+		err := anypb.UnmarshalTo(message, &timeoutMessage, proto.UnmarshalOptions{})
+		if err != nil {
+			return err
+		}
+		m.handleTimeoutMessage(&timeoutMessage)
 	default:
 		return typesCons.ErrUnknownConsensusMessageType(message.MessageName())
 	}
@@ -186,6 +340,11 @@ func (m *consensusModule) HandleMessage(message *anypb.Any) error {
 func (m *consensusModule) handleHotstuffMessage(msg *typesCons.HotstuffMessage) {
 	m.nodeLog(typesCons.DebugHandlingHotstuffMessage(msg))
 
+	if m.isChainedHotstuff() {
+		m.handleChainedHotstuffMessage(msg)
+		return
+	}
+
 	// Liveness & safety checks
 	if err := m.paceMaker.ValidateMessage(msg); err != nil {
 		// If a replica is not a leader for this round, but has already determined a leader,
@@ -198,7 +357,14 @@ func (m *consensusModule) handleHotstuffMessage(msg *typesCons.HotstuffMessage)
 	}
 
 	// Need to execute leader election if there is no leader and we are in a new round.
-	if m.Step == NewRound && m.LeaderId == nil {
+	// Leader claims carried by NEWROUND messages may elect a higher ranked leader until the node leaves the step.
+	if m.Step == NewRound && (m.LeaderId == nil || msg.GetLeaderClaim() != nil) {
+		m.electNextLeader(msg)
+	}
+
+	// A node that elected itself may be outranked by the leader claim of a proposal from another validator, in
+	// which case it handles the proposal as a replica.
+	if m.isLeader() && msg.Type == Propose && msg.Step != NewRound && m.isOtherLeaderClaim(msg) {
 		m.electNextLeader(msg)
 	}
 
@@ -215,6 +381,10 @@ func (m *consensusModule) AppHash() string {
 	return m.appHash
 }
 
+func (m *consensusModule) BlockHash() string {
+	return m.lastBlockHash
+}
+
 func (m *consensusModule) BlockHeight() uint64 {
 	return m.Height
 }
@@ -3,6 +3,8 @@ package consensus
 import (
 	"context"
 	"log"
+	"math"
+	"math/rand"
 	"time"
 
 	typesCons "github.com/pokt-network/pocket/consensus/types"
@@ -24,6 +26,7 @@ type Pacemaker interface {
 	RestartTimer()
 	NewHeight()
 	InterruptRound()
+	AdvanceRound(timeoutQC *typesCons.TimeoutQuorumCertificate)
 }
 
 var _ modules.Module = &paceMaker{}
@@ -41,6 +44,7 @@ type paceMaker struct {
 	pacemakerConfigs *config.PacemakerConfig
 
 	stepCancelFunc context.CancelFunc
+	stepCtx        context.Context // The timer of the current step; timers of previous steps that fire late are ignored
 
 	// Only used for development and debugging.
 	paceMakerDebug
@@ -54,6 +58,7 @@ func CreatePacemaker(cfg *config.Config) (m *paceMaker, err error) {
 		pacemakerConfigs: cfg.Consensus.Pacemaker,
 
 		stepCancelFunc: nil, // Only set on restarts
+		stepCtx:        nil,
 
 		paceMakerDebug: paceMakerDebug{
 			manualMode:                cfg.Consensus.Pacemaker.Manual,
@@ -94,12 +99,13 @@ func (p *paceMaker) ValidateMessage(m *typesCons.HotstuffMessage) error {
 
 	// Current node is out of sync
 	if m.Height > p.consensusMod.Height {
-		// TODO(design): Need to restart state sync
+		p.consensusMod.handleFutureMessage(m)
 		return typesCons.ErrPacemakerUnexpectedMessageHeight(typesCons.ErrFutureMessage, p.consensusMod.Height, m.Height)
 	}
 
-	// Do not handle messages if it is a self proposal
-	if p.consensusMod.isLeader() && m.Type == Propose && m.Step != NewRound {
+	// Do not handle messages if it is a self proposal. With VRF sortition, a proposal carrying another validator's
+	// leader claim is not one, since this node may have elected itself before receiving a higher ranked claim.
+	if p.consensusMod.isLeader() && m.Type == Propose && m.Step != NewRound && !p.consensusMod.isOtherLeaderClaim(m) {
 		// TODO(olshansky): This code branch is a result of the optimization in the leader
 		// handlers. Since the leader also acts as a replica but doesn't use the replica's
 		// handlers given the current implementation, it is safe to drop proposal that the leader made to itself.
@@ -118,9 +124,27 @@ func (p *paceMaker) ValidateMessage(m *typesCons.HotstuffMessage) error {
 
 	// Pacemaker catch up! Node is synched to the right height, but on a previous step/round so we just jump to the latest state.
 	if m.Round > p.consensusMod.Round || (m.Round == p.consensusMod.Round && m.Step > p.consensusMod.Step) {
+		// A later round can only be entered once enough validators timed out in the round before it, which
+		// the TimeoutQC attached to the message proves.
+		if m.Round > p.consensusMod.Round {
+			if err := p.consensusMod.validateTimeoutQuorumCertificate(m.TimeoutQc, m.Height, m.Round-1); err != nil {
+				return err
+			}
+
+			// The replica joins the new round like the validators that formed the TimeoutQC did, otherwise the
+			// leader of the round may never gather enough NewRound messages to propose.
+			if m.Step == NewRound {
+				p.AdvanceRound(m.TimeoutQc)
+				p.consensusMod.electNextLeader(m)
+				return nil
+			}
+			p.consensusMod.TimeoutQC = m.TimeoutQc
+		}
+
 		p.consensusMod.nodeLog(typesCons.PacemakerCatchup(p.consensusMod.Height, uint64(p.consensusMod.Step), p.consensusMod.Round, m.Height, uint64(m.Step), m.Round))
 		p.consensusMod.Step = m.Step
 		p.consensusMod.Round = m.Round
+		p.consensusMod.writeStateToWAL()
 
 		// TODO(olshansky): Add tests for this. When we catch up to a later step, the leader is still the same.
 		// However, when we catch up to a later round, the leader at the same height will be different.
@@ -140,17 +164,23 @@ func (p *paceMaker) RestartTimer() {
 	}
 	p.debugSleep()
 
+	// Steps do not time out in manual mode; views are only driven by debug messages instead.
+	if p.manualMode {
+		p.stepCtx = nil
+		return
+	}
+
 	// NOTE: Not defering a cancel call because this function is asynchronous.
 	stepTimeout := p.getStepTimeout(p.consensusMod.Round)
 	ctx, cancel := context.WithTimeout(context.TODO(), stepTimeout)
 	p.stepCancelFunc = cancel
+	p.stepCtx = ctx
 
 	go func() {
 		select {
 		case <-ctx.Done():
 			if ctx.Err() == context.DeadlineExceeded {
-				p.consensusMod.nodeLog(typesCons.PacemakerTimeout(p.consensusMod.Height, p.consensusMod.Step, p.consensusMod.Round))
-				p.InterruptRound()
+				p.handleStepTimeout(ctx)
 			}
 		case <-time.After(stepTimeout + 30*time.Millisecond): // Adding 30ms to the context timeout to avoid race condition.
 			return
@@ -158,11 +188,51 @@ func (p *paceMaker) RestartTimer() {
 	}()
 }
 
+// The timer fires alongside the node's event loop, so the state machine is only driven once the event loop is
+// not handling a message, and only if the step the timer was started for did not end in the meantime.
+func (p *paceMaker) handleStepTimeout(stepCtx context.Context) {
+	p.consensusMod.m.Lock()
+	defer p.consensusMod.m.Unlock()
+
+	if p.stepCtx != stepCtx {
+		return
+	}
+
+	p.consensusMod.nodeLog(typesCons.PacemakerTimeout(p.consensusMod.Height, p.consensusMod.Step, p.consensusMod.Round))
+	p.consensusMod.tracePacemakerTimeout()
+	p.InterruptRound()
+	p.consensusMod.publishViewChange()
+}
+
+// Signs and broadcasts a timeout for the current round. The node only leaves the round once the timeouts of
+// enough validators form a TimeoutQC (see `AdvanceRound`), so a single node's local clock cannot advance it.
 func (p *paceMaker) InterruptRound() {
 	p.consensusMod.nodeLog(typesCons.PacemakerInterrupt(p.consensusMod.Height, p.consensusMod.Step, p.consensusMod.Round))
+	p.consensusMod.publishTimeout()
+	p.consensusMod.writeStateToWAL()
 
-	p.consensusMod.Round++
-	p.startNextView(p.consensusMod.HighPrepareQC, false)
+	timeoutMessage, err := CreateTimeoutMessage(p.consensusMod)
+	if err != nil {
+		p.consensusMod.nodeLogError(typesCons.ErrCreateTimeoutMessage.Error(), err)
+		return
+	}
+
+	// The timeout is sent again if the round is still not over once the timer expires.
+	p.RestartTimer()
+	p.consensusMod.broadcastTimeout(timeoutMessage)
+	p.consensusMod.handleTimeoutMessage(timeoutMessage)
+}
+
+// Enters the round following the one certified by `timeoutQC`, which is attached to the messages of the new
+// round so lagging replicas can verify why the rest of the network moved on.
+func (p *paceMaker) AdvanceRound(timeoutQC *typesCons.TimeoutQuorumCertificate) {
+	p.consensusMod.nodeLog(typesCons.PacemakerNewRound(timeoutQC.Height, timeoutQC.Round+1))
+
+	p.consensusMod.Round = timeoutQC.Round + 1
+	p.consensusMod.TimeoutQC = timeoutQC
+
+	// The round is justified by the TimeoutQC itself, so it is not held back in manual mode.
+	p.startNextView(p.consensusMod.HighPrepareQC, true)
 }
 
 func (p *paceMaker) NewHeight() {
@@ -174,8 +244,10 @@ func (p *paceMaker) NewHeight() {
 
 	p.consensusMod.HighPrepareQC = nil
 	p.consensusMod.LockedQC = nil
+	p.consensusMod.TimeoutQC = nil
+	p.consensusMod.clearTimeoutPool()
 
-	p.startNextView(nil, false) // TODO(design): We are omitting CommitQC and TimeoutQC here.
+	p.startNextView(nil, false) // TODO(design): We are omitting the CommitQC here.
 }
 
 func (p *paceMaker) startNextView(qc *typesCons.QuorumCertificate, forceNextView bool) {
@@ -186,8 +258,11 @@ func (p *paceMaker) startNextView(qc *typesCons.QuorumCertificate, forceNextView
 	// TODO(olshansky): This if structure for debug purposes only; think of a way to externalize it...
 	if p.manualMode && !forceNextView {
 		p.quorumCertificate = qc
+		p.nextViewPending = true
+		p.consensusMod.writeStateToWAL()
 		return
 	}
+	p.nextViewPending = false
 
 	hotstuffMessage := &typesCons.HotstuffMessage{
 		Type:          Propose,
@@ -196,6 +271,7 @@ func (p *paceMaker) startNextView(qc *typesCons.QuorumCertificate, forceNextView
 		Round:         p.consensusMod.Round,
 		Block:         nil,
 		Justification: nil, // Set below if qc is not nil
+		TimeoutQc:     p.consensusMod.TimeoutQC,
 	}
 
 	if qc != nil {
@@ -204,12 +280,31 @@ func (p *paceMaker) startNextView(qc *typesCons.QuorumCertificate, forceNextView
 		}
 	}
 
+	leaderClaim, err := p.consensusMod.leaderElectionMod.CreateLeaderClaim(p.consensusMod.Height, p.consensusMod.Round)
+	if err != nil {
+		p.consensusMod.nodeLogError(typesCons.ErrCreateLeaderClaim.Error(), err)
+	}
+	hotstuffMessage.LeaderClaim = leaderClaim
+
 	p.RestartTimer()
 	p.consensusMod.broadcastToNodes(hotstuffMessage)
 }
 
-// TODO(olshansky): Increase timeout using exponential backoff.
+// The timeout grows exponentially with every round so slow leaders are eventually given enough time to
+// propose. Since the round is reset on `NewHeight`, so is the timeout.
 func (p *paceMaker) getStepTimeout(round uint64) time.Duration {
-	baseTimeout := time.Duration(int64(time.Millisecond) * int64(p.pacemakerConfigs.TimeoutMsec))
-	return baseTimeout
+	baseTimeout := float64(p.pacemakerConfigs.TimeoutMsec)
+	maxTimeout := float64(p.pacemakerConfigs.TimeoutMaxMsec)
+
+	timeoutMsec := baseTimeout * math.Pow(p.pacemakerConfigs.TimeoutMultiplier, float64(round))
+
+	// Jitter avoids validators that timed out together from also starting the next view in lockstep.
+	if jitter := p.pacemakerConfigs.TimeoutJitter; jitter > 0 {
+		timeoutMsec += timeoutMsec * jitter * rand.Float64()
+	}
+
+	// The timeout is clamped last so the jitter cannot push it past `TimeoutMaxMsec`.
+	timeoutMsec = math.Min(timeoutMsec, maxTimeout)
+
+	return time.Duration(timeoutMsec * float64(time.Millisecond))
 }
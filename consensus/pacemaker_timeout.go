@@ -0,0 +1,134 @@
+package consensus
+
+import (
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+)
+
+// Aggregates a timeout from any validator, including this node, and advances the round as soon as the timeouts
+// for a round the node has not left yet form a TimeoutQC.
+func (m *consensusModule) handleTimeoutMessage(msg *typesCons.TimeoutMessage) {
+	if err := m.validateTimeoutMessage(msg); err != nil {
+		m.nodeLog(typesCons.WarnDiscardTimeoutMessage(msg, err.Error()))
+		return
+	}
+
+	// Only the latest timeout of every validator is kept, which bounds the size of the pool by the size of the
+	// validator set. Older timeouts are superseded since honest validators only ever move to later rounds.
+	address := msg.PartialSignature.Address
+	if prevMsg, ok := m.timeoutPool[address]; ok && prevMsg.Round >= msg.Round {
+		return
+	}
+	m.timeoutPool[address] = msg
+
+	timeoutQC, err := m.getTimeoutQuorumCertificate(msg.Height, msg.Round)
+	if err != nil {
+		m.nodeLog(typesCons.OptimisticTimeoutCountWaiting(msg.Round, err.Error()))
+		return
+	}
+
+	m.paceMaker.AdvanceRound(timeoutQC)
+}
+
+func (m *consensusModule) validateTimeoutMessage(msg *typesCons.TimeoutMessage) error {
+	if msg.Height < m.Height {
+		return typesCons.ErrPacemakerUnexpectedMessageHeight(typesCons.ErrOlderMessage, m.Height, msg.Height)
+	}
+
+	// DISCUSS: Timeouts from a future height do not trigger state sync since they do not prove anything was committed.
+	if msg.Height > m.Height {
+		return typesCons.ErrPacemakerUnexpectedMessageHeight(typesCons.ErrFutureMessage, m.Height, msg.Height)
+	}
+
+	if msg.Round < m.Round {
+		return typesCons.ErrOlderTimeoutRound(m.Round, msg.Round)
+	}
+
+	partialSig := msg.GetPartialSignature()
+	if partialSig == nil || partialSig.Signature == nil || len(partialSig.Address) == 0 {
+		return typesCons.ErrNilPartialSigOrSourceNotSpecified
+	}
+
+	validator, ok := m.validatorMap[partialSig.Address]
+	if !ok {
+		return typesCons.ErrMissingValidator(partialSig.Address, m.ValAddrToIdMap[partialSig.Address])
+	}
+	pubKey, err := cryptoPocket.NewPublicKeyFromBytes(validator.PublicKey)
+	if err != nil {
+		return err
+	}
+	bytesToVerify, err := getTimeoutSignableBytes(msg.Height, msg.Round)
+	if err != nil {
+		return err
+	}
+	if !pubKey.Verify(bytesToVerify, partialSig.Signature) {
+		return typesCons.ErrInvalidTimeoutSig(partialSig.Address, m.ValAddrToIdMap[partialSig.Address])
+	}
+
+	return nil
+}
+
+func (m *consensusModule) getTimeoutQuorumCertificate(height, round uint64) (*typesCons.TimeoutQuorumCertificate, error) {
+	var pss []*typesCons.PartialSignature
+	signers := make(map[string]struct{}, len(m.timeoutPool))
+	for address, msg := range m.timeoutPool {
+		if msg.Height != height || msg.Round != round {
+			continue
+		}
+		signers[address] = struct{}{}
+		pss = append(pss, msg.PartialSignature)
+	}
+
+	if err := m.isOptimisticThresholdMet(len(pss)); err != nil {
+		return nil, err
+	}
+
+	if err := m.isStakeThresholdMet(signers); err != nil {
+		return nil, err
+	}
+
+	bytesSigned, err := getTimeoutSignableBytes(height, round)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdSig, err := m.thresholdSigner.Aggregate(m.getValidatorList(), bytesSigned, pss)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutQC := &typesCons.TimeoutQuorumCertificate{
+		Height:             height,
+		Round:              round,
+		ThresholdSignature: thresholdSig,
+	}
+	m.publishQCFormed(m.summarizeTimeoutQC(timeoutQC))
+
+	return timeoutQC, nil
+}
+
+// Verifies that `timeoutQC` proves that enough validators timed out at (height, round).
+func (m *consensusModule) validateTimeoutQuorumCertificate(timeoutQC *typesCons.TimeoutQuorumCertificate, height, round uint64) error {
+	if timeoutQC == nil {
+		return typesCons.ErrNilTimeoutQC
+	}
+
+	if timeoutQC.Height != height || timeoutQC.Round != round {
+		return typesCons.ErrTimeoutQCHeightRoundMismatch(timeoutQC.Height, timeoutQC.Round, height, round)
+	}
+
+	if timeoutQC.ThresholdSignature == nil {
+		return typesCons.ErrNilThresholdSigInQC
+	}
+
+	bytesToVerify, err := getTimeoutSignableBytes(timeoutQC.Height, timeoutQC.Round)
+	if err != nil {
+		return err
+	}
+
+	return m.validateThresholdSignature(timeoutQC.ThresholdSignature, bytesToVerify)
+}
+
+func (m *consensusModule) clearTimeoutPool() {
+	m.timeoutPool = make(map[string]*typesCons.TimeoutMessage)
+}
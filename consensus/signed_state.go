@@ -0,0 +1,228 @@
+package consensus
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	lastSignedStateFileName     = "last_signed_state.json"
+	lastSignedStateLockFileName = "last_signed_state.lock"
+)
+
+// Keeps track of the last (height, round, step) the validator signed a consensus message at, and refuses to sign
+// anything that conflicts with it: a message from an earlier (height, round, step), or one for another block at the
+// same (height, round, step). The state is persisted in the node's root directory before the message is signed so
+// a restarted validator cannot be tricked into double signing; without a root directory it is only kept in memory.
+// The state is locked for as long as the guard is open, so two processes running with the same root directory
+// cannot sign concurrently.
+type signGuard struct {
+	path       string   // Empty if the state is not persisted
+	lockFile   *os.File // nil if the state is not persisted
+	address    string
+	lastSigned *typesCons.LastSignedState // nil until the validator signs its first message
+}
+
+func openSignGuard(rootDir, address string) (*signGuard, error) {
+	guard := &signGuard{
+		address: address,
+	}
+	if rootDir == "" {
+		return guard, nil
+	}
+
+	lockFile, err := lockLastSignedState(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	guard.path = filepath.Join(rootDir, lastSignedStateFileName)
+	lastSigned, err := readLastSignedState(guard.path, address)
+	if err != nil {
+		lockFile.Close()
+		return nil, err
+	}
+	guard.lockFile = lockFile
+	guard.lastSigned = lastSigned
+
+	return guard, nil
+}
+
+// Releases the lock on the last signed state, after which the guard must not be used anymore.
+func (g *signGuard) close() error {
+	if g.lockFile == nil {
+		return nil
+	}
+	return g.lockFile.Close()
+}
+
+// Durably records that the validator is about to sign a message for `blockHash` at (`height`, `round`, `step`),
+// unless it conflicts with the last signed state. Signing the exact same data again is allowed (e.g. the leader
+// voting for its own proposal, or resending a message).
+func (g *signGuard) guard(height, round uint64, step typesCons.HotstuffStep, blockHash string) error {
+	if g.lastSigned != nil {
+		switch compareHeightRoundStep(height, round, step, g.lastSigned) {
+		case -1:
+			return typesCons.ErrConflictingSignature(height, round, step, blockHash, g.lastSigned)
+		case 0:
+			if blockHash != g.lastSigned.BlockHash {
+				return typesCons.ErrConflictingSignature(height, round, step, blockHash, g.lastSigned)
+			}
+			return nil
+		}
+	}
+
+	lastSigned := &typesCons.LastSignedState{
+		Address:   g.address,
+		Height:    height,
+		Round:     round,
+		Step:      step,
+		BlockHash: blockHash,
+	}
+	if g.path != "" {
+		bz, err := protojson.Marshal(lastSigned)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomically(g.path, bz); err != nil {
+			return err
+		}
+	}
+	g.lastSigned = lastSigned
+
+	return nil
+}
+
+// Forgets the last signed state. Only used when the node is reset to genesis for debugging.
+func (g *signGuard) reset() error {
+	g.lastSigned = nil
+	if g.path == "" {
+		return nil
+	}
+	if err := os.Remove(g.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Returns the last signed state persisted in `rootDir` in its JSON interchange format, so it can be imported on
+// another machine with `ImportLastSignedState` before the validator is started there.
+func ExportLastSignedState(rootDir string) ([]byte, error) {
+	path := filepath.Join(rootDir, lastSignedStateFileName)
+	lastSigned, err := readLastSignedState(path, "")
+	if err != nil {
+		return nil, err
+	}
+	if lastSigned == nil {
+		lastSigned = &typesCons.LastSignedState{}
+	}
+	return protojson.Marshal(lastSigned)
+}
+
+// Imports the exported last signed state of the validator with the hex encoded `address` into `rootDir`. If a state
+// is already persisted there, the latest of the two is kept so importing can never allow a conflicting signature.
+func ImportLastSignedState(rootDir, address string, bz []byte) error {
+	imported := &typesCons.LastSignedState{}
+	if err := protojson.Unmarshal(bz, imported); err != nil {
+		return err
+	}
+	if imported.Address != "" && imported.Address != address {
+		return typesCons.ErrLastSignedStateAddressMismatch(imported.Address, address)
+	}
+	imported.Address = address
+
+	// Importing into the root directory of a running validator could roll back the state it relies on
+	lockFile, err := lockLastSignedState(rootDir)
+	if err != nil {
+		return err
+	}
+	defer lockFile.Close()
+
+	path := filepath.Join(rootDir, lastSignedStateFileName)
+	lastSigned, err := readLastSignedState(path, address)
+	if err != nil {
+		return err
+	}
+	if lastSigned != nil {
+		switch compareHeightRoundStep(imported.Height, imported.Round, imported.Step, lastSigned) {
+		case -1:
+			return nil
+		case 0:
+			if imported.BlockHash != lastSigned.BlockHash {
+				return typesCons.ErrConflictingSignature(imported.Height, imported.Round, imported.Step, imported.BlockHash, lastSigned)
+			}
+			return nil
+		}
+	}
+
+	importedBz, err := protojson.Marshal(imported)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(path, importedBz)
+}
+
+// Takes an exclusive lock on the last signed state in `rootDir`, which is held until the returned file is closed.
+// The state file is replaced atomically on every write, so the lock is taken on a separate file that is never replaced.
+func lockLastSignedState(rootDir string) (*os.File, error) {
+	path := filepath.Join(rootDir, lastSignedStateLockFileName)
+	lockFile, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		lockFile.Close()
+		return nil, typesCons.ErrLastSignedStateLocked(path, err)
+	}
+	return lockFile, nil
+}
+
+// Returns nil if nothing was persisted at `path`. The address of the persisted state is only checked if `address`
+// is not empty.
+func readLastSignedState(path, address string) (*typesCons.LastSignedState, error) {
+	bz, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lastSigned := &typesCons.LastSignedState{}
+	if err := protojson.Unmarshal(bz, lastSigned); err != nil {
+		return nil, err
+	}
+	if address != "" && lastSigned.Address != address {
+		return nil, typesCons.ErrLastSignedStateAddressMismatch(lastSigned.Address, address)
+	}
+	return lastSigned, nil
+}
+
+// Returns -1, 0 or 1 if (`height`, `round`, `step`) is respectively before, the same as or after that of `lastSigned`.
+func compareHeightRoundStep(height, round uint64, step typesCons.HotstuffStep, lastSigned *typesCons.LastSignedState) int {
+	switch {
+	case height != lastSigned.Height:
+		return compareUint64(height, lastSigned.Height)
+	case round != lastSigned.Round:
+		return compareUint64(round, lastSigned.Round)
+	default:
+		return compareUint64(uint64(step), uint64(lastSigned.Step))
+	}
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,365 @@
+package consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+	"sort"
+
+	"filippo.io/edwards25519"
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+)
+
+// ThresholdSigner aggregates the partial signatures of the validators that signed the same bytes (i.e. a vote or
+// a timeout) into the threshold signature of a QC, and verifies it. `validators` is the validator set sorted by
+// address that the QC is formed and verified against; the signer bitmap of a threshold signature refers to the
+// indices of the validators in it.
+type ThresholdSigner interface {
+	// Aggregates `partialSigs`, which must be valid signatures over `bytesSigned` by distinct validators.
+	Aggregate(validators []*genesis.Validator, bytesSigned []byte, partialSigs []*typesCons.PartialSignature) (*typesCons.ThresholdSignature, error)
+	// Verifies that `thresholdSig` is only made of valid signatures over `bytesToVerify` by distinct validators.
+	// Whether the signers meet the byzantine thresholds is left to the caller.
+	Verify(validators []*genesis.Validator, bytesToVerify []byte, thresholdSig *typesCons.ThresholdSignature) error
+	// Returns the validators whose partial signature is part of `thresholdSig`.
+	SignerBitmap(validators []*genesis.Validator, thresholdSig *typesCons.ThresholdSignature) (SignerBitmap, error)
+}
+
+var _ ThresholdSigner = &partialSignatureListSigner{}
+var _ ThresholdSigner = &aggregateSigner{}
+
+func CreateThresholdSigner(scheme config.ThresholdSignatureScheme) (ThresholdSigner, error) {
+	switch scheme {
+	case config.PartialSignatureListScheme:
+		return &partialSignatureListSigner{}, nil
+	case config.AggregateSignatureScheme:
+		return &aggregateSigner{}, nil
+	default:
+		return nil, typesCons.ErrUnknownThresholdSignatureScheme(string(scheme))
+	}
+}
+
+// Verifies that the threshold signature of `qc` is over its height, round, step and block hash, and that its signers
+// control more than `ByzantineThreshold` of both the validators in `validators` (sorted by address) and their stake.
+// Returns the signers of the QC.
+func VerifyQuorumCertificateSignature(signer ThresholdSigner, validators []*genesis.Validator, qc *typesCons.QuorumCertificate) (SignerBitmap, error) {
+	if qc.ThresholdSignature == nil {
+		return nil, typesCons.ErrNilThresholdSigInQC
+	}
+
+	// Every partial signature is verified over the same signable bytes of the QC, so we only serialize them once.
+	bytesToVerify, err := getVoteSignableBytes(qc.Height, qc.Step, qc.Round, qc.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyThresholdSignature(signer, validators, qc.ThresholdSignature, bytesToVerify)
+}
+
+func verifyThresholdSignature(signer ThresholdSigner, validators []*genesis.Validator, thresholdSig *typesCons.ThresholdSignature, bytesToVerify []byte) (SignerBitmap, error) {
+	if err := signer.Verify(validators, bytesToVerify, thresholdSig); err != nil {
+		return nil, err
+	}
+
+	signerBitmap, err := signer.SignerBitmap(validators, thresholdSig)
+	if err != nil {
+		return nil, err
+	}
+	signers := signerBitmap.Signers(validators)
+
+	if err := isOptimisticThresholdMet(len(signers), len(validators)); err != nil {
+		return nil, err
+	}
+
+	if err := isStakeThresholdMet(signers, validators); err != nil {
+		return nil, err
+	}
+
+	return signerBitmap, nil
+}
+
+// Bit `i` (starting with the least significant bit of the first byte) is set if the validator at index `i` of the
+// validator set signed.
+type SignerBitmap []byte
+
+func NewSignerBitmap(numValidators int) SignerBitmap {
+	return make(SignerBitmap, (numValidators+7)/8)
+}
+
+func (b SignerBitmap) Set(i int) {
+	b[i/8] |= 1 << (i % 8)
+}
+
+func (b SignerBitmap) IsSet(i int) bool {
+	return i/8 < len(b) && b[i/8]&(1<<(i%8)) != 0
+}
+
+func (b SignerBitmap) Count() (count int) {
+	for _, bz := range b {
+		count += bits.OnesCount8(bz)
+	}
+	return
+}
+
+// Returns the hex encoded addresses of the signers amongst `validators`.
+func (b SignerBitmap) Signers(validators []*genesis.Validator) map[string]struct{} {
+	signers := make(map[string]struct{}, b.Count())
+	for i, validator := range validators {
+		if b.IsSet(i) {
+			signers[hex.EncodeToString(validator.Address)] = struct{}{}
+		}
+	}
+	return signers
+}
+
+// Only valid if it is exactly large enough for `numValidators` and has no bits set past the last validator.
+func (b SignerBitmap) validate(numValidators int) error {
+	if len(b) != (numValidators+7)/8 {
+		return typesCons.ErrInvalidSignerBitmap(len(b), numValidators)
+	}
+	if numValidators%8 != 0 && b[len(b)-1]>>(numValidators%8) != 0 {
+		return typesCons.ErrInvalidSignerBitmap(len(b), numValidators)
+	}
+	return nil
+}
+
+func getValidatorIndices(validators []*genesis.Validator) map[string]int {
+	indices := make(map[string]int, len(validators))
+	for i, validator := range validators {
+		indices[hex.EncodeToString(validator.Address)] = i
+	}
+	return indices
+}
+
+/*** Partial Signature List ***/
+
+// Mimics a threshold signature by including every partial signature in the QC alongside the address of its signer,
+// so the size of a QC grows by the size of a signature and an address with every signer.
+type partialSignatureListSigner struct{}
+
+func (s *partialSignatureListSigner) Aggregate(_ []*genesis.Validator, _ []byte, partialSigs []*typesCons.PartialSignature) (*typesCons.ThresholdSignature, error) {
+	thresholdSig := new(typesCons.ThresholdSignature)
+	thresholdSig.Signatures = make([]*typesCons.PartialSignature, len(partialSigs))
+	copy(thresholdSig.Signatures, partialSigs)
+	return thresholdSig, nil
+}
+
+func (s *partialSignatureListSigner) Verify(validators []*genesis.Validator, bytesToVerify []byte, thresholdSig *typesCons.ThresholdSignature) error {
+	if len(thresholdSig.Signatures) == 0 {
+		return typesCons.ErrNilThresholdSigInQC
+	}
+
+	// A single invalid, unknown or duplicate signer invalidates the whole QC since an honest leader would
+	// never include it; otherwise, a byzantine leader could pad the QC to artificially meet the threshold.
+	indices := getValidatorIndices(validators)
+	signers := NewSignerBitmap(len(validators))
+	for _, partialSig := range thresholdSig.Signatures {
+		address := partialSig.Address
+		i, ok := indices[address]
+		if !ok {
+			return typesCons.ErrMissingValidator(address, 0)
+		}
+		if signers.IsSet(i) {
+			return typesCons.ErrDuplicateSignerInQC(address, typesCons.NodeId(i+1))
+		}
+		pubKey, err := cryptoPocket.NewPublicKeyFromBytes(validators[i].PublicKey)
+		if err != nil {
+			return err
+		}
+		if !pubKey.Verify(bytesToVerify, partialSig.Signature) {
+			return typesCons.ErrInvalidPartialSigInQC(address, typesCons.NodeId(i+1))
+		}
+		signers.Set(i)
+	}
+
+	return nil
+}
+
+func (s *partialSignatureListSigner) SignerBitmap(validators []*genesis.Validator, thresholdSig *typesCons.ThresholdSignature) (SignerBitmap, error) {
+	indices := getValidatorIndices(validators)
+	signers := NewSignerBitmap(len(validators))
+	for _, partialSig := range thresholdSig.Signatures {
+		i, ok := indices[partialSig.Address]
+		if !ok {
+			return nil, typesCons.ErrMissingValidator(partialSig.Address, 0)
+		}
+		signers.Set(i)
+	}
+	return signers, nil
+}
+
+/*** Aggregate Signature ***/
+
+// Half-aggregates the ed25519 partial signatures (R_i, s_i) of the signers into (R_1, ..., R_n, s) where
+// s = z_1*s_1 + ... + z_n*s_n, and identifies the signers with a bitmap of the validator set rather than their
+// addresses. A QC only grows by the 32 bytes of R_i and a bit with every signer, about a third of the size taken by
+// the partial signature list. The coefficients z_i are derived from the whole aggregate so the partial signatures
+// cannot be chosen to cancel each other out.
+// TODO(research): A pairing based scheme (e.g. BLS) would make the size of a QC independent of the number of
+// signers, but requires validators to hold a second key pair; it can be added as another `ThresholdSigner`.
+type aggregateSigner struct{}
+
+const (
+	aggregatePointSize  = 32 // The size of an encoded R_i
+	aggregateScalarSize = 32 // The size of an encoded s
+)
+
+var aggregationCoefficientDomain = []byte("pocket-consensus-aggregate-signature")
+
+func (s *aggregateSigner) Aggregate(validators []*genesis.Validator, bytesSigned []byte, partialSigs []*typesCons.PartialSignature) (*typesCons.ThresholdSignature, error) {
+	indices := getValidatorIndices(validators)
+	signers := NewSignerBitmap(len(validators))
+	sigsByIndex := make(map[int][]byte, len(partialSigs))
+	for _, partialSig := range partialSigs {
+		i, ok := indices[partialSig.Address]
+		if !ok {
+			return nil, typesCons.ErrMissingValidator(partialSig.Address, 0)
+		}
+		if signers.IsSet(i) {
+			return nil, typesCons.ErrDuplicateSignerInQC(partialSig.Address, typesCons.NodeId(i+1))
+		}
+		if len(partialSig.Signature) != ed25519.SignatureSize {
+			return nil, typesCons.ErrInvalidPartialSigInQC(partialSig.Address, typesCons.NodeId(i+1))
+		}
+		signers.Set(i)
+		sigsByIndex[i] = partialSig.Signature
+	}
+
+	// The partial signatures are aggregated in the order of the signer bitmap so they can be matched to their
+	// signers when verifying the aggregate.
+	signerIndices := make([]int, 0, len(sigsByIndex))
+	for i := range sigsByIndex {
+		signerIndices = append(signerIndices, i)
+	}
+	sort.Ints(signerIndices)
+
+	pubKeys := make([][]byte, 0, len(signerIndices))
+	rs := make([][]byte, 0, len(signerIndices))
+	partialScalars := make([]*edwards25519.Scalar, 0, len(signerIndices))
+	for _, i := range signerIndices {
+		sig := sigsByIndex[i]
+		partialScalar, err := edwards25519.NewScalar().SetCanonicalBytes(sig[aggregatePointSize:])
+		if err != nil {
+			return nil, typesCons.ErrNonCanonicalPartialSig(hex.EncodeToString(validators[i].Address), err)
+		}
+		pubKeys = append(pubKeys, validators[i].PublicKey)
+		rs = append(rs, sig[:aggregatePointSize])
+		partialScalars = append(partialScalars, partialScalar)
+	}
+
+	aggregateScalar := edwards25519.NewScalar()
+	for i, z := range getAggregationCoefficients(bytesSigned, pubKeys, rs) {
+		aggregateScalar.MultiplyAdd(z, partialScalars[i], aggregateScalar)
+	}
+
+	aggregateSig := make([]byte, 0, len(rs)*aggregatePointSize+aggregateScalarSize)
+	for _, r := range rs {
+		aggregateSig = append(aggregateSig, r...)
+	}
+	aggregateSig = append(aggregateSig, aggregateScalar.Bytes()...)
+
+	return &typesCons.ThresholdSignature{
+		SignerBitmap:       signers,
+		AggregateSignature: aggregateSig,
+	}, nil
+}
+
+// The aggregate is valid if [s]B = [z_1]R_1 + [z_1*k_1]A_1 + ... + [z_n]R_n + [z_n*k_n]A_n, where A_i is the public
+// key of the i-th signer and k_i = SHA512(R_i || A_i || bytesToVerify) is its ed25519 challenge.
+func (s *aggregateSigner) Verify(validators []*genesis.Validator, bytesToVerify []byte, thresholdSig *typesCons.ThresholdSignature) error {
+	signers, err := s.SignerBitmap(validators, thresholdSig)
+	if err != nil {
+		return err
+	}
+	numSigners := signers.Count()
+	if numSigners == 0 {
+		return typesCons.ErrNilThresholdSigInQC
+	}
+
+	aggregateSig := thresholdSig.AggregateSignature
+	if len(aggregateSig) != numSigners*aggregatePointSize+aggregateScalarSize {
+		return typesCons.ErrInvalidAggregateSigLength(len(aggregateSig), numSigners)
+	}
+
+	pubKeys := make([][]byte, 0, numSigners)
+	rs := make([][]byte, 0, numSigners)
+	for i, validator := range validators {
+		if !signers.IsSet(i) {
+			continue
+		}
+		offset := len(rs) * aggregatePointSize
+		pubKeys = append(pubKeys, validator.PublicKey)
+		rs = append(rs, aggregateSig[offset:offset+aggregatePointSize])
+	}
+
+	aggregateScalar, err := edwards25519.NewScalar().SetCanonicalBytes(aggregateSig[numSigners*aggregatePointSize:])
+	if err != nil {
+		return typesCons.ErrInvalidAggregateSig
+	}
+
+	expected := edwards25519.NewIdentityPoint()
+	for i, z := range getAggregationCoefficients(bytesToVerify, pubKeys, rs) {
+		r, err := new(edwards25519.Point).SetBytes(rs[i])
+		if err != nil {
+			return typesCons.ErrInvalidAggregateSig
+		}
+		pubKey, err := new(edwards25519.Point).SetBytes(pubKeys[i])
+		if err != nil {
+			return typesCons.ErrInvalidAggregateSig
+		}
+
+		challenge := sha512.New()
+		challenge.Write(rs[i])
+		challenge.Write(pubKeys[i])
+		challenge.Write(bytesToVerify)
+		k, err := edwards25519.NewScalar().SetUniformBytes(challenge.Sum(nil))
+		if err != nil {
+			return err
+		}
+
+		expected.Add(expected, new(edwards25519.Point).ScalarMult(z, r))
+		expected.Add(expected, new(edwards25519.Point).ScalarMult(edwards25519.NewScalar().Multiply(z, k), pubKey))
+	}
+
+	if new(edwards25519.Point).ScalarBaseMult(aggregateScalar).Equal(expected) != 1 {
+		return typesCons.ErrInvalidAggregateSig
+	}
+
+	return nil
+}
+
+func (s *aggregateSigner) SignerBitmap(validators []*genesis.Validator, thresholdSig *typesCons.ThresholdSignature) (SignerBitmap, error) {
+	signers := SignerBitmap(thresholdSig.SignerBitmap)
+	if err := signers.validate(len(validators)); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// z_i = SHA512(domain || SHA512(bytesSigned || R_1 || A_1 || ... || R_n || A_n) || i)
+func getAggregationCoefficients(bytesSigned []byte, pubKeys, rs [][]byte) []*edwards25519.Scalar {
+	transcript := sha512.New()
+	transcript.Write(bytesSigned)
+	for i := range rs {
+		transcript.Write(rs[i])
+		transcript.Write(pubKeys[i])
+	}
+	transcriptHash := transcript.Sum(nil)
+
+	coefficients := make([]*edwards25519.Scalar, len(rs))
+	indexBz := make([]byte, 4)
+	for i := range rs {
+		binary.BigEndian.PutUint32(indexBz, uint32(i))
+		coefficient := sha512.New()
+		coefficient.Write(aggregationCoefficientDomain)
+		coefficient.Write(transcriptHash)
+		coefficient.Write(indexBz)
+		// SetUniformBytes only fails if the input is not 64 bytes long, which a SHA512 digest always is.
+		coefficients[i], _ = edwards25519.NewScalar().SetUniformBytes(coefficient.Sum(nil))
+	}
+	return coefficients
+}
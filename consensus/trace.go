@@ -0,0 +1,219 @@
+package consensus
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Records every consensus message sent and received by the node, alongside the local events that drive it, so
+// the run can be reproduced through `ReplayTrace`. Unlike the WAL, the trace is only used for debugging so
+// entries are not synced to disk as they are written. A trace can only be replayed into a fresh module, so the
+// trace of the previous start of the node is overwritten.
+type traceRecorder struct {
+	m    sync.Mutex // Entries are written by both the node's event loop and the pacemaker timer
+	file *os.File
+}
+
+func openTraceRecorder(path string) (*traceRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &traceRecorder{file: file}, nil
+}
+
+func (r *traceRecorder) record(entry *typesCons.TraceEntry) error {
+	bz, err := encodeLengthPrefixed(entry)
+	if err != nil {
+		return err
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	_, err = r.file.Write(bz)
+	return err
+}
+
+func (r *traceRecorder) close() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.file.Close()
+}
+
+// Reads the entries of a trace recorded through the `trace_file` consensus config.
+func ReadTrace(path string) ([]*typesCons.TraceEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	trace := make([]*typesCons.TraceEntry, 0)
+	err = readLengthPrefixed(file, func(entryBz []byte) error {
+		entry := &typesCons.TraceEntry{}
+		if err := proto.Unmarshal(entryBz, entry); err != nil {
+			return err
+		}
+		trace = append(trace, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// Feeds the messages received by a node and its local events, as recorded in `trace`, into a fresh consensus
+// module created with the config of that node. The pacemaker must be in manual mode so the state transitions
+// are driven by the trace alone; outbound messages are not replayed since the module sends them again as it
+// handles the inbound ones, except for the blocks the node proposed, which it proposes again rather than building
+// new ones with a different timestamp and transactions. `afterEntry` is called once every entry has been replayed,
+// if it is not nil.
+func ReplayTrace(consensusMod modules.ConsensusModule, trace []*typesCons.TraceEntry, afterEntry func(int, *typesCons.TraceEntry)) error {
+	m, ok := consensusMod.(*consensusModule)
+	if !ok {
+		return typesCons.ErrReplayUnsupportedModule
+	}
+	if !m.paceMaker.IsManualMode() {
+		return typesCons.ErrReplayRequiresManualPacemaker
+	}
+
+	m.replayedProposals = getTraceProposals(trace)
+	defer func() { m.replayedProposals = nil }()
+
+	for i, entry := range trace {
+		var err error
+		switch entry.Type {
+		case typesCons.TraceEntryType_TRACE_ENTRY_INBOUND:
+			err = m.HandleMessage(entry.Message)
+		case typesCons.TraceEntryType_TRACE_ENTRY_OUTBOUND:
+		case typesCons.TraceEntryType_TRACE_ENTRY_DEBUG:
+			var debugMessage types.DebugMessage
+			if err = entry.Message.UnmarshalTo(&debugMessage); err == nil {
+				err = m.HandleDebugMessage(&debugMessage)
+			}
+		case typesCons.TraceEntryType_TRACE_ENTRY_PACEMAKER_TIMEOUT:
+			m.m.Lock()
+			m.paceMaker.InterruptRound()
+			m.publishViewChange()
+			m.m.Unlock()
+		default:
+			err = typesCons.ErrUnknownTraceEntryType(entry.Type)
+		}
+		if err != nil {
+			return typesCons.ErrReplayTraceEntry(i, err)
+		}
+
+		if afterEntry != nil {
+			afterEntry(i, entry)
+		}
+	}
+
+	return nil
+}
+
+// Returns the blocks proposed in the outbound PREPARE proposals of `trace`, keyed by the view of the proposal.
+func getTraceProposals(trace []*typesCons.TraceEntry) map[consensusView]*types.Block {
+	proposals := make(map[consensusView]*types.Block)
+	for _, entry := range trace {
+		if entry.Type != typesCons.TraceEntryType_TRACE_ENTRY_OUTBOUND || entry.Message.MessageName() != HotstuffMessage {
+			continue
+		}
+		var msg typesCons.HotstuffMessage
+		if err := entry.Message.UnmarshalTo(&msg); err != nil {
+			continue
+		}
+		if msg.Type == Propose && msg.Step == Prepare && msg.Block != nil {
+			proposals[consensusView{height: msg.Height, round: msg.Round, step: Prepare}] = msg.Block
+		}
+	}
+	return proposals
+}
+
+// Returns the block the node proposed in the current view of the trace it replays, if any.
+func (m *consensusModule) getReplayedProposal() *types.Block {
+	return m.replayedProposals[consensusView{height: m.Height, round: m.Round, step: Prepare}]
+}
+
+/*** Trace Recording Helpers ***/
+
+func (m *consensusModule) traceInbound(msg *anypb.Any) {
+	if m.trace == nil {
+		return
+	}
+	m.recordTraceEntry(typesCons.TraceEntryType_TRACE_ENTRY_INBOUND, getTraceSender(msg), msg)
+}
+
+// `peer` is the hex encoded address of the recipient, or empty if the message is broadcast.
+func (m *consensusModule) traceOutbound(peer string, msg *anypb.Any) {
+	m.recordTraceEntry(typesCons.TraceEntryType_TRACE_ENTRY_OUTBOUND, peer, msg)
+}
+
+func (m *consensusModule) traceDebugMessage(debugMessage *types.DebugMessage) {
+	if m.trace == nil {
+		return
+	}
+
+	anyDebugMessage, err := anypb.New(debugMessage)
+	if err != nil {
+		m.nodeLogError(typesCons.ErrRecordTrace.Error(), err)
+		return
+	}
+	m.recordTraceEntry(typesCons.TraceEntryType_TRACE_ENTRY_DEBUG, "", anyDebugMessage)
+}
+
+func (m *consensusModule) tracePacemakerTimeout() {
+	m.recordTraceEntry(typesCons.TraceEntryType_TRACE_ENTRY_PACEMAKER_TIMEOUT, "", nil)
+}
+
+func (m *consensusModule) recordTraceEntry(entryType typesCons.TraceEntryType, peer string, msg *anypb.Any) {
+	if m.trace == nil {
+		return
+	}
+
+	entry := &typesCons.TraceEntry{
+		Timestamp: timestamppb.New(time.Now()),
+		Type:      entryType,
+		Peer:      peer,
+		Message:   msg,
+	}
+	if err := m.trace.record(entry); err != nil {
+		m.nodeLogError(typesCons.ErrRecordTrace.Error(), err)
+	}
+}
+
+// Consensus messages do not carry their sender, so it is only known for messages signed by it.
+func getTraceSender(msg *anypb.Any) string {
+	switch msg.MessageName() {
+	case HotstuffMessage:
+		var hotstuffMessage typesCons.HotstuffMessage
+		if err := msg.UnmarshalTo(&hotstuffMessage); err != nil {
+			return ""
+		}
+		if partialSig := hotstuffMessage.GetPartialSignature(); partialSig != nil {
+			return partialSig.Address
+		}
+		return hotstuffMessage.GetLeaderClaim().GetAddress()
+	case TimeoutMessage:
+		var timeoutMessage typesCons.TimeoutMessage
+		if err := msg.UnmarshalTo(&timeoutMessage); err != nil {
+			return ""
+		}
+		return timeoutMessage.GetPartialSignature().GetAddress()
+	case BlockSyncRequestMessage:
+		var blockSyncRequest typesCons.BlockSyncRequest
+		if err := msg.UnmarshalTo(&blockSyncRequest); err != nil {
+			return ""
+		}
+		return blockSyncRequest.RequesterAddress
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package consensus
+
+import (
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"github.com/pokt-network/pocket/shared/types"
+	typesUtil "github.com/pokt-network/pocket/utility/types"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Entry point for transactions accepted by this node (e.g. from a client). The transaction is only gossiped to the
+// rest of the network if the utility module accepts it into the mempool.
+func (m *consensusModule) SubmitTransaction(txBz []byte) error {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return m.submitTransaction(txBz)
+}
+
+// Adds a transaction that originated on this node to the utility mempool and gossips it to the rest of the network.
+func (m *consensusModule) submitTransaction(txBz []byte) error {
+	if err := m.checkTransaction(txBz); err != nil {
+		return err
+	}
+
+	anyMsg, err := anypb.New(&typesCons.UtilityMessage{Transaction: txBz})
+	if err != nil {
+		return err
+	}
+	m.traceOutbound("", anyMsg)
+	return m.GetBus().GetP2PModule().Broadcast(anyMsg, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC)
+}
+
+// Adds a transaction gossiped by another node to the utility mempool. Since the original broadcast already
+// reaches every node, the transaction is not gossiped any further.
+func (m *consensusModule) handleUtilityMessage(msg *typesCons.UtilityMessage) {
+	txHash := typesUtil.TransactionHash(msg.Transaction)
+	if err := m.checkTransaction(msg.Transaction); err != nil {
+		m.nodeLog(typesCons.WarnDiscardTransaction(txHash, err.Error()))
+		return
+	}
+	m.nodeLog(typesCons.AddedGossipedTransaction(txHash))
+}
+
+// The utility mempool is shared by every utility context, but the context of the block being voted on is owned by
+// the Hotstuff steps, so transactions are checked against a dedicated context that is released right away.
+func (m *consensusModule) checkTransaction(txBz []byte) error {
+	utilityContext, err := m.GetBus().GetUtilityModule().NewContext(int64(m.Height))
+	if err != nil {
+		return err
+	}
+	defer utilityContext.ReleaseContext()
+	return utilityContext.CheckTransaction(txBz)
+}
@@ -12,13 +12,13 @@ import (
 // Logs and warnings
 const (
 	// INFO
-	DisregardHotstuffMessage = "Discarding hotstuff message"
-	NotLockedOnQC            = "node is not locked on any QC"
-	ProposalBlockExtends     = "the ProposalQC block is the same as the LockedQC block"
+	DisregardHotstuffMessage    = "Discarding hotstuff message"
+	NotLockedOnQC               = "node is not locked on any QC"
+	ProposalBlockExtends        = "the proposed block extends the LockedQC block"
+	ProposalJustifiedByHigherQC = "the proposal is justified by a QC from a later view than the LockedQC"
 
 	// WARN
-	NilUtilityContextWarning     = "[WARN] Utility context not nil when preparing a new block? Releasing for now but should not happen"
-	InvalidPartialSigInQCWarning = "[WARN] QC contains an invalid partial signature"
+	NilUtilityContextWarning = "[WARN] Utility context not nil when preparing a new block? Releasing for now but should not happen"
 
 	// DEBUG
 	DebugResetToGenesis  = "[DEBUG] Resetting to genesis..."
@@ -42,6 +42,10 @@ func PacemakerTimeout(height uint64, step HotstuffStep, round uint64) string {
 	return fmt.Sprintf("Timed out at (height, step, round) (%d, %s, %d)!", height, StepToString[step], round)
 }
 
+func PacemakerNewRound(height, round uint64) string {
+	return fmt.Sprintf("Starting round %d at height %d justified by a TimeoutQC", round, height)
+}
+
 func PacemakerNewHeight(height uint64) string {
 	return fmt.Sprintf("Starting first round for new block at height: %d", height)
 }
@@ -54,6 +58,10 @@ func OptimisticVoteCountWaiting(step HotstuffStep, status string) string {
 	return fmt.Sprintf("Still waiting for more %s messages; %s", StepToString[step], status)
 }
 
+func OptimisticTimeoutCountWaiting(round uint64, status string) string {
+	return fmt.Sprintf("Still waiting for more timeouts for round %d; %s", round, status)
+}
+
 func OptimisticVoteCountPassed(step HotstuffStep) string {
 	return fmt.Sprintf("received enough %s votes!", StepToString[step])
 }
@@ -78,8 +86,12 @@ func BroadcastingMessage(msg *HotstuffMessage) string {
 	return fmt.Sprintf("Broadcasting message for %s step", StepToString[msg.Step])
 }
 
-func WarnInvalidPartialSigInQC(address string, nodeId NodeId) string {
-	return fmt.Sprintf("%s: from %s (%d)", InvalidPartialSigInQCWarning, address, nodeId)
+func BroadcastingTimeout(msg *TimeoutMessage) string {
+	return fmt.Sprintf("Broadcasting timeout for (height, round): (%d, %d)", msg.Height, msg.Round)
+}
+
+func WarnDuplicatePartialSig(address string, step HotstuffStep) string {
+	return fmt.Sprintf("[WARN] Ignoring duplicate partial signature from %s for step %s", address, StepToString[step])
 }
 
 func WarnMissingPartialSig(msg *HotstuffMessage) string {
@@ -90,14 +102,70 @@ func WarnDiscardHotstuffMessage(_ *HotstuffMessage, reason string) string {
 	return fmt.Sprintf("[WARN] %s because: %s", DisregardHotstuffMessage, reason)
 }
 
+func WarnDiscardTimeoutMessage(_ *TimeoutMessage, reason string) string {
+	return fmt.Sprintf("[WARN] Discarding timeout message because: %s", reason)
+}
+
+func WarnInvalidHighQC(reason string) string {
+	return fmt.Sprintf("[WARN] Ignoring invalid high QC because: %s", reason)
+}
+
 func WarnUnexpectedMessageInPool(_ *HotstuffMessage, height uint64, step HotstuffStep, round uint64) string {
 	return fmt.Sprintf("[WARN] Message in pool does not match (height, step, round) of QC being generated; %d, %s, %d", height, StepToString[step], round)
 }
 
+func WarnDuplicateMessageInPool(msg *HotstuffMessage) string {
+	return fmt.Sprintf("[WARN] Discarding %s message for (height, round): (%d, %d) since the pool already has one from the same validator", StepToString[msg.Step], msg.Height, msg.Round)
+}
+
+func WarnUnexpectedBlockInPool(_ *HotstuffMessage, blockHash string) string {
+	return fmt.Sprintf("[WARN] Message in pool is for block %s rather than the block of the QC being generated", blockHash)
+}
+
 func WarnIncompletePartialSig(ps *PartialSignature, msg *HotstuffMessage) string {
 	return fmt.Sprintf("[WARN] Partial signature is incomplete for step %s which should not happen...", StepToString[msg.Step])
 }
 
+func WarnDiscardTransaction(txHash string, reason string) string {
+	return fmt.Sprintf("[WARN] Discarding transaction %s gossiped by another node because: %s", txHash, reason)
+}
+
+func WarnDroppedConsensusEvent(eventType, consumer string) string {
+	return fmt.Sprintf("[WARN] Dropping consensus event %s because %s is not keeping up", eventType, consumer)
+}
+
+func StateSyncStarted(height, networkHeight uint64) string {
+	return fmt.Sprintf("🔄 Node is at height %d but the network is at height %d; starting state sync 🔄", height, networkHeight)
+}
+
+func StateSyncAppliedBlock(height uint64) string {
+	return fmt.Sprintf("Applied block at height %d received through state sync", height)
+}
+
+func StateSyncCompleted(height uint64) string {
+	return fmt.Sprintf("🔄 State sync completed; rejoining consensus at height %d 🔄", height)
+}
+
+func ReplayingWAL(height, round uint64, step HotstuffStep) string {
+	return fmt.Sprintf("Replaying the consensus WAL; restored state at (height, round, step): (%d, %d, %s)", height, round, StepToString[step])
+}
+
+func DoubleSignDetected(address string, nodeId NodeId, msg *HotstuffMessage) string {
+	return fmt.Sprintf("⚠️ Validator %s (node %d) signed conflicting votes at (height, step, round): (%d, %s, %d); submitting double sign evidence ⚠️", address, nodeId, msg.Height, StepToString[msg.Step], msg.Round)
+}
+
+func ValidatorSetUpdated(height uint64, numValidators int) string {
+	return fmt.Sprintf("Validator set updated after committing height %d; there are now %d validators", height, numValidators)
+}
+
+func AddedGossipedTransaction(txHash string) string {
+	return fmt.Sprintf("Added transaction %s gossiped by another node to the utility mempool", txHash)
+}
+
+func RequestingBlock(height uint64) string {
+	return fmt.Sprintf("Requesting committed block at height %d from the network", height)
+}
+
 func DebugTogglePacemakerManualMode(mode string) string {
 	return fmt.Sprintf("[DEBUG] Toggling pacemaker manual mode to %s", mode)
 }
@@ -122,8 +190,8 @@ const (
 	nilBlockInQCError                           = "QC must contain a non nil block"
 	nilThresholdSigInQCError                    = "QC must contains a non nil threshold signature"
 	notEnoughSignaturesError                    = "did not receive enough partial signature"
-	nodeIsLockedOnPastQCError                   = "node is locked on a QC from the past"
-	unhandledProposalCaseError                  = "unhandled proposal validation check"
+	unsafeProposalError                         = "proposal neither extends the locked block nor is justified by a QC from a later view"
+	invalidHighQCViewError                      = "high QC must be from an earlier round of the current height"
 	unnecessaryPartialSigForNewRoundError       = "newRound messages do not need a partial signature"
 	unnecessaryPartialSigForLeaderProposalError = "leader proposals do not need a partial signature"
 	nilPartialSigError                          = "partial signature cannot be nil"
@@ -149,6 +217,54 @@ const (
 	createConsensusMessageError                 = "error creating consensus message"
 	anteValidationError                         = "discarding hotstuff message because ante validation failed"
 	nilLeaderIdError                            = "attempting to send a message to leader when LeaderId is nil"
+	duplicateSignerInQCError                    = "QC contains more than one partial signature from the same validator"
+	invalidPartialSigInQCError                  = "QC contains an invalid partial signature"
+	qcBlockMismatchError                        = "QC does not certify the block in the message"
+	qcHeightRoundMismatchError                  = "QC (height, round) does not match that of the message"
+	qcStepMismatchError                         = "QC was not formed for the expected step"
+	byzantineStakeThresholdError                = "byzantine stake threshold not met"
+	applyCommittedBlockError                    = "could not apply committed block received through state sync"
+	createLeaderClaimError                      = "could not create leader claim"
+	writeWALError                               = "could not write to the consensus WAL; the message will not be sent"
+	blockStoreError                             = "could not access the consensus block store"
+	missingLeaderClaimsError                    = "did not receive the leader claims of enough validators"
+	blockSyncInvalidRequesterError              = "block sync request has an invalid requester address"
+	invalidValidatorStakeError                  = "validator has an invalid amount of staked tokens"
+	submitDoubleSignEvidenceError               = "could not submit double sign evidence to the utility mempool"
+	updateAddrBookError                         = "could not update the P2P address book with the new validator set"
+	nilBlockHeaderError                         = "block header cannot be nil"
+	invalidBlockHeightError                     = "block height does not match the node's height"
+	invalidBlockHashError                       = "block hash does not match the hash of the block header"
+	invalidTransactionsRootError                = "block header does not commit to the transactions in the block"
+	invalidLastBlockHashError                   = "block does not extend the last committed block"
+	invalidLastCommitQCError                    = "block header does not contain the COMMIT QC of the last committed block"
+	nilTimeoutQCError                           = "entering a later round requires a TimeoutQC"
+	timeoutQCHeightRoundMismatchError           = "TimeoutQC does not justify the (height, round) of the message"
+	olderTimeoutRoundError                      = "timeout is from a round the node already left"
+	invalidTimeoutSignatureError                = "partial signature on timeout is invalid"
+	createTimeoutMessageError                   = "could not create timeout message"
+	unexpectedChainedMessageError               = "only NEWROUND and PREPARE messages are exchanged in chained hotstuff"
+	notNextLeaderError                          = "votes are only aggregated by the leader of the next height"
+	alreadyVotedError                           = "node already voted in the current view"
+	invalidProposerError                        = "block was not proposed by the leader of the view"
+	invalidJustifyQCError                       = "block header does not contain the QC justifying the proposal"
+	duplicatePendingTransactionError            = "block contains a transaction from one of its uncommitted ancestors"
+	missingPendingBlockError                    = "an uncommitted block to commit is missing"
+	publishConsensusEventError                  = "could not publish consensus event"
+	recordTraceError                            = "could not record consensus trace entry"
+	replayUnsupportedModuleError                = "traces can only be replayed into a module created by `consensus.Create`"
+	replayRequiresManualPacemakerError          = "traces can only be replayed with the pacemaker in manual mode"
+	replayTraceEntryError                       = "could not replay trace entry"
+	invalidSignerBitmapError                    = "signer bitmap does not match the validator set"
+	invalidAggregateSigLengthError              = "aggregate signature does not match the number of signers"
+	invalidAggregateSigError                    = "aggregate signature in QC is invalid"
+	nonCanonicalPartialSigError                 = "partial signature cannot be aggregated"
+	getQCSignersError                           = "could not get the signers of the QC"
+	invalidValidatorsHashError                  = "the validator set hash in the block header does not match the validator set"
+	conflictingSignatureError                   = "refusing to sign a message that conflicts with the last signed state"
+	writeLastSignedStateError                   = "could not persist the last signed state"
+	lastSignedStateAddressMismatchError         = "the last signed state belongs to another validator"
+	lastSignedStateLockedError                  = "the last signed state is locked by another node running with the same root directory"
 )
 
 var (
@@ -161,8 +277,6 @@ var (
 	ErrNilBlockInQC                           = errors.New(nilBlockInQCError)
 	ErrNilThresholdSigInQC                    = errors.New(nilThresholdSigInQCError)
 	ErrNotEnoughSignatures                    = errors.New(notEnoughSignaturesError)
-	ErrNodeIsLockedOnPastQC                   = errors.New(nodeIsLockedOnPastQCError)
-	ErrUnhandledProposalCase                  = errors.New(unhandledProposalCaseError)
 	ErrUnnecessaryPartialSigForNewRound       = errors.New(unnecessaryPartialSigForNewRoundError)
 	ErrUnnecessaryPartialSigForLeaderProposal = errors.New(unnecessaryPartialSigForLeaderProposalError)
 	ErrNilPartialSig                          = errors.New(nilPartialSigError)
@@ -183,12 +297,66 @@ var (
 	ErrCreateConsensusMessage                 = errors.New(createConsensusMessageError)
 	ErrHotstuffValidation                     = errors.New(anteValidationError)
 	ErrNilLeaderId                            = errors.New(nilLeaderIdError)
+	ErrQCBlockMismatch                        = errors.New(qcBlockMismatchError)
+	ErrApplyCommittedBlock                    = errors.New(applyCommittedBlockError)
+	ErrCreateLeaderClaim                      = errors.New(createLeaderClaimError)
+	ErrWriteWAL                               = errors.New(writeWALError)
+	ErrBlockStore                             = errors.New(blockStoreError)
+	ErrBlockSyncInvalidRequester              = errors.New(blockSyncInvalidRequesterError)
+	ErrSubmitDoubleSignEvidence               = errors.New(submitDoubleSignEvidenceError)
+	ErrUpdateAddrBook                         = errors.New(updateAddrBookError)
+	ErrNilBlockHeader                         = errors.New(nilBlockHeaderError)
+	ErrInvalidTransactionsRoot                = errors.New(invalidTransactionsRootError)
+	ErrInvalidLastCommitQC                    = errors.New(invalidLastCommitQCError)
+	ErrWriteLastSignedState                   = errors.New(writeLastSignedStateError)
+	ErrNilTimeoutQC                           = errors.New(nilTimeoutQCError)
+	ErrCreateTimeoutMessage                   = errors.New(createTimeoutMessageError)
+	ErrUnexpectedChainedMessage               = errors.New(unexpectedChainedMessageError)
+	ErrNotNextLeader                          = errors.New(notNextLeaderError)
+	ErrAlreadyVoted                           = errors.New(alreadyVotedError)
+	ErrInvalidJustifyQC                       = errors.New(invalidJustifyQCError)
+	ErrDuplicatePendingTransaction            = errors.New(duplicatePendingTransactionError)
+	ErrPublishConsensusEvent                  = errors.New(publishConsensusEventError)
+	ErrRecordTrace                            = errors.New(recordTraceError)
+	ErrReplayUnsupportedModule                = errors.New(replayUnsupportedModuleError)
+	ErrReplayRequiresManualPacemaker          = errors.New(replayRequiresManualPacemakerError)
+	ErrInvalidAggregateSig                    = errors.New(invalidAggregateSigError)
+	ErrGetQCSigners                           = errors.New(getQCSignersError)
 )
 
 func ErrInvalidBlockSize(blockSize, maxSize uint64) error {
 	return fmt.Errorf("%s: %d bytes VS max of %d bytes", blockSizeTooLargeError, blockSize, maxSize)
 }
 
+func ErrInvalidBlockHeight(blockHeight int64, height uint64) error {
+	return fmt.Errorf("%s: %d != %d", invalidBlockHeightError, blockHeight, height)
+}
+
+func ErrInvalidBlockHash(blockHash, computedHash string) error {
+	return fmt.Errorf("%s: %s != %s", invalidBlockHashError, blockHash, computedHash)
+}
+
+func ErrInvalidLastBlockHash(lastBlockHash, expectedLastBlockHash string) error {
+	return fmt.Errorf("%s: %s != %s", invalidLastBlockHashError, lastBlockHash, expectedLastBlockHash)
+}
+
+func ErrInvalidValidatorsHash(validatorsHash, expectedValidatorsHash string) error {
+	return fmt.Errorf("%s: %s != %s", invalidValidatorsHashError, validatorsHash, expectedValidatorsHash)
+}
+
+func ErrConflictingSignature(height, round uint64, step HotstuffStep, blockHash string, lastSigned *LastSignedState) error {
+	return fmt.Errorf("%s; (height, round, step, block): (%d, %d, %s, %s); last signed: (%d, %d, %s, %s)", conflictingSignatureError,
+		height, round, StepToString[step], blockHash, lastSigned.Height, lastSigned.Round, StepToString[lastSigned.Step], lastSigned.BlockHash)
+}
+
+func ErrLastSignedStateAddressMismatch(address, expectedAddress string) error {
+	return fmt.Errorf("%s: %s != %s", lastSignedStateAddressMismatchError, address, expectedAddress)
+}
+
+func ErrLastSignedStateLocked(path string, err error) error {
+	return fmt.Errorf("%s: %s: %w", lastSignedStateLockedError, path, err)
+}
+
 func ErrInvalidAppHash(blockHeaderHash, appHash string) error {
 	return fmt.Errorf("%s: %s != %s", invalidAppHashError, blockHeaderHash, appHash)
 }
@@ -197,6 +365,74 @@ func ErrByzantineThresholdCheck(n int, threshold float64) error {
 	return fmt.Errorf("%s: (%d > %.2f?)", byzantineOptimisticThresholdError, n, threshold)
 }
 
+func ErrByzantineStakeThresholdCheck(signedStake, totalStake string) error {
+	return fmt.Errorf("%s: (%s > 2/3 * %s?)", byzantineStakeThresholdError, signedStake, totalStake)
+}
+
+func ErrInvalidValidatorStake(address, stake string) error {
+	return fmt.Errorf("%s: %s (%s)", invalidValidatorStakeError, address, stake)
+}
+
+func ErrDuplicateSignerInQC(address string, nodeId NodeId) error {
+	return fmt.Errorf("%s: %s (%d)", duplicateSignerInQCError, address, nodeId)
+}
+
+func ErrInvalidPartialSigInQC(address string, nodeId NodeId) error {
+	return fmt.Errorf("%s: from %s (%d)", invalidPartialSigInQCError, address, nodeId)
+}
+
+func ErrInvalidSignerBitmap(bitmapLen, numValidators int) error {
+	return fmt.Errorf("%s: %d bytes for %d validators", invalidSignerBitmapError, bitmapLen, numValidators)
+}
+
+func ErrInvalidAggregateSigLength(sigLen, numSigners int) error {
+	return fmt.Errorf("%s: %d bytes for %d signers", invalidAggregateSigLengthError, sigLen, numSigners)
+}
+
+func ErrNonCanonicalPartialSig(address string, err error) error {
+	return fmt.Errorf("%s: from %s: %s", nonCanonicalPartialSigError, address, err.Error())
+}
+
+func ErrUnknownThresholdSignatureScheme(scheme string) error {
+	return fmt.Errorf("unknown threshold signature scheme: %s", scheme)
+}
+
+func ErrQCHeightRoundMismatch(qcHeight, qcRound, msgHeight, msgRound uint64) error {
+	return fmt.Errorf("%s: QC (%d, %d) VS message (%d, %d)", qcHeightRoundMismatchError, qcHeight, qcRound, msgHeight, msgRound)
+}
+
+func ErrUnsafeProposal(lockedHeight, lockedRound uint64) error {
+	return fmt.Errorf("%s: locked at (height, round): (%d, %d)", unsafeProposalError, lockedHeight, lockedRound)
+}
+
+func ErrInvalidHighQCView(qcHeight, qcRound, height, round uint64) error {
+	return fmt.Errorf("%s: QC (%d, %d) VS current (%d, %d)", invalidHighQCViewError, qcHeight, qcRound, height, round)
+}
+
+func ErrQCStepMismatch(expected, actual HotstuffStep) error {
+	return fmt.Errorf("%s: expected %s but got %s", qcStepMismatchError, StepToString[expected], StepToString[actual])
+}
+
+func ErrTimeoutQCHeightRoundMismatch(qcHeight, qcRound, height, round uint64) error {
+	return fmt.Errorf("%s: TimeoutQC (%d, %d) VS expected (%d, %d)", timeoutQCHeightRoundMismatchError, qcHeight, qcRound, height, round)
+}
+
+func ErrOlderTimeoutRound(currentRound, timeoutRound uint64) error {
+	return fmt.Errorf("%s: Current: %d; Timeout: %d", olderTimeoutRoundError, currentRound, timeoutRound)
+}
+
+func ErrInvalidTimeoutSig(address string, nodeId NodeId) error {
+	return fmt.Errorf("%s: from %s (%d)", invalidTimeoutSignatureError, address, nodeId)
+}
+
+func ErrInvalidProposer(proposer, leader string) error {
+	return fmt.Errorf("%s: %s != %s", invalidProposerError, proposer, leader)
+}
+
+func ErrMissingPendingBlock(height uint64) error {
+	return fmt.Errorf("%s: height %d", missingPendingBlockError, height)
+}
+
 func ErrMissingValidator(address string, nodeId NodeId) error {
 	return fmt.Errorf("%s: %s (%d)", validatorNotFoundInMapError, address, nodeId)
 }
@@ -218,6 +454,14 @@ func ErrUnknownConsensusMessageType(msg interface{}) error {
 	return fmt.Errorf("unknown consensus message type: %v", msg)
 }
 
+func ErrUnknownTraceEntryType(entryType TraceEntryType) error {
+	return fmt.Errorf("unknown trace entry type: %s", entryType)
+}
+
+func ErrReplayTraceEntry(index int, err error) error {
+	return fmt.Errorf("%s %d: %s", replayTraceEntryError, index, err.Error())
+}
+
 func ErrCreateProposeMessage(step HotstuffStep) error {
 	return fmt.Errorf("could not create a %s Propose message", StepToString[step])
 }
@@ -230,6 +474,10 @@ func ErrQCInvalid(step HotstuffStep) error {
 	return fmt.Errorf("invalid QC in step %s", StepToString[step])
 }
 
+func ErrMissingLeaderClaims(numClaims, numValidators int, err error) error {
+	return fmt.Errorf("%s; received: %d, validators: %d: %w", missingLeaderClaimsError, numClaims, numValidators, err)
+}
+
 func ErrLeaderElection(msg *HotstuffMessage) error {
 	return fmt.Errorf("leader election failed: Validator cannot take part in consensus at height %d round %d", msg.Height, msg.Round)
 }
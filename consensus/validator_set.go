@@ -0,0 +1,91 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"sort"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"google.golang.org/protobuf/proto"
+)
+
+// Applies the validator set changes returned by utility when the committed block was applied, so they take
+// effect starting at the next height, and rebuilds the set of peers the P2P module communicates with.
+func (m *consensusModule) applyValidatorUpdates() {
+	if len(m.validatorUpdates) == 0 {
+		return
+	}
+
+	// A new map is built rather than modifying the current one in place since it is shared with other modules.
+	validatorMap := make(modules.ValidatorMap, len(m.validatorMap))
+	for address, validator := range m.validatorMap {
+		validatorMap[address] = validator
+	}
+	for _, update := range m.validatorUpdates {
+		address := hex.EncodeToString(update.Validator.Address)
+		if update.Removed {
+			delete(validatorMap, address)
+			continue
+		}
+		validatorMap[address] = update.Validator
+	}
+	m.validatorUpdates = nil
+
+	m.setValidatorMap(validatorMap)
+	m.nodeLog(typesCons.ValidatorSetUpdated(m.Height, len(validatorMap)))
+
+	if err := m.GetBus().GetP2PModule().UpdateAddrBook(validatorMap); err != nil {
+		m.nodeLogError(typesCons.ErrUpdateAddrBook.Error(), err)
+	}
+}
+
+// Replaces the validator set and recomputes the node ids derived from it. The node id of a node that
+// is no longer a validator is 0.
+func (m *consensusModule) setValidatorMap(validatorMap modules.ValidatorMap) {
+	valIdMap, idValMap := typesCons.GetValAddrToIdMap(validatorMap)
+
+	m.validatorMap = validatorMap
+	m.ValAddrToIdMap = valIdMap
+	m.IdToValAddrMap = idValMap
+	m.NodeId = valIdMap[m.privateKey.Address().String()]
+}
+
+// Returns the hex encoded Merkle root of the address, public key and stake of the validators in `validators`, which
+// must be sorted by address. Only the fields that determine who votes and how much their votes weigh are hashed so the
+// hash does not change with the rest of the validators' state (e.g. missed blocks).
+func ValidatorSetHash(validators []*genesis.Validator) (string, error) {
+	leaves := make([][]byte, len(validators))
+	for i, validator := range validators {
+		leaf, err := proto.MarshalOptions{Deterministic: true}.Marshal(&genesis.Validator{
+			Address:      validator.Address,
+			PublicKey:    validator.PublicKey,
+			StakedTokens: validator.StakedTokens,
+		})
+		if err != nil {
+			return "", err
+		}
+		leaves[i] = leaf
+	}
+	return hex.EncodeToString(cryptoPocket.MerkleRoot(leaves)), nil
+}
+
+func (m *consensusModule) getValidatorSetHash() (string, error) {
+	return ValidatorSetHash(m.getValidatorList())
+}
+
+// Returns the current validator set sorted by address.
+func (m *consensusModule) getValidatorList() []*genesis.Validator {
+	addresses := make([]string, 0, len(m.validatorMap))
+	for address := range m.validatorMap {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	validators := make([]*genesis.Validator, 0, len(addresses))
+	for _, address := range addresses {
+		validators = append(validators, m.validatorMap[address])
+	}
+	return validators
+}
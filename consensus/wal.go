@@ -0,0 +1,343 @@
+package consensus
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	typesCons "github.com/pokt-network/pocket/consensus/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	walFileName      = "consensus.wal"
+	walEntryLenBytes = 4 // Every entry is prefixed by its length as a big endian uint32
+)
+
+// An append only log of consensus state snapshots stored in the node's root directory. It is truncated
+// every time a new height starts since the committed state of previous heights is owned by persistence.
+type consensusWAL struct {
+	path   string
+	file   *os.File
+	height uint64 // The height of the entries currently in the log
+}
+
+func openWAL(rootDir string) (*consensusWAL, error) {
+	path := filepath.Join(rootDir, walFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	wal := &consensusWAL{
+		path: path,
+		file: file,
+	}
+
+	lastEntry, err := wal.readLastEntry()
+	if err != nil {
+		return nil, err
+	}
+	if lastEntry != nil {
+		wal.height = lastEntry.Height
+	}
+
+	return wal, nil
+}
+
+// Durably records the entry before returning.
+func (w *consensusWAL) write(entry *typesCons.WALEntry) error {
+	bz, err := encodeWALEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	if entry.Height > w.height {
+		return w.truncate(entry.Height, bz)
+	}
+
+	if _, err := w.file.Write(bz); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Atomically replaces the log with a single entry from a new height.
+func (w *consensusWAL) truncate(height uint64, bz []byte) error {
+	if err := writeFileAtomically(w.path, bz); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(w.path, os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	w.file.Close()
+	w.file = file
+	w.height = height
+
+	return nil
+}
+
+// Returns the most recent complete entry in the log, or nil if it is empty. An incomplete entry at
+// the end of the log is the result of a crash while it was being written and is ignored.
+func (w *consensusWAL) readLastEntry() (*typesCons.WALEntry, error) {
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var lastEntry *typesCons.WALEntry
+	err := readLengthPrefixed(w.file, func(entryBz []byte) error {
+		entry := &typesCons.WALEntry{}
+		if err := proto.Unmarshal(entryBz, entry); err != nil {
+			return err
+		}
+		lastEntry = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lastEntry, nil
+}
+
+// Discards every entry in the log.
+func (w *consensusWAL) reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	w.height = 0
+	return w.file.Sync()
+}
+
+func (w *consensusWAL) close() error {
+	return w.file.Close()
+}
+
+// Durably replaces the content of the file at `path` with `bz`, so a crash leaves either the old or the new content.
+func writeFileAtomically(path string, bz []byte) error {
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, bz, 0600); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.Open(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	tmpFile.Close()
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// The rename is only durable once the directory containing the file is synced as well.
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func encodeWALEntry(entry *typesCons.WALEntry) ([]byte, error) {
+	return encodeLengthPrefixed(entry)
+}
+
+// Entries are prefixed by their length as a big endian uint32. This encoding is shared by the WAL and the
+// consensus message traces.
+func encodeLengthPrefixed(msg proto.Message) ([]byte, error) {
+	msgBz, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	bz := make([]byte, walEntryLenBytes, walEntryLenBytes+len(msgBz))
+	binary.BigEndian.PutUint32(bz, uint32(len(msgBz)))
+	return append(bz, msgBz...), nil
+}
+
+// Calls `handleEntry` with every complete length prefixed entry read from `r`; an incomplete entry at the end
+// is ignored.
+func readLengthPrefixed(r io.Reader, handleEntry func([]byte) error) error {
+	lenBz := make([]byte, walEntryLenBytes)
+	for {
+		if _, err := io.ReadFull(r, lenBz); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+
+		entryBz := make([]byte, binary.BigEndian.Uint32(lenBz))
+		if _, err := io.ReadFull(r, entryBz); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+
+		if err := handleEntry(entryBz); err != nil {
+			return err
+		}
+	}
+}
+
+/*** Consensus WAL Helpers ***/
+
+// Records the current state, alongside the vote that is about to be sent if there is one. This must
+// happen before any message leaves the node so a restarted node never contradicts what it sent.
+// The last vote of the current height is recorded in every entry so it can still be resent after a crash.
+func (m *consensusModule) writeToWAL(vote *typesCons.HotstuffMessage) error {
+	if m.wal == nil {
+		return nil
+	}
+
+	if vote != nil {
+		m.walLastVote = vote
+	} else if m.walLastVote != nil && m.walLastVote.Height != m.Height {
+		m.walLastVote = nil
+	}
+
+	return m.writeEntryToWAL(m.newWALEntry())
+}
+
+// Records that the block at the current height was committed. Persistence commits the block before the node moves
+// on to the next height, so a node that crashes in between resumes at the next height rather than applying the
+// block again.
+func (m *consensusModule) writeCommitToWAL() error {
+	if m.wal == nil {
+		return nil
+	}
+
+	entry := m.newWALEntry()
+	entry.Committed = true
+	return m.writeEntryToWAL(entry)
+}
+
+func (m *consensusModule) newWALEntry() *typesCons.WALEntry {
+	entry := &typesCons.WALEntry{
+		Height:               m.Height,
+		Round:                m.Round,
+		Step:                 m.Step,
+		Block:                m.Block,
+		LockedQc:             m.LockedQC,
+		HighPrepareQc:        m.HighPrepareQC,
+		TimeoutQc:            m.TimeoutQC,
+		AppHash:              m.appHash,
+		Vote:                 m.walLastVote,
+		LastMissedValidators: m.lastMissedValidators,
+		Validators:           m.getValidatorList(),
+		LastBlockHash:        m.lastBlockHash,
+		LastCommitQc:         m.lastCommitQC,
+	}
+	if m.LeaderId != nil {
+		entry.LeaderId = uint64(*m.LeaderId)
+	}
+	return entry
+}
+
+func (m *consensusModule) writeEntryToWAL(entry *typesCons.WALEntry) error {
+	// The state is recorded on every transition and again before messages are sent, which is often unchanged.
+	if proto.Equal(entry, m.walLastEntry) {
+		return nil
+	}
+	if err := m.wal.write(entry); err != nil {
+		return err
+	}
+	m.walLastEntry = entry
+	return nil
+}
+
+// Records a state transition, such as a new lock, high QC, view or timeout, that is not immediately followed by
+// a message leaving the node.
+func (m *consensusModule) writeStateToWAL() {
+	if err := m.writeToWAL(nil); err != nil {
+		m.nodeLogError(typesCons.ErrWriteWAL.Error(), err)
+	}
+}
+
+// Restores the in memory consensus state from the last entry in the WAL. Called when the module is created.
+func (m *consensusModule) restoreFromWAL() error {
+	entry, err := m.wal.readLastEntry()
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+
+	m.Height = entry.Height
+	m.Round = entry.Round
+	m.Step = entry.Step
+	m.Block = entry.Block
+	m.LockedQC = entry.LockedQc
+	m.HighPrepareQC = entry.HighPrepareQc
+	m.TimeoutQC = entry.TimeoutQc
+	m.appHash = entry.AppHash
+	m.lastBlockHash = entry.LastBlockHash
+	m.lastCommitQC = entry.LastCommitQc
+	m.walLastVote = entry.Vote
+	m.walLastEntry = entry
+	m.lastMissedValidators = entry.LastMissedValidators
+	if len(entry.Validators) > 0 {
+		m.setValidatorMap(validatorListToMap(entry.Validators))
+	}
+	if entry.LeaderId != 0 {
+		leaderId := typesCons.NodeId(entry.LeaderId)
+		m.LeaderId = &leaderId
+	}
+
+	// The node crashed after committing the block but before it started the next height, which is where it resumes
+	if entry.Committed {
+		m.Height++
+		m.Round = 0
+		m.Step = NewRound
+		m.Block = nil
+		m.LockedQC = nil
+		m.HighPrepareQC = nil
+		m.TimeoutQC = nil
+		m.LeaderId = nil
+	}
+
+	return nil
+}
+
+// Replays the side effects of the restored state once the rest of the node is running. Called when the module is started.
+func (m *consensusModule) replayWAL() error {
+	if m.Height == 0 {
+		return nil
+	}
+	m.nodeLog(typesCons.ReplayingWAL(m.Height, m.Round, m.Step))
+
+	// The in-flight block was applied to a utility context that did not survive the crash. Chained mode does not
+	// use the WAL; see `Create`.
+	if m.Block != nil && m.Step > Prepare {
+		if err := m.updateUtilityContext(); err != nil {
+			return err
+		}
+		appHash, validatorUpdates, err := m.utilityContext.ApplyBlock(int64(m.Height), m.Block.BlockHeader.ProposerAddress, m.Block.Transactions, m.lastMissedValidators)
+		if err != nil {
+			return err
+		}
+		if m.Block.BlockHeader.AppHash != hex.EncodeToString(appHash) {
+			return typesCons.ErrInvalidAppHash(m.Block.BlockHeader.AppHash, hex.EncodeToString(appHash))
+		}
+		m.validatorUpdates = validatorUpdates
+	}
+
+	// The last vote may have been lost in the crash. Resending the exact same vote is always safe.
+	if m.walLastVote != nil && m.walLastVote.Height == m.Height {
+		m.sendToNode(m.walLastVote)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ module github.com/pokt-network/pocket
 go 1.18
 
 require (
+	filippo.io/edwards25519 v1.0.0-rc.1
 	github.com/ProtonMail/go-ecvrf v0.0.1
 	github.com/golang/mock v1.6.0
 	github.com/jackc/pgx/v4 v4.15.0
@@ -38,7 +39,6 @@ require (
 )
 
 require (
-	filippo.io/edwards25519 v1.0.0-rc.1 // indirect
 	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
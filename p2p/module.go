@@ -46,3 +46,11 @@ func (m *p2pModule) Broadcast(msg *anypb.Any, topic types.PocketTopic) error {
 func (m *p2pModule) Send(addr cryptoPocket.Address, msg *anypb.Any, topic types.PocketTopic) error {
 	panic("Send not implemented")
 }
+
+func (m *p2pModule) UpdateAddrBook(validators modules.ValidatorMap) error {
+	panic("UpdateAddrBook not implemented")
+}
+
+func (m *p2pModule) HandleAddrBookEvent(event *types.AddrBookEvent) error {
+	panic("HandleAddrBookEvent not implemented")
+}
@@ -0,0 +1,237 @@
+package pre2p
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"time"
+
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Every TCP connection between peers starts with an authenticated key exchange modelled after the Noise XX pattern
+// and Station-to-Station, in which the static keys are the nodes' ed25519 keys:
+//
+//  1. Both sides send an ephemeral X25519 public key.
+//  2. Both sides derive a pair of ChaCha20-Poly1305 keys, one per direction, from the ephemeral Diffie-Hellman
+//     secret and the hash of the handshake transcript.
+//  3. Both sides send their ed25519 public key and their signature of the transcript hash, encrypted.
+//
+// The signatures bind the ephemeral keys to the nodes' identities, so a connection is only established with the
+// holder of the expected key and every message on it is authenticated and encrypted. Since the ephemeral keys are
+// discarded, recorded traffic cannot be decrypted even if a node's key is later compromised.
+const (
+	handshakeProtocolName = "pocket/pre2p/handshake/v1/X25519_ChaChaPoly_SHA256_Ed25519"
+	handshakeAuthDomain   = "pocket/pre2p/handshake/v1/auth"
+
+	handshakeInitiator byte = 0
+	handshakeResponder byte = 1
+
+	handshakeAuthMsgLen = ed25519.PublicKeySize + ed25519.SignatureSize
+)
+
+var (
+	ErrUnexpectedPeerKey = errors.New("peer authenticated with an unexpected key")
+	ErrUnknownPeerKey    = errors.New("peer authenticated with a key that is not in the address book")
+	ErrInvalidPeerAuth   = errors.New("peer signature over the handshake is invalid")
+	ErrNonceExhausted    = errors.New("too many messages were sent on the connection")
+)
+
+// A connection on which every message is length prefixed and encrypted with the keys agreed upon in the handshake.
+// Reads and writes are not safe for concurrent use, but a read and a write can happen concurrently.
+type secureConn struct {
+	net.Conn
+	peerPublicKey cryptoPocket.PublicKey
+
+	sendCipher, recvCipher cipher.AEAD
+	sendNonce, recvNonce   uint64
+}
+
+// Performs the handshake as the dialing side. `peerPublicKey` is the key the peer must authenticate with.
+func dialHandshake(conn net.Conn, privateKey cryptoPocket.PrivateKey, peerPublicKey cryptoPocket.PublicKey, timeout time.Duration) (*secureConn, error) {
+	return handshake(conn, privateKey, handshakeInitiator, timeout, func(publicKey cryptoPocket.PublicKey) error {
+		if !publicKey.Equals(peerPublicKey) {
+			return ErrUnexpectedPeerKey
+		}
+		return nil
+	})
+}
+
+// Performs the handshake as the accepting side. `isKnownPeer` is nil if any peer is accepted.
+func acceptHandshake(conn net.Conn, privateKey cryptoPocket.PrivateKey, isKnownPeer func(cryptoPocket.PublicKey) bool, timeout time.Duration) (*secureConn, error) {
+	return handshake(conn, privateKey, handshakeResponder, timeout, func(publicKey cryptoPocket.PublicKey) error {
+		if isKnownPeer != nil && !isKnownPeer(publicKey) {
+			return ErrUnknownPeerKey
+		}
+		return nil
+	})
+}
+
+func handshake(conn net.Conn, privateKey cryptoPocket.PrivateKey, role byte, timeout time.Duration, verifyPeerKey func(cryptoPocket.PublicKey) error) (*secureConn, error) {
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	// 1. Exchange ephemeral keys
+	ephemeralPrivateKey := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(ephemeralPrivateKey); err != nil {
+		return nil, err
+	}
+	ephemeralPublicKey, err := curve25519.X25519(ephemeralPrivateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	peerEphemeralPublicKey, err := exchange(conn, ephemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Derive the keys of both directions
+	sharedSecret, err := curve25519.X25519(ephemeralPrivateKey, peerEphemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	initiatorEphemeralPublicKey, responderEphemeralPublicKey := ephemeralPublicKey, peerEphemeralPublicKey
+	if role == handshakeResponder {
+		initiatorEphemeralPublicKey, responderEphemeralPublicKey = peerEphemeralPublicKey, ephemeralPublicKey
+	}
+	transcriptHash := sha256.Sum256(bytes.Join([][]byte{[]byte(handshakeProtocolName), initiatorEphemeralPublicKey, responderEphemeralPublicKey}, nil))
+
+	keys := make([]byte, 2*chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, transcriptHash[:], []byte(handshakeProtocolName)), keys); err != nil {
+		return nil, err
+	}
+	initiatorKey, responderKey := keys[:chacha20poly1305.KeySize], keys[chacha20poly1305.KeySize:]
+	sendKey, recvKey := initiatorKey, responderKey
+	if role == handshakeResponder {
+		sendKey, recvKey = responderKey, initiatorKey
+	}
+
+	secure := &secureConn{
+		Conn: conn,
+	}
+	if secure.sendCipher, err = chacha20poly1305.New(sendKey); err != nil {
+		return nil, err
+	}
+	if secure.recvCipher, err = chacha20poly1305.New(recvKey); err != nil {
+		return nil, err
+	}
+
+	// 3. Authenticate both sides; the role is signed so a signature cannot be reflected back to its signer
+	signature, err := privateKey.Sign(handshakeAuthBytes(transcriptHash[:], role))
+	if err != nil {
+		return nil, err
+	}
+	authMsg := bytes.Join([][]byte{privateKey.PublicKey().Bytes(), signature}, nil)
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- secure.writeMessage(authMsg)
+	}()
+	peerAuthMsg, err := secure.readMessage(handshakeAuthMsgLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := <-writeErr; err != nil {
+		return nil, err
+	}
+	if len(peerAuthMsg) != handshakeAuthMsgLen {
+		return nil, ErrInvalidPeerAuth
+	}
+
+	peerPublicKey, err := cryptoPocket.NewPublicKeyFromBytes(peerAuthMsg[:ed25519.PublicKeySize])
+	if err != nil {
+		return nil, err
+	}
+	if !peerPublicKey.Verify(handshakeAuthBytes(transcriptHash[:], 1-role), peerAuthMsg[ed25519.PublicKeySize:]) {
+		return nil, ErrInvalidPeerAuth
+	}
+	if err := verifyPeerKey(peerPublicKey); err != nil {
+		return nil, err
+	}
+	secure.peerPublicKey = peerPublicKey
+
+	return secure, nil
+}
+
+// Sends `data` to the peer while reading the same number of bytes from it.
+func exchange(conn net.Conn, data []byte) ([]byte, error) {
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(data)
+		writeErr <- err
+	}()
+
+	peerData := make([]byte, len(data))
+	if _, err := io.ReadFull(conn, peerData); err != nil {
+		return nil, err
+	}
+	if err := <-writeErr; err != nil {
+		return nil, err
+	}
+	return peerData, nil
+}
+
+func handshakeAuthBytes(transcriptHash []byte, role byte) []byte {
+	return bytes.Join([][]byte{[]byte(handshakeAuthDomain), {role}, transcriptHash}, nil)
+}
+
+// The address of the peer, as verified during the handshake.
+func (c *secureConn) peerAddress() cryptoPocket.Address {
+	return c.peerPublicKey.Address()
+}
+
+// The length prefix is authenticated as additional data.
+func (c *secureConn) writeMessage(data []byte) error {
+	nonce, err := nextNonce(&c.sendNonce)
+	if err != nil {
+		return err
+	}
+	frameLen := make([]byte, frameLenBytes)
+	binary.BigEndian.PutUint32(frameLen, uint32(len(data)+c.sendCipher.Overhead()))
+
+	buffers := net.Buffers{frameLen, c.sendCipher.Seal(nil, nonce, data, frameLen)}
+	_, err = buffers.WriteTo(c.Conn)
+	return err
+}
+
+func (c *secureConn) readMessage(maxMessageBytes uint32) ([]byte, error) {
+	ciphertext, err := readFrame(c.Conn, maxMessageBytes+uint32(c.recvCipher.Overhead()))
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := nextNonce(&c.recvNonce)
+	if err != nil {
+		return nil, err
+	}
+	frameLen := make([]byte, frameLenBytes)
+	binary.BigEndian.PutUint32(frameLen, uint32(len(ciphertext)))
+
+	data, err := c.recvCipher.Open(ciphertext[:0], nonce, ciphertext, frameLen)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting message: %v", err)
+	}
+	return data, nil
+}
+
+// Nonces are a counter of the messages sent in one direction, which never repeats for a given key.
+func nextNonce(counter *uint64) ([]byte, error) {
+	if *counter == math.MaxUint64 {
+		return nil, ErrNonceExhausted
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], *counter)
+	*counter++
+	return nonce, nil
+}
@@ -5,7 +5,10 @@ package pre2p
 // to be a "real" replacement for now.
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"sync"
 
 	"github.com/pokt-network/pocket/p2p/pre2p/raintree"
 	"github.com/pokt-network/pocket/p2p/pre2p/stdnetwork"
@@ -21,34 +24,55 @@ import (
 
 var _ modules.P2PModule = &p2pModule{}
 
+var ErrNetworkNotStarted = errors.New("the network module has not been started")
+
+// The topics of the events received from the network that are published on the bus. The other topics, such as
+// `ADDRBOOK_EVENT_TOPIC`, are only published by the local modules.
+var networkTopics = map[types.PocketTopic]struct{}{
+	types.PocketTopic_CONSENSUS_MESSAGE_TOPIC: {},
+	types.PocketTopic_DEBUG_TOPIC:             {}, // Sent by the debug client (see `app/client`)
+}
+
 type p2pModule struct {
 	bus       modules.Bus
 	p2pConfig *config.Pre2PConfig
 
-	listener typesPre2P.Transport
-	address  cryptoPocket.Address
+	listener   typesPre2P.Transport
+	address    cryptoPocket.Address
+	privateKey cryptoPocket.PrivateKey
 
-	network typesPre2P.Network
+	addrBookMu sync.Mutex   // Serializes the changes to the address book of `network`, which is created once the module is started
+	networkMu  sync.RWMutex // Guards `network` itself, which is read by the listener goroutine; see `getNetwork`
+	network    typesPre2P.Network
+
+	knownPeersMu sync.RWMutex
+	knownPeers   map[string]struct{} // Hex encoded public keys of the peers in the address book
 }
 
 func Create(cfg *config.Config) (m modules.P2PModule, err error) {
 	log.Println("Creating network module")
 
-	l, err := CreateListener(cfg.Pre2P)
-	if err != nil {
+	if err := cfg.Pre2P.ValidateAndHydrate(); err != nil {
 		return nil, err
 	}
 
-	m = &p2pModule{
+	p2pMod := &p2pModule{
 		p2pConfig: cfg.Pre2P,
 
-		listener: l,
-		address:  cfg.PrivateKey.Address(),
+		listener:   nil, // Created below since it needs to look up the address book
+		address:    cfg.PrivateKey.Address(),
+		privateKey: cfg.PrivateKey,
 
 		network: nil,
+
+		knownPeers: make(map[string]struct{}),
+	}
+
+	if p2pMod.listener, err = CreateListener(cfg.Pre2P, cfg.PrivateKey, p2pMod.isKnownPeer); err != nil {
+		return nil, err
 	}
 
-	return m, nil
+	return p2pMod, nil
 }
 
 func (m *p2pModule) SetBus(bus modules.Bus) {
@@ -65,25 +89,21 @@ func (m *p2pModule) GetBus() modules.Bus {
 func (m *p2pModule) Start() error {
 	log.Println("Starting network module")
 
-	addrBook, err := ValidatorMapToAddrBook(m.p2pConfig, m.bus.GetConsensusModule().ValidatorMap())
-	if err != nil {
+	if err := m.UpdateAddrBook(m.bus.GetConsensusModule().ValidatorMap()); err != nil {
 		return err
 	}
 
-	if m.p2pConfig.UseRainTree {
-		m.network = raintree.NewRainTreeNetwork(m.address, addrBook)
-	} else {
-		m.network = stdnetwork.NewNetwork(addrBook)
-	}
-
 	go func() {
 		for {
-			data, err := m.listener.Read()
+			data, sender, err := m.listener.Read()
+			if errors.Is(err, ErrTransportClosed) {
+				return
+			}
 			if err != nil {
 				log.Println("Error reading data from connection: ", err)
 				continue
 			}
-			go m.handleNetworkMessage(data)
+			go m.handleNetworkMessage(data, sender)
 		}
 	}()
 
@@ -95,6 +115,9 @@ func (m *p2pModule) Stop() error {
 	if err := m.listener.Close(); err != nil {
 		return err
 	}
+	if network := m.getNetwork(); network != nil {
+		closeDialers(network.GetAddrBook())
+	}
 	return nil
 }
 
@@ -107,8 +130,12 @@ func (m *p2pModule) Broadcast(msg *anypb.Any, topic types.PocketTopic) error {
 	if err != nil {
 		return err
 	}
+	network := m.getNetwork()
+	if network == nil {
+		return ErrNetworkNotStarted
+	}
 	log.Println("broadcasting message to network")
-	return m.network.NetworkBroadcast(data)
+	return network.NetworkBroadcast(data)
 }
 
 func (m *p2pModule) Send(addr cryptoPocket.Address, msg *anypb.Any, topic types.PocketTopic) error {
@@ -121,11 +148,132 @@ func (m *p2pModule) Send(addr cryptoPocket.Address, msg *anypb.Any, topic types.
 		return err
 	}
 
-	return m.network.NetworkSend(data, addr)
+	network := m.getNetwork()
+	if network == nil {
+		return ErrNetworkNotStarted
+	}
+	return network.NetworkSend(data, addr)
 }
 
-func (m *p2pModule) handleNetworkMessage(networkMsgData []byte) {
-	appMsgData, err := m.network.HandleNetworkData(networkMsgData)
+// The network is only created once. Afterwards, the differences between `validators` and the current address book are
+// applied to it, so the connections to the peers that did not change and the state of the network (e.g. the RainTree
+// mempool used to dedupe messages) are kept.
+func (m *p2pModule) UpdateAddrBook(validators modules.ValidatorMap) error {
+	m.addrBookMu.Lock()
+	defer m.addrBookMu.Unlock()
+
+	if m.network == nil {
+		addrBook, err := ValidatorMapToAddrBook(m.p2pConfig, m.privateKey, validators)
+		if err != nil {
+			return err
+		}
+		var network typesPre2P.Network
+		if m.p2pConfig.UseRainTree {
+			network = raintree.NewRainTreeNetwork(m.address, addrBook, m.p2pConfig)
+		} else {
+			network = stdnetwork.NewNetwork(addrBook)
+		}
+		m.networkMu.Lock()
+		m.network = network
+		m.networkMu.Unlock()
+		m.setKnownPeers(addrBook)
+		return nil
+	}
+
+	removedPeers := make(map[string]*typesPre2P.NetworkPeer)
+	for _, peer := range m.network.GetAddrBook() {
+		removedPeers[peer.Address.String()] = peer
+	}
+
+	for _, v := range validators {
+		pubKey, err := cryptoPocket.NewPublicKeyFromBytes(v.PublicKey)
+		if err != nil {
+			log.Println("[WARN] Error connecting to validator: ", err)
+			continue
+		}
+		addr := pubKey.Address().String()
+		existingPeer, ok := removedPeers[addr]
+		delete(removedPeers, addr)
+		// The address is derived from the public key, so only the service url of an existing peer can change
+		if ok && existingPeer.ServiceUrl == v.ServiceUrl {
+			continue
+		}
+
+		changeType := types.AddrBookChangeType_ADDRBOOK_CHANGE_ADD
+		if ok {
+			changeType = types.AddrBookChangeType_ADDRBOOK_CHANGE_UPDATE
+		}
+		if err := m.applyAddrBookChange(&types.AddrBookChange{
+			Type:       changeType,
+			PublicKey:  v.PublicKey,
+			ServiceUrl: v.ServiceUrl,
+		}); err != nil {
+			log.Println("[WARN] Error connecting to validator: ", err)
+		}
+	}
+
+	for _, peer := range removedPeers {
+		if err := m.network.RemovePeerFromAddrBook(peer); err != nil {
+			return err
+		}
+	}
+
+	m.setKnownPeers(m.network.GetAddrBook())
+	return nil
+}
+
+func (m *p2pModule) HandleAddrBookEvent(event *types.AddrBookEvent) error {
+	m.addrBookMu.Lock()
+	defer m.addrBookMu.Unlock()
+
+	if m.network == nil {
+		return ErrNetworkNotStarted
+	}
+	defer func() {
+		m.setKnownPeers(m.network.GetAddrBook())
+	}()
+
+	for _, change := range event.Changes {
+		if err := m.applyAddrBookChange(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Must be called with `m.addrBookMu` held.
+func (m *p2pModule) applyAddrBookChange(change *types.AddrBookChange) error {
+	if change.Type == types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE {
+		return m.network.RemovePeerFromAddrBook(&typesPre2P.NetworkPeer{Address: change.Address})
+	}
+
+	peer, err := createNetworkPeer(m.p2pConfig, m.privateKey, change.PublicKey, change.ServiceUrl)
+	if err != nil {
+		return err
+	}
+	switch change.Type {
+	case types.AddrBookChangeType_ADDRBOOK_CHANGE_ADD:
+		err = m.network.AddPeerToAddrBook(peer)
+	case types.AddrBookChangeType_ADDRBOOK_CHANGE_UPDATE:
+		err = m.network.UpdatePeerInAddrBook(peer)
+	default:
+		err = fmt.Errorf("unknown address book change type: %s", change.Type)
+	}
+	// The dialer is only owned by the network if the change was applied
+	if err != nil {
+		closeDialers(typesPre2P.AddrBook{peer})
+	}
+	return err
+}
+
+func (m *p2pModule) handleNetworkMessage(networkMsgData []byte, sender cryptoPocket.Address) {
+	network := m.getNetwork()
+	if network == nil {
+		log.Println("Error handling raw data: ", ErrNetworkNotStarted)
+		return
+	}
+
+	appMsgData, err := network.HandleNetworkData(networkMsgData, sender)
 	if err != nil {
 		log.Println("Error handling raw data: ", err)
 		return
@@ -144,6 +292,11 @@ func (m *p2pModule) handleNetworkMessage(networkMsgData []byte) {
 		return
 	}
 
+	if _, ok := networkTopics[networkMessage.Topic]; !ok {
+		log.Printf("[WARN] Dropping network message from %s with topic %s\n", sender, networkMessage.Topic)
+		return
+	}
+
 	event := types.PocketEvent{
 		Topic: networkMessage.Topic,
 		Data:  networkMessage.Data,
@@ -151,3 +304,31 @@ func (m *p2pModule) handleNetworkMessage(networkMsgData []byte) {
 
 	m.GetBus().PublishEventToBus(&event)
 }
+
+// Returns the network, which is nil until the module is started. The methods that change the address book own
+// `m.addrBookMu`, which also serializes the creation of the network, so they access `m.network` directly.
+func (m *p2pModule) getNetwork() typesPre2P.Network {
+	m.networkMu.RLock()
+	defer m.networkMu.RUnlock()
+	return m.network
+}
+
+func (m *p2pModule) setKnownPeers(addrBook typesPre2P.AddrBook) {
+	knownPeers := make(map[string]struct{}, len(addrBook))
+	for _, peer := range addrBook {
+		knownPeers[peer.PublicKey.String()] = struct{}{}
+	}
+
+	m.knownPeersMu.Lock()
+	defer m.knownPeersMu.Unlock()
+	m.knownPeers = knownPeers
+}
+
+// Whether the peer with `publicKey` is in the address book, which is the only peers inbound connections are accepted
+// from if `RequireKnownPeers` is set in the config.
+func (m *p2pModule) isKnownPeer(publicKey cryptoPocket.PublicKey) bool {
+	m.knownPeersMu.RLock()
+	defer m.knownPeersMu.RUnlock()
+	_, ok := m.knownPeers[publicKey.String()]
+	return ok
+}
@@ -0,0 +1,181 @@
+package pre2p
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	typesPre2P "github.com/pokt-network/pocket/p2p/pre2p/types"
+	"github.com/pokt-network/pocket/shared/modules"
+	modulesMock "github.com/pokt-network/pocket/shared/modules/mocks"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUpdateAddrBook(t *testing.T) {
+	for _, useRainTree := range []bool{true, false} {
+		t.Run(fmt.Sprintf("useRainTree=%t", useRainTree), func(t *testing.T) {
+			p2pMod, genesisState := createAddrBookTestModule(t, useRainTree)
+			validators := genesisState.Validators
+
+			require.NoError(t, p2pMod.UpdateAddrBook(createValidatorMap(validators[0], validators[1], validators[2])))
+			addrBook := getAddrBookByServiceUrl(p2pMod)
+			require.Len(t, addrBook, 3)
+
+			// Only the validators that changed are applied to the address book, so the peers of the others are kept
+			updatedValidator := proto.Clone(validators[2]).(*genesis.Validator)
+			updatedValidator.ServiceUrl = "updated"
+			require.NoError(t, p2pMod.UpdateAddrBook(createValidatorMap(validators[0], updatedValidator, validators[3])))
+			updatedAddrBook := getAddrBookByServiceUrl(p2pMod)
+			require.Len(t, updatedAddrBook, 3)
+			require.Same(t, addrBook[validators[0].ServiceUrl], updatedAddrBook[validators[0].ServiceUrl])
+			require.Contains(t, updatedAddrBook, "updated")
+			require.Contains(t, updatedAddrBook, validators[3].ServiceUrl)
+			require.NotContains(t, updatedAddrBook, validators[1].ServiceUrl)
+			require.NotContains(t, updatedAddrBook, validators[2].ServiceUrl)
+
+			// The peers that were removed are no longer accepted connections from
+			require.True(t, p2pMod.isKnownPeer(keys[3].PublicKey()))
+			require.False(t, p2pMod.isKnownPeer(keys[1].PublicKey()))
+		})
+	}
+}
+
+func TestHandleAddrBookEvent(t *testing.T) {
+	for _, useRainTree := range []bool{true, false} {
+		t.Run(fmt.Sprintf("useRainTree=%t", useRainTree), func(t *testing.T) {
+			p2pMod, genesisState := createAddrBookTestModule(t, useRainTree)
+			validators := genesisState.Validators
+
+			addValidator := &types.AddrBookChange{
+				Type:       types.AddrBookChangeType_ADDRBOOK_CHANGE_ADD,
+				PublicKey:  validators[3].PublicKey,
+				ServiceUrl: validators[3].ServiceUrl,
+			}
+			require.Equal(t, ErrNetworkNotStarted, p2pMod.HandleAddrBookEvent(&types.AddrBookEvent{
+				Changes: []*types.AddrBookChange{addValidator},
+			}))
+
+			require.NoError(t, p2pMod.UpdateAddrBook(createValidatorMap(validators[0], validators[1], validators[2])))
+			require.NoError(t, p2pMod.HandleAddrBookEvent(&types.AddrBookEvent{
+				Changes: []*types.AddrBookChange{
+					addValidator,
+					{
+						Type:    types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE,
+						Address: validators[1].Address,
+					},
+					{
+						Type:       types.AddrBookChangeType_ADDRBOOK_CHANGE_UPDATE,
+						PublicKey:  validators[2].PublicKey,
+						ServiceUrl: "updated",
+					},
+				},
+			}))
+			addrBook := getAddrBookByServiceUrl(p2pMod)
+			require.Len(t, addrBook, 3)
+			require.Contains(t, addrBook, validators[0].ServiceUrl)
+			require.Contains(t, addrBook, validators[3].ServiceUrl)
+			require.Contains(t, addrBook, "updated")
+			require.True(t, p2pMod.isKnownPeer(keys[3].PublicKey()))
+			require.False(t, p2pMod.isKnownPeer(keys[1].PublicKey()))
+
+			// Changes are applied in order until one of them fails
+			require.Equal(t, typesPre2P.ErrPeerNotInAddrBook, p2pMod.HandleAddrBookEvent(&types.AddrBookEvent{
+				Changes: []*types.AddrBookChange{
+					{
+						Type:    types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE,
+						Address: validators[3].Address,
+					},
+					{
+						Type:    types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE,
+						Address: validators[1].Address,
+					},
+				},
+			}))
+			require.Len(t, p2pMod.network.GetAddrBook(), 2)
+			require.False(t, p2pMod.isKnownPeer(keys[3].PublicKey()))
+
+			require.Equal(t, typesPre2P.ErrPeerAlreadyInAddrBook, p2pMod.HandleAddrBookEvent(&types.AddrBookEvent{
+				Changes: []*types.AddrBookChange{{
+					Type:       types.AddrBookChangeType_ADDRBOOK_CHANGE_ADD,
+					PublicKey:  validators[0].PublicKey,
+					ServiceUrl: validators[0].ServiceUrl,
+				}},
+			}))
+			require.Error(t, p2pMod.HandleAddrBookEvent(&types.AddrBookEvent{
+				Changes: []*types.AddrBookChange{{Type: types.AddrBookChangeType_ADDRBOOK_CHANGE_UNKNOWN}},
+			}))
+		})
+	}
+}
+
+func createAddrBookTestModule(t *testing.T, useRainTree bool) (*p2pModule, *genesis.GenesisState) {
+	configs, genesisState := createConfigs(t, 4)
+	configs[0].Pre2P.UseRainTree = useRainTree
+	p2pMod, err := Create(configs[0])
+	require.NoError(t, err)
+	return p2pMod.(*p2pModule), genesisState
+}
+
+func createValidatorMap(validators ...*genesis.Validator) modules.ValidatorMap {
+	m := make(modules.ValidatorMap, len(validators))
+	for _, v := range validators {
+		m[hex.EncodeToString(v.Address)] = v
+	}
+	return m
+}
+
+func getAddrBookByServiceUrl(p2pMod *p2pModule) map[string]*typesPre2P.NetworkPeer {
+	addrBook := make(map[string]*typesPre2P.NetworkPeer)
+	for _, peer := range p2pMod.network.GetAddrBook() {
+		addrBook[peer.ServiceUrl] = peer
+	}
+	return addrBook
+}
+
+func TestNetworkAccessWhileStarting(t *testing.T) {
+	p2pMod, genesisState := createAddrBookTestModule(t, true)
+	validators := genesisState.Validators
+
+	// Messages sent while the network is created either fail because it is not started yet or use the new network
+	unknownAddr := keys[len(keys)-1].Address()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			err := p2pMod.Send(unknownAddr, nil, types.PocketTopic_CONSENSUS_MESSAGE_TOPIC)
+			require.Error(t, err)
+		}
+	}()
+	require.NoError(t, p2pMod.UpdateAddrBook(createValidatorMap(validators[0], validators[1])))
+	<-done
+	require.NotNil(t, p2pMod.getNetwork())
+}
+
+func TestNetworkMessageTopics(t *testing.T) {
+	p2pMod, genesisState := createAddrBookTestModule(t, false)
+	require.NoError(t, p2pMod.UpdateAddrBook(createValidatorMap(genesisState.Validators[0])))
+
+	busMock := modulesMock.NewMockBus(gomock.NewController(t))
+	published := make([]types.PocketTopic, 0)
+	busMock.EXPECT().PublishEventToBus(gomock.Any()).Do(func(e *types.PocketEvent) {
+		published = append(published, e.Topic)
+	}).AnyTimes()
+	p2pMod.SetBus(busMock)
+
+	// Address book changes and node signals from the network are not published on the bus
+	for _, topic := range []types.PocketTopic{
+		types.PocketTopic_CONSENSUS_MESSAGE_TOPIC,
+		types.PocketTopic_ADDRBOOK_EVENT_TOPIC,
+		types.PocketTopic_POCKET_NODE_TOPIC,
+		types.PocketTopic_DEBUG_TOPIC,
+	} {
+		data, err := proto.Marshal(&types.PocketEvent{Topic: topic})
+		require.NoError(t, err)
+		p2pMod.handleNetworkMessage(data, keys[1].Address())
+	}
+	require.Equal(t, []types.PocketTopic{types.PocketTopic_CONSENSUS_MESSAGE_TOPIC, types.PocketTopic_DEBUG_TOPIC}, published)
+}
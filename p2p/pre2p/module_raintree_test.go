@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"sort"
 	"sync"
 	"testing"
@@ -42,7 +43,7 @@ func TestRainTreeCompleteTwoNodes(t *testing.T) {
 	// 	       val_2
 	originatorNode := validatorId(t, 1)
 	var expectedCalls = TestRainTreeCommConfig{
-		validatorId(t, 1): {0, 0}, // Originator
+		validatorId(t, 1): {1, 1}, // Originator
 		validatorId(t, 2): {1, 1},
 	}
 	testRainTreeCalls(t, originatorNode, expectedCalls, false)
@@ -54,7 +55,7 @@ func TestRainTreeCompleteThreeNodes(t *testing.T) {
 	//   val_2        val_1     val_3
 	originatorNode := validatorId(t, 1)
 	var expectedCalls = TestRainTreeCommConfig{
-		validatorId(t, 1): {0, 0}, // Originator
+		validatorId(t, 1): {2, 2}, // Originator
 		validatorId(t, 2): {1, 1},
 		validatorId(t, 3): {1, 1},
 	}
@@ -70,9 +71,9 @@ func TestRainTreeCompleteFourNodes(t *testing.T) {
 	// 		    val_3                val_2             val_4
 	originatorNode := validatorId(t, 1)
 	var expectedCalls = TestRainTreeCommConfig{
-		validatorId(t, 1): {0, 0}, // Originator
-		validatorId(t, 2): {2, 2},
-		validatorId(t, 3): {2, 2},
+		validatorId(t, 1): {3, 3}, // Originator
+		validatorId(t, 2): {3, 3},
+		validatorId(t, 3): {3, 3},
 		validatorId(t, 4): {1, 1},
 	}
 	testRainTreeCalls(t, originatorNode, expectedCalls, false)
@@ -86,13 +87,13 @@ func TestRainTreeCompleteNineNodes(t *testing.T) {
 	// val_6        val_4     val_8        val_3        val_1     val_5     val_9        val_7     val_2
 	originatorNode := validatorId(t, 1)
 	var expectedCalls = TestRainTreeCommConfig{
-		validatorId(t, 1): {0, 0}, // Originator
+		validatorId(t, 1): {4, 4}, // Originator
 		validatorId(t, 2): {1, 1},
 		validatorId(t, 3): {1, 1},
-		validatorId(t, 4): {1, 1},
+		validatorId(t, 4): {3, 3},
 		validatorId(t, 5): {1, 1},
 		validatorId(t, 6): {1, 1},
-		validatorId(t, 7): {1, 1},
+		validatorId(t, 7): {3, 3},
 		validatorId(t, 8): {1, 1},
 		validatorId(t, 9): {1, 1},
 	}
@@ -109,28 +110,28 @@ func TestRainTreeCompleteEighteenNodes(t *testing.T) {
 	// val_13         val_11      val_16        val_9        val_7      val_12      val_17         val_15     val_8        val_7        val_5      val_10        val_3        val_1     val_6      val_11        val_9     val_2     val_1         val_17     val_4         val_15         val_13      val_18     val_5        val_3      val_14
 	originatorNode := validatorId(t, 1)
 	var expectedCalls = TestRainTreeCommConfig{
-		validatorId(t, 1):  {1, 1}, // Originator
+		validatorId(t, 1):  {7, 7}, // Originator, messaged by `val_17`
 		validatorId(t, 2):  {1, 1},
-		validatorId(t, 3):  {2, 2},
+		validatorId(t, 3):  {4, 4},
 		validatorId(t, 4):  {1, 1},
-		validatorId(t, 5):  {2, 2},
+		validatorId(t, 5):  {4, 4},
 		validatorId(t, 6):  {1, 1},
-		validatorId(t, 7):  {2, 2},
+		validatorId(t, 7):  {6, 6},
 		validatorId(t, 8):  {1, 1},
-		validatorId(t, 9):  {2, 2},
+		validatorId(t, 9):  {4, 4},
 		validatorId(t, 10): {1, 1},
-		validatorId(t, 11): {2, 2},
+		validatorId(t, 11): {4, 4},
 		validatorId(t, 12): {1, 1},
-		validatorId(t, 13): {2, 2},
+		validatorId(t, 13): {6, 6},
 		validatorId(t, 14): {1, 1},
-		validatorId(t, 15): {2, 2},
+		validatorId(t, 15): {4, 4},
 		validatorId(t, 16): {1, 1},
-		validatorId(t, 17): {2, 2},
+		validatorId(t, 17): {4, 4},
 		validatorId(t, 18): {1, 1},
 	}
 	// Note that the originator, `val_1` is also messaged by `val_17` outside of continuously
-	// demoting itself.
-	testRainTreeCalls(t, originatorNode, expectedCalls, true)
+	// demoting itself, but does not hand its own message back to the application.
+	testRainTreeCalls(t, originatorNode, expectedCalls, false)
 }
 
 func TestRainTreeCompleteTwentySevenNodes(t *testing.T) {
@@ -143,37 +144,91 @@ func TestRainTreeCompleteTwentySevenNodes(t *testing.T) {
 	// val_20         val_16      val_24         val_14         val_10      val_18      val_26         val_22      val_12         val_11        val_7      val_15        val_5        val_1     val_9      val_17         val_13     val_3     val_2         val_25     val_6         val_23         val_19      val_27     val_8        val_4      val_21
 	originatorNode := validatorId(t, 1)
 	var expectedCalls = TestRainTreeCommConfig{
-		validatorId(t, 1):  {0, 0}, // Originator
+		validatorId(t, 1):  {6, 6}, // Originator
 		validatorId(t, 2):  {1, 1},
 		validatorId(t, 3):  {1, 1},
-		validatorId(t, 4):  {1, 1},
+		validatorId(t, 4):  {3, 3},
 		validatorId(t, 5):  {1, 1},
 		validatorId(t, 6):  {1, 1},
-		validatorId(t, 7):  {1, 1},
+		validatorId(t, 7):  {3, 3},
 		validatorId(t, 8):  {1, 1},
 		validatorId(t, 9):  {1, 1},
-		validatorId(t, 10): {1, 1},
+		validatorId(t, 10): {5, 5},
 		validatorId(t, 11): {1, 1},
 		validatorId(t, 12): {1, 1},
-		validatorId(t, 13): {1, 1},
+		validatorId(t, 13): {3, 3},
 		validatorId(t, 14): {1, 1},
 		validatorId(t, 15): {1, 1},
-		validatorId(t, 16): {1, 1},
+		validatorId(t, 16): {3, 3},
 		validatorId(t, 17): {1, 1},
 		validatorId(t, 18): {1, 1},
-		validatorId(t, 19): {1, 1},
+		validatorId(t, 19): {5, 5},
 		validatorId(t, 20): {1, 1},
 		validatorId(t, 21): {1, 1},
-		validatorId(t, 22): {1, 1},
+		validatorId(t, 22): {3, 3},
 		validatorId(t, 23): {1, 1},
 		validatorId(t, 24): {1, 1},
-		validatorId(t, 25): {1, 1},
+		validatorId(t, 25): {3, 3},
 		validatorId(t, 26): {1, 1},
 		validatorId(t, 27): {1, 1},
 	}
 	testRainTreeCalls(t, originatorNode, expectedCalls, false)
 }
 
+// ### RainTree Packet Loss Tests ###
+
+func TestRainTreeCoverageWithPacketLoss(t *testing.T) {
+	testCases := []struct {
+		numValidators int
+		dropRate      float64
+	}{
+		{4, 0.25},
+		{9, 0.25},
+		{18, 0.25},
+		{27, 0.25},
+		{27, 0.5},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("n=%d/drop=%.2f", testCase.numValidators, testCase.dropRate), func(t *testing.T) {
+			lossConfig := make(TestRainTreeLossConfig, testCase.numValidators)
+			for i := 1; i <= testCase.numValidators; i++ {
+				lossConfig[validatorId(t, i)] = testRainTreeLoss{0, testCase.dropRate}
+			}
+			testRainTreeCoverage(t, validatorId(t, 1), lossConfig)
+		})
+	}
+}
+
+func TestRainTreeCoverageWithDroppedSubtree(t *testing.T) {
+	// The message from the originator to `val_4` is dropped, so neither `val_4` nor its subtree receive the
+	// message through the tree (see `TestRainTreeCompleteNineNodes`)
+	// 	                              val_1
+	// 	         ┌──────────────────────┴────────────┬────────────────────────────────┐
+	//         (val_4)                             val_1                            val_7
+	//   ┌───────┴────┬─────────┐            ┌───────┴────┬─────────┐         ┌───────┴────┬─────────┐
+	// (val_6)      (val_4)   (val_8)      val_3        val_1     val_5     val_9        val_7     val_2
+	lossConfig := make(TestRainTreeLossConfig, 9)
+	for i := 1; i <= 9; i++ {
+		lossConfig[validatorId(t, i)] = testRainTreeLoss{0, 0}
+	}
+	lossConfig[validatorId(t, 4)] = testRainTreeLoss{1, 0}
+	testRainTreeCoverage(t, validatorId(t, 1), lossConfig)
+}
+
+func TestRainTreeCoverageWithOfflineValidators(t *testing.T) {
+	// Both targets of the originator at the top level, and one of its targets at the level below, are offline
+	// (see `TestRainTreeCompleteTwentySevenNodes`)
+	lossConfig := make(TestRainTreeLossConfig, 27)
+	for i := 1; i <= 27; i++ {
+		lossConfig[validatorId(t, i)] = testRainTreeLoss{0, 0}
+	}
+	for _, i := range []int{7, 10, 19} {
+		lossConfig[validatorId(t, i)] = testRainTreeLoss{0, 1}
+	}
+	testRainTreeCoverage(t, validatorId(t, 1), lossConfig)
+}
+
 // ### RainTree Unit Helpers - To remove redundancy of code in the unit tests ###
 
 func testRainTreeCalls(t *testing.T, origNode string, testCommConfig TestRainTreeCommConfig, isOriginatorPinged bool) {
@@ -181,6 +236,12 @@ func testRainTreeCalls(t *testing.T, origNode string, testCommConfig TestRainTre
 	numValidators := len(testCommConfig)
 	configs, genesisState := createConfigs(t, numValidators)
 
+	// Every node also receives the message from its neighbours through the redundancy layer
+	numNeighbours := uint16(2)
+	if numValidators < 3 {
+		numNeighbours = uint16(numValidators - 1)
+	}
+
 	// Test configurations
 	var messageHandeledWaitGroup sync.WaitGroup
 	if isOriginatorPinged {
@@ -194,10 +255,48 @@ func testRainTreeCalls(t *testing.T, origNode string, testCommConfig TestRainTre
 	connMocks := make(map[string]typesPre2P.Transport)
 	busMocks := make(map[string]modules.Bus)
 	for valId, expectedCall := range testCommConfig {
-		connMocks[valId] = prepareConnMock(t, expectedCall.numNetworkReads, expectedCall.numNetworkWrites)
+		connMocks[valId] = prepareConnMock(t, expectedCall.numNetworkReads+numNeighbours, expectedCall.numNetworkWrites+numNeighbours, numValidators)
 		busMocks[valId] = prepareBusMock(t, &messageHandeledWaitGroup, consensusMock)
 	}
 
+	broadcastAndWait(t, configs, origNode, connMocks, busMocks, &messageHandeledWaitGroup)
+}
+
+// Every online node but the originator handles the message exactly once, despite the packet loss on the way to every
+// node.
+func testRainTreeCoverage(t *testing.T, origNode string, lossConfig TestRainTreeLossConfig) {
+	// Network configurations
+	numValidators := len(lossConfig)
+	configs, genesisState := createConfigs(t, numValidators)
+	for _, cfg := range configs {
+		cfg.Pre2P.RainTreeCleanupDelayMsec = 10
+		cfg.Pre2P.RainTreeCleanupMaxRounds = 20
+		cfg.Pre2P.RainTreeAckTimeoutMsec = 10
+	}
+
+	// Test configurations
+	numOffline := 0
+	for _, loss := range lossConfig {
+		if loss.dropRate == 1 {
+			numOffline++
+		}
+	}
+	var messageHandeledWaitGroup sync.WaitGroup
+	messageHandeledWaitGroup.Add(numValidators - numOffline - 1) // -1 because the originator node implicitly handles the message
+
+	// Network initialization
+	consensusMock := prepareConsensusMock(t, genesisState)
+	connMocks := make(map[string]typesPre2P.Transport)
+	busMocks := make(map[string]modules.Bus)
+	for valId, loss := range lossConfig {
+		connMocks[valId] = newLossyTransport(loss.numDroppedFirst, loss.dropRate, int64(len(connMocks)))
+		busMocks[valId] = prepareBusMock(t, &messageHandeledWaitGroup, consensusMock)
+	}
+
+	broadcastAndWait(t, configs, origNode, connMocks, busMocks, &messageHandeledWaitGroup)
+}
+
+func broadcastAndWait(t *testing.T, configs []*config.Config, origNode string, connMocks map[string]typesPre2P.Transport, busMocks map[string]modules.Bus, messageHandeledWaitGroup *sync.WaitGroup) {
 	// Module injection
 	p2pModules := prepareP2PModules(t, configs)
 	for validatorId, p2pMod := range p2pModules {
@@ -205,7 +304,7 @@ func testRainTreeCalls(t *testing.T, origNode string, testCommConfig TestRainTre
 		p2pMod.SetBus(busMocks[validatorId])
 		p2pMod.Start()
 		for _, peer := range p2pMod.network.GetAddrBook() {
-			peer.Dialer = connMocks[peer.ServiceUrl]
+			peer.Dialer = &testDialer{Transport: connMocks[peer.ServiceUrl], sender: p2pMod.address}
 		}
 		defer p2pMod.Stop()
 	}
@@ -240,12 +339,22 @@ const (
 	testChannelSize        = 10000
 )
 
-// TODO(olshansky): Add configurations tests for dead and partially visible nodes
+// The number of messages every node reads and that are written to it, including the ACKs of the messages it sends
+// through the tree. The messages of the redundancy layer are accounted for by `testRainTreeCalls`.
+// TODO(olshansky): Add configurations tests for partially visible nodes
 type TestRainTreeCommConfig map[string]struct {
 	numNetworkReads  uint16
 	numNetworkWrites uint16
 }
 
+// The packet loss on the way to every node
+type TestRainTreeLossConfig map[string]testRainTreeLoss
+
+type testRainTreeLoss struct {
+	numDroppedFirst int     // The first messages sent to the node are always dropped...
+	dropRate        float64 // ...and every following message is dropped with this probability, where 1 is offline
+}
+
 var keys []cryptoPocket.PrivateKey
 
 func init() {
@@ -273,7 +382,8 @@ func generateKeys(_ *testing.T, numValidators int) []cryptoPocket.PrivateKey {
 
 // A mock of the application specific to know if a message was sent to be handled by the application
 // INVESTIGATE(olshansky): Double check that how the expected calls are counted is accurate per the
-//                         expectation with RainTree by comparing with Telemetry after updating specs.
+//
+//	expectation with RainTree by comparing with Telemetry after updating specs.
 func prepareBusMock(t *testing.T, wg *sync.WaitGroup, consensusMock *modulesMock.MockConsensusModule) *modulesMock.MockBus {
 	ctrl := gomock.NewController(t)
 	busMock := modulesMock.NewMockBus(ctrl)
@@ -307,15 +417,16 @@ func prepareConsensusMock(t *testing.T, genesisState *genesis.GenesisState) *mod
 // is a race condition here, but it is okay because our goal is to achieve max coverage with an upper limit
 // on the number of expected messages propagated.
 // INVESTIGATE(olshansky): Double check that how the expected calls are counted is accurate per the
-//                         expectation with RainTree by comparing with Telemetry after updating specs.
-func prepareConnMock(t *testing.T, expectedNumNetworkReads, expectedNumNetworkWrites uint16) typesPre2P.Transport {
+//
+//	expectation with RainTree by comparing with Telemetry after updating specs.
+func prepareConnMock(t *testing.T, expectedNumNetworkReads, expectedNumNetworkWrites uint16, numValidators int) typesPre2P.Transport {
 	testChannel := make(chan []byte, testChannelSize)
 	ctrl := gomock.NewController(t)
 	connMock := mocksPre2P.NewMockTransport(ctrl)
 
-	connMock.EXPECT().Read().DoAndReturn(func() ([]byte, error) {
-		data := <-testChannel
-		return data, nil
+	connMock.EXPECT().Read().DoAndReturn(func() ([]byte, cryptoPocket.Address, error) {
+		data, sender := splitTestMessage(<-testChannel)
+		return data, sender, nil
 	}).MaxTimes(int(expectedNumNetworkReads + 1)) // INVESTIGATE(olshansky): The +1 is necessary because there is one extra read of empty data by every channel...
 
 	connMock.EXPECT().Write(gomock.Any()).DoAndReturn(func(data []byte) error {
@@ -323,11 +434,74 @@ func prepareConnMock(t *testing.T, expectedNumNetworkReads, expectedNumNetworkWr
 		return nil
 	}).MaxTimes(int(expectedNumNetworkWrites))
 
-	connMock.EXPECT().Close().Return(nil).Times(1)
+	// The same mock is the listener of its node and the dialer of its node in the address book of every node,
+	// all of which are closed when the nodes are stopped.
+	connMock.EXPECT().Close().Return(nil).Times(1 + numValidators)
 
 	return connMock
 }
 
+// The dialer of a peer in the address book of `sender`. The in-memory transports of the tests are shared by all the
+// nodes writing to a peer, so the dialer prefixes every message with the address of `sender` for the transport to
+// return it from `Read`, as the authenticated TCP listener does.
+type testDialer struct {
+	typesPre2P.Transport
+	sender cryptoPocket.Address
+}
+
+func (d *testDialer) Write(data []byte) error {
+	return d.Transport.Write(append(append([]byte{}, d.sender...), data...))
+}
+
+func splitTestMessage(data []byte) ([]byte, cryptoPocket.Address) {
+	return data[cryptoPocket.AddressLen:], data[:cryptoPocket.AddressLen]
+}
+
+// An in-memory transport that drops messages on their way to its node.
+type lossyTransport struct {
+	data chan []byte
+
+	m               sync.Mutex
+	rand            *rand.Rand
+	numWrites       int
+	numDroppedFirst int
+	dropRate        float64
+}
+
+func newLossyTransport(numDroppedFirst int, dropRate float64, seed int64) *lossyTransport {
+	return &lossyTransport{
+		data:            make(chan []byte, testChannelSize),
+		rand:            rand.New(rand.NewSource(seed)),
+		numDroppedFirst: numDroppedFirst,
+		dropRate:        dropRate,
+	}
+}
+
+func (c *lossyTransport) IsListener() bool {
+	return true
+}
+
+func (c *lossyTransport) Read() ([]byte, cryptoPocket.Address, error) {
+	data, sender := splitTestMessage(<-c.data)
+	return data, sender, nil
+}
+
+// Dropped messages are written successfully, like a packet that is lost on the way to its destination.
+func (c *lossyTransport) Write(data []byte) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.numWrites++
+	if c.numWrites <= c.numDroppedFirst || c.rand.Float64() < c.dropRate {
+		return nil
+	}
+	c.data <- data
+	return nil
+}
+
+func (c *lossyTransport) Close() error {
+	return nil
+}
+
 func prepareP2PModules(t *testing.T, configs []*config.Config) (p2pModules map[string]*p2pModule) {
 	p2pModules = make(map[string]*p2pModule, len(configs))
 	for i, config := range configs {
@@ -20,9 +20,9 @@ const (
 	floatPrecision            = float64(0.0000001)
 )
 
-// Whenever `addrBook` changes, we also need to update `addrBookMap` and `addrList`
+// Computes `addrBookMap`, `addrList` and `maxNumLevels` from scratch out of `addrBook`. Once the network is created,
+// they are updated incrementally by `addPeer`, `removePeer` and `updatePeer` instead.
 func (n *rainTreeNetwork) processAddrBookUpdates() error {
-	// OPTIMIZE(olshansky): This is a very naive approach for now that recomputes everything every time that we can optimize later
 	n.addrBookMap = make(map[string]*typesPre2P.NetworkPeer, len(n.addrBook))
 	n.addrList = make([]string, len(n.addrBook))
 	for i, peer := range n.addrBook {
@@ -32,17 +32,77 @@ func (n *rainTreeNetwork) processAddrBookUpdates() error {
 	}
 	n.maxNumLevels = n.getMaxAddrBookLevels()
 
-	sort.Strings(n.addrList)
-	if i, ok := n.getSelfIndexInAddrBook(); ok {
-		// The list is sorted lexicographically above, but is reformatted below so this addr of this node
-		// is always the first in the list. This makes RainTree propagation easier to compute and interpret.
-		n.addrList = append(n.addrList[i:len(n.addrList)], n.addrList[0:i]...)
-	} else {
+	sort.Slice(n.addrList, func(i, j int) bool {
+		return n.addrListLess(n.addrList[i], n.addrList[j])
+	})
+	if !n.isSelfInAddrBook() {
 		return fmt.Errorf("self address not found for %s in addrBook so this client can send messages but does not propagate them", n.selfAddr)
 	}
 	return nil
 }
 
+// The addresses in `addrList` are sorted lexicographically, but starting at the address of this node and wrapping
+// around, so the address of this node is always the first in the list. This makes RainTree propagation easier to
+// compute and interpret.
+func (n *rainTreeNetwork) addrListLess(addr1, addr2 string) bool {
+	selfAddr := n.selfAddr.String()
+	if isBeforeSelf1, isBeforeSelf2 := addr1 < selfAddr, addr2 < selfAddr; isBeforeSelf1 != isBeforeSelf2 {
+		return isBeforeSelf2
+	}
+	return addr1 < addr2
+}
+
+// Returns the index `addr` has, or would have, in `addrList`.
+func (n *rainTreeNetwork) searchAddrList(addr string) int {
+	return sort.Search(len(n.addrList), func(i int) bool {
+		return !n.addrListLess(n.addrList[i], addr)
+	})
+}
+
+func (n *rainTreeNetwork) isSelfInAddrBook() bool {
+	return len(n.addrList) > 0 && n.addrList[0] == n.selfAddr.String()
+}
+
+// The peer must not be in the address book yet.
+func (n *rainTreeNetwork) addPeer(peer *typesPre2P.NetworkPeer) {
+	addr := peer.Address.String()
+	i := n.searchAddrList(addr)
+	n.addrList = append(n.addrList, "")
+	copy(n.addrList[i+1:], n.addrList[i:])
+	n.addrList[i] = addr
+
+	n.addrBookMap[addr] = peer
+	n.addrBook = append(n.addrBook, peer)
+	n.maxNumLevels = n.getMaxAddrBookLevels()
+}
+
+// The peer with `addr` must be in the address book.
+func (n *rainTreeNetwork) removePeer(addr string) {
+	i := n.searchAddrList(addr)
+	n.addrList = append(n.addrList[:i], n.addrList[i+1:]...)
+
+	delete(n.addrBookMap, addr)
+	j := n.getAddrBookIndex(addr)
+	n.addrBook = append(n.addrBook[:j], n.addrBook[j+1:]...)
+	n.maxNumLevels = n.getMaxAddrBookLevels()
+}
+
+// A peer with the same address as `peer` must be in the address book.
+func (n *rainTreeNetwork) updatePeer(peer *typesPre2P.NetworkPeer) {
+	addr := peer.Address.String()
+	n.addrBookMap[addr] = peer
+	n.addrBook[n.getAddrBookIndex(addr)] = peer
+}
+
+func (n *rainTreeNetwork) getAddrBookIndex(addr string) int {
+	for i, peer := range n.addrBook {
+		if peer.Address.String() == addr {
+			return i
+		}
+	}
+	return -1
+}
+
 func (n *rainTreeNetwork) getSelfIndexInAddrBook() (int, bool) {
 	addrString := n.selfAddr.String()
 	for i, addr := range n.addrList {
@@ -53,33 +113,94 @@ func (n *rainTreeNetwork) getSelfIndexInAddrBook() (int, bool) {
 	return -1, false
 }
 
+// A target of a message at some level. If it does not acknowledge the message, the message is sent to the peer that
+// follows it at the same level instead, up to `limit` so it never reaches into the part of the level covered by the
+// next target.
+type target struct {
+	index int // The index of the target in `addrList`
+	limit int
+	addr  cryptoPocket.Address
+}
+
 func (n *rainTreeNetwork) getFirstTargetAddr(level uint32) (cryptoPocket.Address, bool) {
-	return n.getTarget(level, firstMsgTargetPercentage)
+	return n.getTargetAddr(n.getFirstTarget(level))
 }
 
 func (n *rainTreeNetwork) getSecondTargetAddr(level uint32) (cryptoPocket.Address, bool) {
-	return n.getTarget(level, secondMsgTargetPercentage)
+	return n.getTargetAddr(n.getSecondTarget(level))
 }
 
-func (n *rainTreeNetwork) getTarget(level uint32, targetPercentage float64) (cryptoPocket.Address, bool) {
+func (n *rainTreeNetwork) getFirstTarget(level uint32) (target, bool) {
 	// OPTIMIZE(olshansky): We are computing this twice for each message, but it's not that expensive.
 	l := n.getAddrBookLengthAtHeight(level)
+	return n.getTarget(level, l, firstMsgTargetPercentage, int(secondMsgTargetPercentage*float64(l)))
+}
+
+func (n *rainTreeNetwork) getSecondTarget(level uint32) (target, bool) {
+	l := n.getAddrBookLengthAtHeight(level)
+	return n.getTarget(level, l, secondMsgTargetPercentage, l)
+}
 
+func (n *rainTreeNetwork) getTarget(level uint32, l int, targetPercentage float64, limit int) (target, bool) {
 	i := int(targetPercentage * float64(l))
 
 	// If the target is 0, it is a reference to self, which is a `Demote` in RainTree terms.
 	// This is handled separately.
 	if i == 0 {
-		return nil, false
+		return target{}, false
 	}
 
-	addrStr := n.addrList[i]
-	if addr, ok := n.addrBookMap[addrStr]; ok {
+	// Unreliable peers are skipped in favour of the next reliable peer at the same level, if there is one
+	t := target{index: i, limit: limit}
+	for candidate, ok := t, true; ok && candidate.index <= i+int(n.maxRetries); candidate, ok = n.getAlternateTarget(candidate) {
+		if n.isReliable(n.addrList[candidate.index]) {
+			t = candidate
+			break
+		}
+	}
+
+	if peer, ok := n.addrBookMap[n.addrList[t.index]]; ok {
 		// IMPROVE(olshansky): Consolidate so the debug print contains all (i.e. both) targets in one log line
-		log.Printf("[DEBUG] Target (%0.2f) at height (%d): %s", targetPercentage, level, n.debugMsgTargetString(l, i))
-		return addr.Address, true
+		log.Printf("[DEBUG] Target (%0.2f) at height (%d): %s", targetPercentage, level, n.debugMsgTargetString(l, t.index))
+		t.addr = peer.Address
+		return t, true
+	}
+	return target{}, false
+}
+
+// Returns the peer that follows `t` at the same level, if there is one.
+func (n *rainTreeNetwork) getAlternateTarget(t target) (target, bool) {
+	// The address book may have changed since `t` was selected, in which case the alternate is the peer at the index
+	// that follows it in the current address book
+	if t.index+1 >= t.limit || t.index+1 >= len(n.addrList) {
+		return target{}, false
+	}
+	return target{
+		index: t.index + 1,
+		limit: t.limit,
+		addr:  n.addrBookMap[n.addrList[t.index+1]].Address,
+	}, true
+}
+
+func (n *rainTreeNetwork) getTargetAddr(t target, ok bool) (cryptoPocket.Address, bool) {
+	if !ok {
+		return nil, false
+	}
+	return t.addr, true
+}
+
+// Returns the addresses of the nodes after and before this node in the address book, which wraps around. A node
+// that is not in its own address book has no neighbours.
+func (n *rainTreeNetwork) getNeighbourAddrs() []cryptoPocket.Address {
+	if len(n.addrList) < 2 || !n.isSelfInAddrBook() {
+		return nil
+	}
+
+	neighbours := []cryptoPocket.Address{n.addrBookMap[n.addrList[1]].Address}
+	if len(n.addrList) > 2 {
+		neighbours = append(neighbours, n.addrBookMap[n.addrList[len(n.addrList)-1]].Address)
 	}
-	return nil, false
+	return neighbours
 }
 
 // TODO(team): Need to integrate with persistence layer so we are storing this on a per height basis.
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pokt-network/pocket/p2p/pre2p/types"
+	"github.com/pokt-network/pocket/shared/config"
 	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 	"github.com/stretchr/testify/require"
 )
@@ -68,7 +70,7 @@ func TestRainTreeAddrBookUtilsHandleUpdate(t *testing.T) {
 		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
 			addrBook := getAddrBook(t, n-1)
 			addrBook = append(addrBook, &types.NetworkPeer{Address: addr})
-			network := NewRainTreeNetwork(addr, addrBook).(*rainTreeNetwork)
+			network := NewRainTreeNetwork(addr, addrBook, &config.Pre2PConfig{}).(*rainTreeNetwork)
 
 			err = network.processAddrBookUpdates()
 			require.NoError(t, err)
@@ -98,7 +100,7 @@ func BenchmarkAddrBookUpdates(b *testing.B) {
 		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
 			addrBook := getAddrBook(nil, n-1)
 			addrBook = append(addrBook, &types.NetworkPeer{Address: addr})
-			network := NewRainTreeNetwork(addr, addrBook).(*rainTreeNetwork)
+			network := NewRainTreeNetwork(addr, addrBook, &config.Pre2PConfig{}).(*rainTreeNetwork)
 
 			err = network.processAddrBookUpdates()
 			require.NoError(b, err)
@@ -165,9 +167,100 @@ func TestRainTreeAddrBookTargetsTwentySevenNodes(t *testing.T) {
 	testRainTreeMessageTargets(t, prop)
 }
 
+func TestRainTreeAddrBookNeighbours(t *testing.T) {
+	testCases := []struct {
+		orig       byte
+		numNodes   int
+		neighbours string
+	}{
+		{'A', 1, ""},
+		{'A', 2, "B"},
+		{'B', 2, "A"},
+		{'A', 3, "BC"},
+		{'O', 27, "PN"},
+		{'[', 27, "AZ"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%c/n=%d", testCase.orig, testCase.numNodes), func(t *testing.T) {
+			addrBook := getAlphabetAddrBook(testCase.numNodes)
+			network := NewRainTreeNetwork([]byte{testCase.orig}, addrBook, &config.Pre2PConfig{}).(*rainTreeNetwork)
+
+			neighbours := strings.Builder{}
+			for _, addr := range network.getNeighbourAddrs() {
+				neighbours.Write(addr)
+			}
+			require.Equal(t, testCase.neighbours, neighbours.String())
+		})
+	}
+}
+
+func TestRainTreeAddrBookIncrementalUpdates(t *testing.T) {
+	network := NewRainTreeNetwork([]byte{'E'}, getAlphabetAddrBook(9), &config.Pre2PConfig{}).(*rainTreeNetwork)
+
+	// Every change must result in the same helpers as computing them from scratch, including the changes to the size
+	// of the address book that change the number of levels
+	requireSameAsFromScratch := func() {
+		expected := NewRainTreeNetwork([]byte{'E'}, network.GetAddrBook(), &config.Pre2PConfig{}).(*rainTreeNetwork)
+		require.Equal(t, expected.addrList, network.addrList)
+		require.Equal(t, expected.addrBookMap, network.addrBookMap)
+		require.Equal(t, expected.maxNumLevels, network.maxNumLevels)
+		require.Len(t, network.addrBook, len(network.addrList))
+	}
+
+	for _, ch := range "[ZJYKXLWMVNUOTPSQR" {
+		require.NoError(t, network.AddPeerToAddrBook(&types.NetworkPeer{Address: []byte{byte(ch)}}))
+		requireSameAsFromScratch()
+	}
+	require.Equal(t, uint32(3), network.maxNumLevels)
+
+	for _, ch := range "ADFG[BCHIJKLMNZ" {
+		require.NoError(t, network.RemovePeerFromAddrBook(&types.NetworkPeer{Address: []byte{byte(ch)}}))
+		requireSameAsFromScratch()
+	}
+	require.Equal(t, strToAddrList("EOPQRSTUVWXY"), strings.Join(network.addrList, ""))
+	require.Equal(t, uint32(3), network.maxNumLevels)
+
+	require.NoError(t, network.UpdatePeerInAddrBook(&types.NetworkPeer{Address: []byte("O"), ServiceUrl: "updated"}))
+	requireSameAsFromScratch()
+	require.Equal(t, "updated", network.addrBookMap[testAddr('O')].ServiceUrl)
+
+	// Removing self keeps the remaining peers in order, but the node no longer propagates messages
+	require.NoError(t, network.RemovePeerFromAddrBook(&types.NetworkPeer{Address: []byte("E")}))
+	require.Equal(t, strToAddrList("OPQRSTUVWXY"), strings.Join(network.addrList, ""))
+	require.Empty(t, network.getNeighbourAddrs())
+	require.NoError(t, network.AddPeerToAddrBook(&types.NetworkPeer{Address: []byte("E")}))
+	requireSameAsFromScratch()
+	require.Equal(t, strToAddrList("EOPQRSTUVWXY"), strings.Join(network.addrList, ""))
+}
+
+func TestRainTreeAddrBookChanges(t *testing.T) {
+	network, transports := newTestRainTreeNetwork(t, 'A', 3, time.Hour)
+
+	require.Equal(t, types.ErrPeerAlreadyInAddrBook, network.AddPeerToAddrBook(&types.NetworkPeer{Address: []byte("B")}))
+	require.Equal(t, types.ErrPeerNotInAddrBook, network.RemovePeerFromAddrBook(&types.NetworkPeer{Address: []byte("D")}))
+	require.Equal(t, types.ErrPeerNotInAddrBook, network.UpdatePeerInAddrBook(&types.NetworkPeer{Address: []byte("D")}))
+	require.Len(t, network.GetAddrBook(), 3)
+
+	// The dialers of the peers that are replaced or removed are closed
+	updatedTransport := &testTransport{}
+	require.NoError(t, network.UpdatePeerInAddrBook(&types.NetworkPeer{Address: []byte("B"), Dialer: updatedTransport}))
+	require.True(t, transports['B'].isClosed())
+	require.NoError(t, network.RemovePeerFromAddrBook(&types.NetworkPeer{Address: []byte("C")}))
+	require.True(t, transports['C'].isClosed())
+	require.False(t, updatedTransport.isClosed())
+
+	// Messages are only sent to the peers in the address book
+	require.NoError(t, network.NetworkBroadcast([]byte("data")))
+	require.Len(t, updatedTransport.treeMessages(t), 1)
+	require.Empty(t, transports['B'].treeMessages(t))
+	require.Empty(t, transports['C'].treeMessages(t))
+	require.Error(t, network.NetworkSend([]byte("data"), []byte("C")))
+}
+
 func testRainTreeMessageTargets(t *testing.T, expectedMsgProp *ExpectedRainTreeMessageProp) {
 	addrBook := getAlphabetAddrBook(expectedMsgProp.numNodes)
-	network := NewRainTreeNetwork([]byte{expectedMsgProp.orig}, addrBook).(*rainTreeNetwork)
+	network := NewRainTreeNetwork([]byte{expectedMsgProp.orig}, addrBook, &config.Pre2PConfig{}).(*rainTreeNetwork)
 	network.processAddrBookUpdates()
 
 	require.Equal(t, strings.Join(network.addrList, ""), strToAddrList(expectedMsgProp.addrList))
@@ -1,14 +1,17 @@
 package raintree
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	typesPre2P "github.com/pokt-network/pocket/p2p/pre2p/types"
+	"github.com/pokt-network/pocket/shared/config"
 	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 	"github.com/pokt-network/pocket/shared/types"
 
@@ -19,7 +22,11 @@ var _ typesPre2P.Network = &rainTreeNetwork{}
 
 type rainTreeNetwork struct {
 	selfAddr cryptoPocket.Address
-	addrBook typesPre2P.AddrBook
+
+	// The address book can change while messages are propagated, so it is guarded by `addrBookMu`, alongside the
+	// helpers computed from it below. `addrBookMu` is never acquired while `m` is held.
+	addrBookMu sync.RWMutex
+	addrBook   typesPre2P.AddrBook
 
 	// TECHDEBT(olshansky): Consider optimizing these away if possible.
 	// Helpers / abstractions around `addrBook` for simpler implementation through additional
@@ -30,9 +37,34 @@ type rainTreeNetwork struct {
 
 	// TECHDEBT(drewsky): What should we use for de-duping messages within P2P?
 	mempool types.Mempool
+
+	cleanupDelay     time.Duration
+	cleanupMaxRounds uint32
+	ackTimeout       time.Duration
+	maxRetries       uint32
+
+	// Messages are handled concurrently, so deduping them and tracking their acknowledgments is synchronized
+	m           sync.Mutex
+	cleanups    map[uint64]*cleanupState // The broadcast messages the cleanup layer is tracking, keyed by nonce
+	pendingAcks map[ackKey]*time.Timer   // The messages sent through the tree that were not acknowledged yet
+	peerStats   map[string]*peerStats    // Keyed by the hex encoded address of the peer
+}
+
+// Identifies the acknowledgment of a message sent to a target through the tree.
+type ackKey struct {
+	nonce uint64
+	level uint32
+	addr  string // Hex encoded address of the target
+}
+
+// The acknowledgments the cleanup layer has received for a broadcast message.
+type cleanupState struct {
+	data   []byte
+	acked  map[string]struct{} // Hex encoded addresses of the neighbours that are known to have the message
+	rounds uint32              // The number of times the message was re-sent to the neighbours that did not acknowledge it
 }
 
-func NewRainTreeNetwork(addr cryptoPocket.Address, addrBook typesPre2P.AddrBook) typesPre2P.Network {
+func NewRainTreeNetwork(addr cryptoPocket.Address, addrBook typesPre2P.AddrBook, cfg *config.Pre2PConfig) typesPre2P.Network {
 	n := &rainTreeNetwork{
 		selfAddr: addr,
 		addrBook: addrBook,
@@ -42,6 +74,14 @@ func NewRainTreeNetwork(addr cryptoPocket.Address, addrBook typesPre2P.AddrBook)
 		maxNumLevels: 0,
 		// TODO(team): Mempool size should be configurable
 		mempool: types.NewMempool(1000000, 1000),
+
+		cleanupDelay:     time.Duration(cfg.RainTreeCleanupDelayMsec) * time.Millisecond,
+		cleanupMaxRounds: cfg.RainTreeCleanupMaxRounds,
+		cleanups:         make(map[uint64]*cleanupState),
+		ackTimeout:       time.Duration(cfg.RainTreeAckTimeoutMsec) * time.Millisecond,
+		maxRetries:       cfg.RainTreeMaxRetries,
+		pendingAcks:      make(map[ackKey]*time.Timer),
+		peerStats:        make(map[string]*peerStats),
 	}
 
 	if err := n.processAddrBookUpdates(); err != nil {
@@ -53,36 +93,68 @@ func NewRainTreeNetwork(addr cryptoPocket.Address, addrBook typesPre2P.AddrBook)
 	return typesPre2P.Network(n)
 }
 
+// A broadcast message is propagated by three layers:
+//  1. The tree layer: every node sends the message to its two targets at its level and demotes itself to the level
+//     below, until the bottom of the tree is reached. Targets acknowledge the message, and one that does not within
+//     `RainTreeAckTimeoutMsec` (or cannot be sent the message) is replaced by the next peer at the same level, up to
+//     `RainTreeMaxRetries` times. Targets that often fail to acknowledge messages are skipped in favour of the next
+//     peer at the same level in the first place.
+//  2. The redundancy layer: every node that receives the message, through any layer, sends it once to its left and
+//     right neighbours in the address book. This covers the nodes a dropped message at the bottom of the tree missed.
+//  3. The cleanup layer: a neighbour acknowledges a message by sending it back through the redundancy layer. Every
+//     `RainTreeCleanupDelayMsec`, the message is sent again to the neighbours that did not acknowledge it, up to
+//     `RainTreeCleanupMaxRounds` times. A neighbour that already has the message acknowledges it again.
+//
+// Since the nodes that receive a message from their neighbours run the redundancy layer themselves, a message that
+// was dropped higher up in the tree reaches the entire subtree it was meant for along the ring of neighbours.
 func (n *rainTreeNetwork) NetworkBroadcast(data []byte) error {
-	return n.networkBroadcastAtLevel(data, n.maxNumLevels, getNonce())
+	nonce := getNonce()
+	// The originator does not hand its own message back to the application once its neighbours send it back
+	if _, err := n.markSeen(nonce, data, true); err != nil {
+		return err
+	}
+
+	n.addrBookMu.RLock()
+	maxNumLevels := n.maxNumLevels
+	n.addrBookMu.RUnlock()
+
+	if err := n.networkBroadcastAtLevel(data, maxNumLevels, nonce); err != nil {
+		return err
+	}
+	n.redundancyLayer(data, nonce)
+
+	return nil
 }
 
 func (n *rainTreeNetwork) networkBroadcastAtLevel(data []byte, level uint32, nonce uint64) error {
-	// This is handled either by the cleanup layer or redundancy layer
+	// The bottom of the tree is covered by the redundancy layer, which every node runs once per message
 	if level == 0 {
 		return nil
 	}
 
 	msg := &typesPre2P.RainTreeMessage{
-		Level: level,
-		Data:  data,
-		Nonce: nonce,
+		Level:  level,
+		Data:   data,
+		Nonce:  nonce,
+		Layer:  typesPre2P.RainTreeLayer_RAINTREE_LAYER_TREE,
+		Sender: n.selfAddr,
 	}
 	bz, err := proto.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	if addr1, ok := n.getFirstTargetAddr(level); ok {
-		if err = n.networkSendInternal(bz, addr1); err != nil {
-			log.Println("Error sending to peer during broadcast: ", err)
-		}
+	n.addrBookMu.RLock()
+	t1, ok1 := n.getFirstTarget(level)
+	t2, ok2 := n.getSecondTarget(level)
+	n.addrBookMu.RUnlock()
+
+	if ok1 {
+		n.sendToTarget(bz, level, nonce, t1, 0)
 	}
 
-	if addr2, ok := n.getSecondTargetAddr(level); ok {
-		if err = n.networkSendInternal(bz, addr2); err != nil {
-			log.Println("Error sending to peer during broadcast: ", err)
-		}
+	if ok2 {
+		n.sendToTarget(bz, level, nonce, t2, 0)
 	}
 
 	if err = n.demote(msg); err != nil {
@@ -92,6 +164,70 @@ func (n *rainTreeNetwork) networkBroadcastAtLevel(data []byte, level uint32, non
 	return nil
 }
 
+// Sends the message to the target and waits for its acknowledgment. `numRetries` is the number of targets at the same
+// level that failed to acknowledge the message before this one.
+func (n *rainTreeNetwork) sendToTarget(bz []byte, level uint32, nonce uint64, t target, numRetries uint32) {
+	key := ackKey{nonce: nonce, level: level, addr: t.addr.String()}
+
+	n.m.Lock()
+	// The message is already on its way to the target at this level (e.g. this node was sent it twice)
+	if _, ok := n.pendingAcks[key]; ok {
+		n.m.Unlock()
+		return
+	}
+	n.pendingAcks[key] = time.AfterFunc(n.ackTimeout, func() {
+		n.targetFailed(bz, level, nonce, t, numRetries, key)
+	})
+	n.m.Unlock()
+
+	if err := n.networkSendInternal(bz, t.addr); err != nil {
+		log.Println("Error sending to peer during broadcast: ", err)
+		n.targetFailed(bz, level, nonce, t, numRetries, key)
+	}
+}
+
+// Sends the message to the next peer at the same level once the target did not acknowledge it in time, or could not
+// be sent it.
+func (n *rainTreeNetwork) targetFailed(bz []byte, level uint32, nonce uint64, t target, numRetries uint32, key ackKey) {
+	n.m.Lock()
+	timer, ok := n.pendingAcks[key]
+	if !ok {
+		n.m.Unlock()
+		return
+	}
+	timer.Stop()
+	delete(n.pendingAcks, key)
+	n.recordFailure(key.addr)
+	n.m.Unlock()
+
+	if numRetries >= n.maxRetries {
+		log.Printf("[WARN] RainTree message %d was not acknowledged at level %d after %d retries", nonce, level, numRetries)
+		return
+	}
+	n.addrBookMu.RLock()
+	alternate, ok := n.getAlternateTarget(t)
+	n.addrBookMu.RUnlock()
+	if !ok {
+		log.Printf("[WARN] RainTree message %d was not acknowledged at level %d and there is no alternate target", nonce, level)
+		return
+	}
+	n.sendToTarget(bz, level, nonce, alternate, numRetries+1)
+}
+
+func (n *rainTreeNetwork) handleAck(nonce uint64, level uint32, sender cryptoPocket.Address) {
+	key := ackKey{nonce: nonce, level: level, addr: sender.String()}
+
+	n.m.Lock()
+	defer n.m.Unlock()
+	timer, ok := n.pendingAcks[key]
+	if !ok {
+		return
+	}
+	timer.Stop()
+	delete(n.pendingAcks, key)
+	n.recordAck(key.addr)
+}
+
 func (n *rainTreeNetwork) demote(rainTreeMsg *typesPre2P.RainTreeMessage) error {
 	if rainTreeMsg.Level > 0 {
 		if err := n.networkBroadcastAtLevel(rainTreeMsg.Data, rainTreeMsg.Level-1, rainTreeMsg.Nonce); err != nil {
@@ -103,9 +239,11 @@ func (n *rainTreeNetwork) demote(rainTreeMsg *typesPre2P.RainTreeMessage) error
 
 func (n *rainTreeNetwork) NetworkSend(data []byte, address cryptoPocket.Address) error {
 	msg := &typesPre2P.RainTreeMessage{
-		Level: 0, // Direct send that does not need to be propagated
-		Data:  data,
-		Nonce: getNonce(),
+		Level:  0, // Direct send that does not need to be propagated
+		Data:   data,
+		Nonce:  getNonce(),
+		Layer:  typesPre2P.RainTreeLayer_RAINTREE_LAYER_TREE,
+		Sender: n.selfAddr,
 	}
 
 	bz, err := proto.Marshal(msg)
@@ -122,7 +260,9 @@ func (n *rainTreeNetwork) networkSendInternal(data []byte, address cryptoPocket.
 		return nil
 	}
 
+	n.addrBookMu.RLock()
 	peer, ok := n.addrBookMap[address.String()]
+	n.addrBookMu.RUnlock()
 	if !ok {
 		return fmt.Errorf("address %s not found in addrBookMap", address.String())
 	}
@@ -135,60 +275,244 @@ func (n *rainTreeNetwork) networkSendInternal(data []byte, address cryptoPocket.
 	return nil
 }
 
-func (n *rainTreeNetwork) HandleNetworkData(data []byte) ([]byte, error) {
+func (n *rainTreeNetwork) HandleNetworkData(data []byte, sender cryptoPocket.Address) ([]byte, error) {
 	var rainTreeMsg typesPre2P.RainTreeMessage
 	if err := proto.Unmarshal(data, &rainTreeMsg); err != nil {
 		return nil, err
 	}
 
+	// The sender a message declares is what ACKs and the reliability of peers are accounted to, so a peer cannot
+	// declare another one
+	if !bytes.Equal(rainTreeMsg.Sender, sender) {
+		return nil, fmt.Errorf("message received from %s declares %s as its sender", sender, cryptoPocket.Address(rainTreeMsg.Sender))
+	}
+
+	if rainTreeMsg.Layer == typesPre2P.RainTreeLayer_RAINTREE_LAYER_ACK {
+		n.handleAck(rainTreeMsg.Nonce, rainTreeMsg.Level, sender)
+		return nil, nil
+	}
+	n.recordSeen(sender.String())
+
 	networkMessage := types.PocketEvent{}
 	if err := proto.Unmarshal(rainTreeMsg.Data, &networkMessage); err != nil {
 		log.Println("Error decoding network message: ", err)
 		return nil, err
 	}
 
-	// Continue RainTree propagation
-	if rainTreeMsg.Level > 0 {
-		if err := n.networkBroadcastAtLevel(rainTreeMsg.Data, rainTreeMsg.Level-1, rainTreeMsg.Nonce); err != nil {
-			return nil, err
+	isBroadcast := rainTreeMsg.Level > 0 || rainTreeMsg.Layer != typesPre2P.RainTreeLayer_RAINTREE_LAYER_TREE
+	isNew, err := n.markSeen(rainTreeMsg.Nonce, rainTreeMsg.Data, isBroadcast)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rainTreeMsg.Layer {
+	case typesPre2P.RainTreeLayer_RAINTREE_LAYER_TREE:
+		// Continue RainTree propagation
+		if rainTreeMsg.Level > 0 {
+			n.sendAck(rainTreeMsg.Nonce, rainTreeMsg.Level, sender)
+			if err := n.networkBroadcastAtLevel(rainTreeMsg.Data, rainTreeMsg.Level-1, rainTreeMsg.Nonce); err != nil {
+				return nil, err
+			}
+		}
+	case typesPre2P.RainTreeLayer_RAINTREE_LAYER_REDUNDANCY, typesPre2P.RainTreeLayer_RAINTREE_LAYER_CLEANUP:
+		n.acknowledge(rainTreeMsg.Nonce, sender)
+
+		// The neighbour did not receive the acknowledgment of this node, which is sent again
+		if rainTreeMsg.Layer == typesPre2P.RainTreeLayer_RAINTREE_LAYER_CLEANUP && !isNew {
+			n.sendToPeers(rainTreeMsg.Data, rainTreeMsg.Nonce, typesPre2P.RainTreeLayer_RAINTREE_LAYER_REDUNDANCY, []cryptoPocket.Address{sender})
 		}
 	}
 
+	// Avoids this node from processing a messages / transactions is has already processed at the
+	// application layer. The logic above makes sure it is only propagated and returns.
+	if !isNew {
+		return nil, nil
+	}
+
+	if isBroadcast {
+		n.redundancyLayer(rainTreeMsg.Data, rainTreeMsg.Nonce)
+	}
+
+	// Return the data back to the caller so it can be handeled by the app specific bus
+	return rainTreeMsg.Data, nil
+}
+
+// Returns true the first time a message with `nonce` is seen, in which case the cleanup layer starts tracking the
+// acknowledgments of the message if it is a broadcast.
+func (n *rainTreeNetwork) markSeen(nonce uint64, data []byte, isBroadcast bool) (bool, error) {
 	b := make([]byte, 8)
-	binary.LittleEndian.PutUint64(b, uint64(rainTreeMsg.Nonce))
+	binary.LittleEndian.PutUint64(b, nonce)
 	hash := cryptoPocket.SHA3Hash(b)
 	hashString := hex.EncodeToString(hash)
-	// Avoids this node from processing a messages / transactions is has already processed at the
-	// application layer. The logic above makes sure it is only propagated and returns.
+
+	n.m.Lock()
+	defer n.m.Unlock()
+
 	// TODO(team): Add more tests to verify this is sufficient for deduping purposes.
 	if n.mempool.Contains(hashString) {
-		return nil, nil
+		return false, nil
 	}
 
 	// Error handling the addition transaction to the local mempool
 	if err := n.mempool.AddTransaction(b); err != nil {
-		return nil, fmt.Errorf("error adding transaction to RainTree mempool: %s", err.Error())
+		return false, fmt.Errorf("error adding transaction to RainTree mempool: %s", err.Error())
 	}
 
-	// Return the data back to the caller so it can be handeled by the app specific bus
-	return rainTreeMsg.Data, nil
+	if isBroadcast {
+		n.cleanups[nonce] = &cleanupState{
+			data:  data,
+			acked: make(map[string]struct{}),
+		}
+	}
+	return true, nil
 }
 
-func (n *rainTreeNetwork) GetAddrBook() typesPre2P.AddrBook {
-	return n.addrBook
+// Sends the message to both neighbours of this node, and re-sends it through the cleanup layer to those that do not
+// acknowledge it.
+func (n *rainTreeNetwork) redundancyLayer(data []byte, nonce uint64) {
+	n.addrBookMu.RLock()
+	neighbours := n.getNeighbourAddrs()
+	n.addrBookMu.RUnlock()
+
+	n.sendToPeers(data, nonce, typesPre2P.RainTreeLayer_RAINTREE_LAYER_REDUNDANCY, neighbours)
+	time.AfterFunc(n.cleanupDelay, func() {
+		n.cleanupLayer(nonce)
+	})
+}
+
+func (n *rainTreeNetwork) cleanupLayer(nonce uint64) {
+	n.addrBookMu.RLock()
+	neighbours := n.getNeighbourAddrs()
+	n.addrBookMu.RUnlock()
+
+	n.m.Lock()
+	state, ok := n.cleanups[nonce]
+	if !ok {
+		n.m.Unlock()
+		return
+	}
+
+	unacked := make([]cryptoPocket.Address, 0)
+	for _, addr := range neighbours {
+		if _, ok := state.acked[addr.String()]; !ok {
+			unacked = append(unacked, addr)
+		}
+	}
+
+	if len(unacked) == 0 || state.rounds >= n.cleanupMaxRounds {
+		delete(n.cleanups, nonce)
+		n.m.Unlock()
+		if len(unacked) > 0 {
+			log.Printf("[WARN] %d neighbour(s) did not acknowledge RainTree message %d after %d cleanup rounds", len(unacked), nonce, state.rounds)
+		}
+		return
+	}
+	state.rounds++
+	n.m.Unlock()
+
+	n.sendToPeers(state.data, nonce, typesPre2P.RainTreeLayer_RAINTREE_LAYER_CLEANUP, unacked)
+	time.AfterFunc(n.cleanupDelay, func() {
+		n.cleanupLayer(nonce)
+	})
+}
+
+func (n *rainTreeNetwork) acknowledge(nonce uint64, addr cryptoPocket.Address) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	if state, ok := n.cleanups[nonce]; ok {
+		state.acked[addr.String()] = struct{}{}
+	}
+}
+
+func (n *rainTreeNetwork) sendAck(nonce uint64, level uint32, addr cryptoPocket.Address) {
+	msg := &typesPre2P.RainTreeMessage{
+		Level:  level,
+		Nonce:  nonce,
+		Layer:  typesPre2P.RainTreeLayer_RAINTREE_LAYER_ACK,
+		Sender: n.selfAddr,
+	}
+	bz, err := proto.Marshal(msg)
+	if err != nil {
+		log.Println("Error encoding RainTree ACK: ", err)
+		return
+	}
+
+	if err := n.networkSendInternal(bz, addr); err != nil {
+		log.Println("Error sending RainTree ACK: ", err)
+	}
+}
+
+func (n *rainTreeNetwork) sendToPeers(data []byte, nonce uint64, layer typesPre2P.RainTreeLayer, addrs []cryptoPocket.Address) {
+	msg := &typesPre2P.RainTreeMessage{
+		Level:  0, // Neither layer is propagated through the tree
+		Data:   data,
+		Nonce:  nonce,
+		Layer:  layer,
+		Sender: n.selfAddr,
+	}
+	bz, err := proto.Marshal(msg)
+	if err != nil {
+		log.Println("Error encoding RainTree message: ", err)
+		return
+	}
+
+	for _, addr := range addrs {
+		if err := n.networkSendInternal(bz, addr); err != nil {
+			log.Printf("Error sending to peer during RainTree %s layer: %v", layer, err)
+		}
+	}
+}
 
+func (n *rainTreeNetwork) GetAddrBook() typesPre2P.AddrBook {
+	n.addrBookMu.RLock()
+	defer n.addrBookMu.RUnlock()
+	return append(typesPre2P.AddrBook(nil), n.addrBook...)
 }
 
+// TODO(team): Messages that are still propagated when the address book changes may be sent to different targets than
+// the other nodes expect, which is covered by the redundancy and cleanup layers.
 func (n *rainTreeNetwork) AddPeerToAddrBook(peer *typesPre2P.NetworkPeer) error {
-	n.addrBook = append(n.addrBook, peer)
-	if err := n.processAddrBookUpdates(); err != nil {
-		return nil
+	n.addrBookMu.Lock()
+	defer n.addrBookMu.Unlock()
+	if _, ok := n.addrBookMap[peer.Address.String()]; ok {
+		return typesPre2P.ErrPeerAlreadyInAddrBook
+	}
+	n.addPeer(peer)
+	return nil
+}
+
+func (n *rainTreeNetwork) RemovePeerFromAddrBook(peer *typesPre2P.NetworkPeer) error {
+	n.addrBookMu.Lock()
+	defer n.addrBookMu.Unlock()
+	addr := peer.Address.String()
+	existingPeer, ok := n.addrBookMap[addr]
+	if !ok {
+		return typesPre2P.ErrPeerNotInAddrBook
 	}
+	closeDialer(existingPeer)
+	n.removePeer(addr)
 	return nil
 }
 
-func (n *rainTreeNetwork) RemovePeerToAddrBook(peer *typesPre2P.NetworkPeer) error {
-	panic("Not implemented")
+func (n *rainTreeNetwork) UpdatePeerInAddrBook(peer *typesPre2P.NetworkPeer) error {
+	n.addrBookMu.Lock()
+	defer n.addrBookMu.Unlock()
+	existingPeer, ok := n.addrBookMap[peer.Address.String()]
+	if !ok {
+		return typesPre2P.ErrPeerNotInAddrBook
+	}
+	closeDialer(existingPeer)
+	n.updatePeer(peer)
+	return nil
+}
+
+func closeDialer(peer *typesPre2P.NetworkPeer) {
+	if peer.Dialer == nil {
+		return
+	}
+	if err := peer.Dialer.Close(); err != nil {
+		log.Println("[WARN] Error closing connection to peer: ", err)
+	}
 }
 
 func getNonce() uint64 {
@@ -0,0 +1,190 @@
+package raintree
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/p2p/pre2p/types"
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRainTreeTargetsAcknowledgeMessages(t *testing.T) {
+	// See `TestRainTreeAddrBookTargetsNineNodes`
+	network, transports := newTestRainTreeNetwork(t, 'A', 9, time.Hour)
+	require.NoError(t, network.NetworkBroadcast([]byte("data")))
+	for _, ch := range "DGCE" {
+		require.Len(t, transports[ch].treeMessages(t), 1)
+	}
+	require.Len(t, network.pendingAcks, 4)
+
+	// Only the ACK of the target at the level it was sent the message at is accepted
+	nonce := transports['D'].treeMessages(t)[0].Nonce
+	require.NoError(t, handleTestAck(t, network, nonce, 1, 'D', 'D'))
+	require.Len(t, network.pendingAcks, 4)
+
+	// A peer cannot acknowledge on behalf of the target
+	require.Error(t, handleTestAck(t, network, nonce, 2, 'D', 'C'))
+	require.Len(t, network.pendingAcks, 4)
+
+	require.NoError(t, handleTestAck(t, network, nonce, 2, 'D', 'D'))
+	require.Len(t, network.pendingAcks, 3)
+	require.Equal(t, uint64(1), network.peerStats[testAddr('D')].numAcks)
+}
+
+func TestRainTreeRetriesUnreachableTargets(t *testing.T) {
+	network, transports := newTestRainTreeNetwork(t, 'A', 9, time.Hour)
+	transports['D'].err = errors.New("unreachable")
+	transports['E'].err = errors.New("unreachable")
+
+	// The message is sent to the next peers at the same level, without crossing into the part of the level of `G`.
+	// `E` is also a target at level 1, where `F` is its alternate.
+	require.NoError(t, network.NetworkBroadcast([]byte("data")))
+	msgs := transports['F'].treeMessages(t)
+	require.Len(t, msgs, 2)
+	require.Equal(t, uint32(2), msgs[0].Level)
+	require.Equal(t, uint32(1), msgs[1].Level)
+	require.Len(t, transports['G'].treeMessages(t), 1)
+	require.Equal(t, uint64(1), network.peerStats[testAddr('D')].numFailures)
+	require.Equal(t, uint64(2), network.peerStats[testAddr('E')].numFailures)
+}
+
+func TestRainTreeRetriesUnacknowledgedTargets(t *testing.T) {
+	network, transports := newTestRainTreeNetwork(t, 'A', 9, 10*time.Millisecond)
+	require.NoError(t, network.NetworkBroadcast([]byte("data")))
+
+	// None of the targets acknowledge the message, which is sent to the next peer at the same level `maxRetries` times
+	// unless it would cross into the part of the level of the next target: at level 2 the targets are `D` and `G`, and
+	// at level 1 `C` (up to `D`) and `E` (up to `F`)
+	require.Eventually(t, func() bool {
+		network.m.Lock()
+		defer network.m.Unlock()
+		return len(network.pendingAcks) == 0
+	}, time.Second, 10*time.Millisecond)
+	for ch, numMessages := range map[rune]int{'D': 2, 'E': 2, 'F': 2, 'G': 1, 'H': 1, 'I': 1, 'C': 1, 'B': 0} {
+		require.Len(t, transports[ch].treeMessages(t), numMessages, "peer %c", ch)
+	}
+}
+
+func TestRainTreeSkipsUnreliableTargets(t *testing.T) {
+	network, _ := newTestRainTreeNetwork(t, 'A', 9, time.Hour)
+
+	network.m.Lock()
+	for i := 0; i < 3; i++ {
+		network.recordFailure(testAddr('D'))
+		network.recordFailure(testAddr('E'))
+	}
+	network.m.Unlock()
+	addr, ok := network.getFirstTargetAddr(2)
+	require.True(t, ok)
+	require.Equal(t, cryptoPocket.Address("F"), addr)
+
+	// Without a reliable peer within `maxRetries` of the target, the target is kept
+	network.m.Lock()
+	for i := 0; i < 3; i++ {
+		network.recordFailure(testAddr('F'))
+	}
+	network.m.Unlock()
+	addr, ok = network.getFirstTargetAddr(2)
+	require.True(t, ok)
+	require.Equal(t, cryptoPocket.Address("D"), addr)
+
+	// Peers recover once they are heard from
+	network.recordSeen(testAddr('D'))
+	addr, ok = network.getFirstTargetAddr(2)
+	require.True(t, ok)
+	require.Equal(t, cryptoPocket.Address("D"), addr)
+	require.True(t, network.isReliable(testAddr('D')))
+}
+
+// Records the messages written to a peer, or fails to write them if `err` is set.
+type testTransport struct {
+	m      sync.Mutex
+	writes [][]byte
+	err    error
+	closed bool
+}
+
+func (c *testTransport) IsListener() bool {
+	return false
+}
+
+func (c *testTransport) Read() ([]byte, cryptoPocket.Address, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (c *testTransport) Write(data []byte) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.writes = append(c.writes, data)
+	return c.err
+}
+
+func (c *testTransport) Close() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *testTransport) isClosed() bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.closed
+}
+
+// Returns the messages written to the peer through the tree layer.
+func (c *testTransport) treeMessages(t *testing.T) []*types.RainTreeMessage {
+	c.m.Lock()
+	defer c.m.Unlock()
+	msgs := make([]*types.RainTreeMessage, 0)
+	for _, data := range c.writes {
+		msg := &types.RainTreeMessage{}
+		require.NoError(t, proto.Unmarshal(data, msg))
+		if msg.Layer == types.RainTreeLayer_RAINTREE_LAYER_TREE {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// Returns the network of `orig` in an address book of `numNodes` (see `getAlphabetAddrBook`) with the transports of
+// all the peers, keyed by their address.
+func newTestRainTreeNetwork(t *testing.T, orig byte, numNodes int, ackTimeout time.Duration) (*rainTreeNetwork, map[rune]*testTransport) {
+	transports := make(map[rune]*testTransport)
+	addrBook := getAlphabetAddrBook(numNodes)
+	for _, peer := range addrBook {
+		transport := &testTransport{}
+		transports[rune(peer.Address[0])] = transport
+		peer.Dialer = transport
+	}
+
+	cfg := &config.Pre2PConfig{
+		RainTreeCleanupDelayMsec: uint64(time.Hour.Milliseconds()),
+		RainTreeAckTimeoutMsec:   uint64(ackTimeout.Milliseconds()),
+		RainTreeMaxRetries:       2,
+	}
+	require.NoError(t, cfg.ValidateAndHydrate())
+	return NewRainTreeNetwork([]byte{orig}, addrBook, cfg).(*rainTreeNetwork), transports
+}
+
+// `from` is the authenticated address of the peer the ACK is received from, which must match its declared `sender`.
+func handleTestAck(t *testing.T, network *rainTreeNetwork, nonce uint64, level uint32, sender, from byte) error {
+	bz, err := proto.Marshal(&types.RainTreeMessage{
+		Level:  level,
+		Nonce:  nonce,
+		Layer:  types.RainTreeLayer_RAINTREE_LAYER_ACK,
+		Sender: []byte{sender},
+	})
+	require.NoError(t, err)
+	_, err = network.HandleNetworkData(bz, []byte{from})
+	return err
+}
+
+func testAddr(ch byte) string {
+	return cryptoPocket.Address([]byte{ch}).String()
+}
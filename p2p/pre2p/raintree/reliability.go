@@ -0,0 +1,57 @@
+package raintree
+
+const (
+	// Every acknowledged or failed delivery moves the reliability of a peer this far towards 1 or 0 respectively
+	reliabilitySmoothingFactor = float64(0.25)
+	// Targets that are less reliable than this are skipped in favour of the next reliable peer at the same level
+	minTargetReliability = float64(0.5)
+)
+
+// How reliably a peer acknowledges the messages sent to it through the tree. Peers that were never sent a message
+// are fully reliable.
+type peerStats struct {
+	numAcks     uint64
+	numFailures uint64 // Messages the peer did not acknowledge in time or that could not be sent to it
+	reliability float64
+}
+
+// Must be called with `n.m` held.
+func (n *rainTreeNetwork) getPeerStats(addr string) *peerStats {
+	stats, ok := n.peerStats[addr]
+	if !ok {
+		stats = &peerStats{reliability: 1}
+		n.peerStats[addr] = stats
+	}
+	return stats
+}
+
+// Must be called with `n.m` held.
+func (n *rainTreeNetwork) recordAck(addr string) {
+	stats := n.getPeerStats(addr)
+	stats.numAcks++
+	stats.reliability += reliabilitySmoothingFactor * (1 - stats.reliability)
+}
+
+// Must be called with `n.m` held.
+func (n *rainTreeNetwork) recordFailure(addr string) {
+	stats := n.getPeerStats(addr)
+	stats.numFailures++
+	stats.reliability -= reliabilitySmoothingFactor * stats.reliability
+}
+
+// Any message received from a peer shows it is online, so a peer that is skipped as a target recovers once it is
+// heard from again.
+func (n *rainTreeNetwork) recordSeen(addr string) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	if stats, ok := n.peerStats[addr]; ok {
+		stats.reliability += reliabilitySmoothingFactor * (1 - stats.reliability)
+	}
+}
+
+func (n *rainTreeNetwork) isReliable(addr string) bool {
+	n.m.Lock()
+	defer n.m.Unlock()
+	stats, ok := n.peerStats[addr]
+	return !ok || stats.reliability >= minTargetReliability
+}
@@ -4,6 +4,7 @@ package stdnetwork
 
 import (
 	"log"
+	"sync"
 
 	typesPre2P "github.com/pokt-network/pocket/p2p/pre2p/types"
 	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
@@ -12,6 +13,7 @@ import (
 var _ typesPre2P.Network = &network{}
 
 type network struct {
+	m        sync.RWMutex
 	addrBook typesPre2P.AddrBook
 }
 
@@ -50,19 +52,65 @@ func (n *network) NetworkSend(data []byte, address cryptoPocket.Address) error {
 	return nil
 }
 
-func (n *network) HandleNetworkData(data []byte) ([]byte, error) {
+func (n *network) HandleNetworkData(data []byte, _ cryptoPocket.Address) ([]byte, error) {
 	return data, nil // intentional passthrough
 }
 
 func (n *network) GetAddrBook() typesPre2P.AddrBook {
-	return n.addrBook
+	n.m.RLock()
+	defer n.m.RUnlock()
+	return append(typesPre2P.AddrBook(nil), n.addrBook...)
 }
 
 func (n *network) AddPeerToAddrBook(peer *typesPre2P.NetworkPeer) error {
+	n.m.Lock()
+	defer n.m.Unlock()
+	if n.getPeerIndex(peer.Address) >= 0 {
+		return typesPre2P.ErrPeerAlreadyInAddrBook
+	}
 	n.addrBook = append(n.addrBook, peer)
 	return nil
 }
 
-func (n *network) RemovePeerToAddrBook(peer *typesPre2P.NetworkPeer) error {
-	panic("RemovePeerToAddrBook not implemented")
+func (n *network) RemovePeerFromAddrBook(peer *typesPre2P.NetworkPeer) error {
+	n.m.Lock()
+	defer n.m.Unlock()
+	i := n.getPeerIndex(peer.Address)
+	if i < 0 {
+		return typesPre2P.ErrPeerNotInAddrBook
+	}
+	closeDialer(n.addrBook[i])
+	n.addrBook = append(n.addrBook[:i], n.addrBook[i+1:]...)
+	return nil
+}
+
+func (n *network) UpdatePeerInAddrBook(peer *typesPre2P.NetworkPeer) error {
+	n.m.Lock()
+	defer n.m.Unlock()
+	i := n.getPeerIndex(peer.Address)
+	if i < 0 {
+		return typesPre2P.ErrPeerNotInAddrBook
+	}
+	closeDialer(n.addrBook[i])
+	n.addrBook[i] = peer
+	return nil
+}
+
+// Returns -1 if the address is not in the address book. Must be called with `n.m` held.
+func (n *network) getPeerIndex(address cryptoPocket.Address) int {
+	for i, peer := range n.addrBook {
+		if peer.Address.Equals(address) {
+			return i
+		}
+	}
+	return -1
+}
+
+func closeDialer(peer *typesPre2P.NetworkPeer) {
+	if peer.Dialer == nil {
+		return
+	}
+	if err := peer.Dialer.Close(); err != nil {
+		log.Println("[WARN] Error closing connection to peer: ", err)
+	}
 }
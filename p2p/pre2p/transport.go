@@ -1,22 +1,40 @@
 package pre2p
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"log"
 	"net"
+	"sync"
+	"time"
 
 	typesPre2P "github.com/pokt-network/pocket/p2p/pre2p/types"
 	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 )
 
 const (
 	TCPNetworkLayerProtocol = "tcp4"
+
+	frameLenBytes                = 4 // Every message is prefixed by its length as a big endian uint32
+	maxWriteAttempts             = 3 // A message is dropped after this many failed attempts to connect to the peer or send it
+	inboundIdleTimeoutMultiplier = 2
+)
+
+var (
+	ErrTransportClosed = errors.New("transport is closed")
+	ErrWriteQueueFull  = errors.New("too many messages are waiting to be sent to the peer")
+	ErrPeerUnreachable = errors.New("the peer could not be reached")
+	errMessageExpired  = errors.New("the message expired before it could be sent")
 )
 
-func CreateListener(cfg *config.Pre2PConfig) (typesPre2P.Transport, error) {
+// `isKnownPeer` is only used to reject connections from unknown peers if `RequireKnownPeers` is set in the config.
+func CreateListener(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, isKnownPeer func(cryptoPocket.PublicKey) bool) (typesPre2P.Transport, error) {
 	switch cfg.ConnectionType {
 	case config.TCPConnection:
-		return createTCPListener(cfg)
+		return createTCPListener(cfg, privateKey, isKnownPeer)
 	case config.EmptyConnection:
 		return createEmptyListener(cfg)
 	default:
@@ -24,10 +42,11 @@ func CreateListener(cfg *config.Pre2PConfig) (typesPre2P.Transport, error) {
 	}
 }
 
-func CreateDialer(cfg *config.Pre2PConfig, url string) (typesPre2P.Transport, error) {
+// `peerPublicKey` is the key the peer listening on `url` must authenticate with.
+func CreateDialer(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, url string, peerPublicKey cryptoPocket.PublicKey) (typesPre2P.Transport, error) {
 	switch cfg.ConnectionType {
 	case config.TCPConnection:
-		return createTCPDialer(cfg, url)
+		return createTCPDialer(cfg, privateKey, url, peerPublicKey)
 	case config.EmptyConnection:
 		return createEmptyDialer(cfg, url)
 	default:
@@ -35,14 +54,27 @@ func CreateDialer(cfg *config.Pre2PConfig, url string) (typesPre2P.Transport, er
 	}
 }
 
-var _ typesPre2P.Transport = &tcpConn{}
+var _ typesPre2P.Transport = &tcpListener{}
 
-type tcpConn struct {
-	address  *net.TCPAddr
-	listener *net.TCPListener
+// Accepts long-lived connections from peers and multiplexes the messages received on all of them into `Read`.
+// Every connection is authenticated and encrypted (see `handshake.go`). Inbound connections are closed once they have been idle for longer than the dialing side keeps them open, so it
+// is the dialing side that closes idle connections and knows not to reuse them.
+type tcpListener struct {
+	cfg         *config.Pre2PConfig
+	listener    *net.TCPListener
+	privateKey  cryptoPocket.PrivateKey
+	isKnownPeer func(cryptoPocket.PublicKey) bool // nil if connections from any peer are accepted
+
+	inbound chan inboundMessage
+
+	m     sync.Mutex
+	conns map[net.Conn]struct{}
+
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
-func createTCPListener(cfg *config.Pre2PConfig) (*tcpConn, error) {
+func createTCPListener(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, isKnownPeer func(cryptoPocket.PublicKey) bool) (*tcpListener, error) {
 	addr, err := net.ResolveTCPAddr(TCPNetworkLayerProtocol, fmt.Sprintf(":%d", cfg.ConsensusPort))
 	if err != nil {
 		return nil, err
@@ -51,67 +83,432 @@ func createTCPListener(cfg *config.Pre2PConfig) (*tcpConn, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &tcpConn{
-		address:  addr,
-		listener: l,
-	}, nil
+
+	listener := &tcpListener{
+		cfg:         cfg,
+		listener:    l,
+		privateKey:  privateKey,
+		isKnownPeer: nil,
+		inbound:     make(chan inboundMessage),
+		conns:       make(map[net.Conn]struct{}),
+		closed:      make(chan struct{}),
+	}
+	if cfg.RequireKnownPeers {
+		listener.isKnownPeer = isKnownPeer
+	}
+	go listener.acceptConnections()
+
+	return listener, nil
+}
+
+func (l *tcpListener) IsListener() bool {
+	return true
 }
 
-func createTCPDialer(cfg *config.Pre2PConfig, url string) (*tcpConn, error) {
+// A message received from the peer with the address verified during the handshake of its connection.
+type inboundMessage struct {
+	data   []byte
+	sender cryptoPocket.Address
+}
+
+// Blocks until a message is received from any peer.
+func (l *tcpListener) Read() ([]byte, cryptoPocket.Address, error) {
+	select {
+	case msg := <-l.inbound:
+		return msg.data, msg.sender, nil
+	case <-l.closed:
+		return nil, nil, ErrTransportClosed
+	}
+}
+
+func (l *tcpListener) Write(_ []byte) error {
+	return fmt.Errorf("connection is a listener")
+}
+
+func (l *tcpListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		err = l.listener.Close()
+
+		l.m.Lock()
+		defer l.m.Unlock()
+		for conn := range l.conns {
+			conn.Close()
+		}
+	})
+	return err
+}
+
+func (l *tcpListener) acceptConnections() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			select {
+			case <-l.closed:
+				return
+			default:
+			}
+			log.Println("[WARN] Error accepting connection: ", err)
+			continue
+		}
+
+		l.m.Lock()
+		l.conns[conn] = struct{}{}
+		l.m.Unlock()
+
+		go l.readMessages(conn)
+	}
+}
+
+func (l *tcpListener) readMessages(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		l.m.Lock()
+		delete(l.conns, conn)
+		l.m.Unlock()
+	}()
+
+	secure, err := acceptHandshake(conn, l.privateKey, l.isKnownPeer, time.Duration(l.cfg.ConnectionTimeoutMsec)*time.Millisecond)
+	if err != nil {
+		log.Printf("[WARN] Rejecting connection from %s: %v\n", conn.RemoteAddr(), err)
+		return
+	}
+
+	idleTimeout := time.Duration(l.cfg.IdleTimeoutMsec*inboundIdleTimeoutMultiplier) * time.Millisecond
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+			return
+		}
+		data, err := secure.readMessage(l.cfg.MaxMessageBytes)
+		if err != nil {
+			var netErr net.Error
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+				log.Printf("[WARN] Error reading from connection with %s (%s): %v\n", secure.peerAddress(), conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		select {
+		case l.inbound <- inboundMessage{data: data, sender: secure.peerAddress()}:
+		case <-l.closed:
+			return
+		}
+	}
+}
+
+var _ typesPre2P.Transport = &tcpConn{}
+
+// A long-lived connection to a peer that is only established once a message is sent to it. Messages are queued
+// and sent in order by a single goroutine, which reconnects with an exponential backoff if the connection breaks
+// and closes it once it has been idle for `IdleTimeoutMsec`. Messages are dropped once they could not be sent
+// after `maxWriteAttempts` attempts or within `MessageTTLMsec`. If the peer could not be reached, writes fail
+// with `ErrPeerUnreachable` until the next attempt to connect is due, so the callers can fall back to other peers.
+type tcpConn struct {
+	cfg           *config.Pre2PConfig
+	address       *net.TCPAddr
+	privateKey    cryptoPocket.PrivateKey
+	peerPublicKey cryptoPocket.PublicKey
+
+	queue     chan queuedMessage
+	startOnce sync.Once
+
+	m                sync.Mutex
+	unreachableUntil time.Time // Zero while the peer is reachable
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func createTCPDialer(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, url string, peerPublicKey cryptoPocket.PublicKey) (*tcpConn, error) {
 	addr, err := net.ResolveTCPAddr(TCPNetworkLayerProtocol, url)
 	if err != nil {
 		return nil, err
 	}
 	return &tcpConn{
-		address: addr,
+		cfg:           cfg,
+		address:       addr,
+		privateKey:    privateKey,
+		peerPublicKey: peerPublicKey,
+		queue:         make(chan queuedMessage, cfg.WriteQueueSize),
+		closed:        make(chan struct{}),
 	}, nil
 }
 
 func (c *tcpConn) IsListener() bool {
-	return c.listener != nil
+	return false
+}
+
+func (c *tcpConn) Read() ([]byte, cryptoPocket.Address, error) {
+	return nil, nil, fmt.Errorf("connection is not a listener")
+}
+
+type queuedMessage struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// Queues `data` to be sent to the peer without waiting for it to be sent.
+func (c *tcpConn) Write(data []byte) error {
+	if len(data) > int(c.cfg.MaxMessageBytes) {
+		return fmt.Errorf("message of %d bytes exceeds the maximum of %d bytes", len(data), c.cfg.MaxMessageBytes)
+	}
+
+	select {
+	case <-c.closed:
+		return ErrTransportClosed
+	default:
+	}
+
+	if c.isUnreachable() {
+		return ErrPeerUnreachable
+	}
+
+	c.startOnce.Do(func() {
+		go c.writeMessages()
+	})
+
+	msg := queuedMessage{
+		data:      data,
+		expiresAt: time.Now().Add(time.Duration(c.cfg.MessageTTLMsec) * time.Millisecond),
+	}
+	select {
+	case c.queue <- msg:
+		return nil
+	default:
+		return ErrWriteQueueFull
+	}
+}
+
+func (c *tcpConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *tcpConn) writeMessages() {
+	var conn *peerConn
+	defer func() {
+		if conn != nil {
+			conn.close()
+		}
+	}()
+
+	idleTimeout := time.Duration(c.cfg.IdleTimeoutMsec) * time.Millisecond
+	idleTimer := time.NewTimer(idleTimeout)
+	backoff := newReconnectBackoff(c.cfg)
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-idleTimer.C:
+			if conn != nil {
+				conn.close()
+				conn = nil
+			}
+		case msg := <-c.queue:
+			var err error
+			if conn, err = c.writeMessage(conn, msg, backoff); err != nil {
+				if errors.Is(err, ErrTransportClosed) {
+					return
+				}
+				log.Printf("[WARN] Dropping message to %s: %v\n", c.address, err)
+				if conn == nil {
+					c.setUnreachableUntil(backoff.nextAttempt())
+				}
+			}
+
+			if !idleTimer.Stop() {
+				select {
+				case <-idleTimer.C:
+				default:
+				}
+			}
+			idleTimer.Reset(idleTimeout)
+		}
+	}
+}
+
+// Sends `msg` on `conn`, reconnecting if needed, and returns the connection to use for the next message, which
+// is nil if the peer could not be reached.
+func (c *tcpConn) writeMessage(conn *peerConn, msg queuedMessage, backoff *reconnectBackoff) (*peerConn, error) {
+	if c.isUnreachable() {
+		return conn, ErrPeerUnreachable
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxWriteAttempts; attempt++ {
+		if conn == nil || conn.isBroken() {
+			if conn != nil {
+				conn.close()
+			}
+			if conn, err = c.connect(backoff); err != nil {
+				if errors.Is(err, ErrTransportClosed) {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if time.Now().After(msg.expiresAt) {
+			return conn, errMessageExpired
+		}
+
+		if err = conn.write(msg.data, time.Duration(c.cfg.ConnectionTimeoutMsec)*time.Millisecond); err == nil {
+			return conn, nil
+		}
+		conn.close()
+		conn = nil
+	}
+	return conn, fmt.Errorf("failed after %d attempts: %w", maxWriteAttempts, err)
 }
 
-func (c *tcpConn) Read() ([]byte, error) {
-	if !c.IsListener() {
-		return nil, fmt.Errorf("connection is not a listener")
+// Makes a single attempt to connect to the peer once the backoff delay has passed. Returns `ErrTransportClosed`
+// if the transport is closed in the meantime.
+func (c *tcpConn) connect(backoff *reconnectBackoff) (*peerConn, error) {
+	if !backoff.wait(c.closed) {
+		return nil, ErrTransportClosed
 	}
-	conn, err := c.listener.Accept()
+
+	timeout := time.Duration(c.cfg.ConnectionTimeoutMsec) * time.Millisecond
+	conn, err := net.DialTimeout(TCPNetworkLayerProtocol, c.address.String(), timeout)
 	if err != nil {
-		return nil, fmt.Errorf("error accepting connection: %v", err)
+		backoff.failed()
+		log.Printf("[WARN] Error connecting to %s; next attempt in %s: %v\n", c.address, backoff.delay, err)
+		return nil, err
 	}
-	defer conn.Close()
 
-	data, err := ioutil.ReadAll(conn)
+	secure, err := dialHandshake(conn, c.privateKey, c.peerPublicKey, timeout)
 	if err != nil {
-		return nil, fmt.Errorf("error reading from conn: %v", err)
+		conn.Close()
+		backoff.failed()
+		log.Printf("[WARN] Error authenticating %s; next attempt in %s: %v\n", c.address, backoff.delay, err)
+		return nil, err
 	}
 
-	return data, nil
+	backoff.succeeded()
+	c.setUnreachableUntil(time.Time{})
+	return newPeerConn(secure), nil
 }
 
-func (c *tcpConn) Write(data []byte) error {
-	if c.IsListener() {
-		return fmt.Errorf("connection is a listener")
+func (c *tcpConn) isUnreachable() bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return time.Now().Before(c.unreachableUntil)
+}
+
+func (c *tcpConn) setUnreachableUntil(until time.Time) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.unreachableUntil = until
+}
+
+// An established outbound connection. Peers never write to outbound connections, so reading from it only returns
+// once the peer closed it, which marks the connection as broken before a message is lost by writing to it.
+type peerConn struct {
+	conn   *secureConn
+	broken chan struct{}
+}
+
+func newPeerConn(conn *secureConn) *peerConn {
+	c := &peerConn{
+		conn:   conn,
+		broken: make(chan struct{}),
 	}
+	go func() {
+		defer close(c.broken)
+		io.Copy(io.Discard, conn.Conn)
+	}()
+	return c
+}
 
-	client, err := net.DialTCP(TCPNetworkLayerProtocol, nil, c.address)
-	if err != nil {
-		return err
+func (c *peerConn) isBroken() bool {
+	select {
+	case <-c.broken:
+		return true
+	default:
+		return false
 	}
-	defer client.Close()
+}
 
-	if _, err = client.Write(data); err != nil {
+func (c *peerConn) write(data []byte, timeout time.Duration) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
 		return err
 	}
+	return c.conn.writeMessage(data)
+}
 
-	return nil
+func (c *peerConn) close() {
+	c.conn.Close()
 }
 
-func (c *tcpConn) Close() error {
-	if c.IsListener() {
-		return c.listener.Close()
+// Exponential backoff between attempts to connect to a peer. The first attempt after a successful connection is
+// not delayed.
+type reconnectBackoff struct {
+	min, max    time.Duration
+	delay       time.Duration // Zero until an attempt fails
+	lastAttempt time.Time
+}
+
+func newReconnectBackoff(cfg *config.Pre2PConfig) *reconnectBackoff {
+	return &reconnectBackoff{
+		min: time.Duration(cfg.ReconnectBackoffMsec) * time.Millisecond,
+		max: time.Duration(cfg.ReconnectBackoffMaxMsec) * time.Millisecond,
 	}
-	return nil
+}
+
+// Returns false if `closed` is closed before the next attempt is due.
+func (b *reconnectBackoff) wait(closed <-chan struct{}) bool {
+	if remaining := time.Until(b.nextAttempt()); remaining > 0 {
+		timer := time.NewTimer(remaining)
+		defer timer.Stop()
+		select {
+		case <-closed:
+			return false
+		case <-timer.C:
+		}
+	}
+	b.lastAttempt = time.Now()
+	return true
+}
+
+func (b *reconnectBackoff) nextAttempt() time.Time {
+	return b.lastAttempt.Add(b.delay)
+}
+
+func (b *reconnectBackoff) failed() {
+	switch {
+	case b.delay == 0:
+		b.delay = b.min
+	case b.delay*2 > b.max:
+		b.delay = b.max
+	default:
+		b.delay *= 2
+	}
+}
+
+func (b *reconnectBackoff) succeeded() {
+	b.delay = 0
+}
+
+func readFrame(r io.Reader, maxMessageBytes uint32) ([]byte, error) {
+	frameLen := make([]byte, frameLenBytes)
+	if _, err := io.ReadFull(r, frameLen); err != nil {
+		return nil, err
+	}
+
+	dataLen := binary.BigEndian.Uint32(frameLen)
+	if dataLen > maxMessageBytes {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum of %d bytes", dataLen, maxMessageBytes)
+	}
+
+	data := make([]byte, dataLen)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
 var _ typesPre2P.Transport = &emptyConn{}
@@ -131,8 +528,8 @@ func (c *emptyConn) IsListener() bool {
 	return false
 }
 
-func (c *emptyConn) Read() ([]byte, error) {
-	return nil, nil
+func (c *emptyConn) Read() ([]byte, cryptoPocket.Address, error) {
+	return nil, nil, nil
 }
 
 func (c *emptyConn) Write(data []byte) error {
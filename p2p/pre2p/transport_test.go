@@ -0,0 +1,315 @@
+package pre2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pokt-network/pocket/shared/config"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPTransportReusesConnection(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	listener, dialer := createTCPTransports(t, cfg)
+
+	// Messages of any size are delivered in order over a single connection
+	messages := make([][]byte, 100)
+	for i := range messages {
+		messages[i] = bytes.Repeat([]byte{byte(i)}, i*100)
+		require.NoError(t, dialer.Write(messages[i]))
+	}
+	for i := range messages {
+		require.Equal(t, messages[i], readWithTimeout(t, listener))
+	}
+	require.Equal(t, 1, numInboundConns(listener))
+
+	// Messages larger than the maximum are rejected
+	require.Error(t, dialer.Write(make([]byte, cfg.MaxMessageBytes+1)))
+}
+
+func TestTCPTransportReconnects(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	listener, dialer := createTCPTransports(t, cfg)
+
+	require.NoError(t, dialer.Write([]byte("before")))
+	require.Equal(t, []byte("before"), readWithTimeout(t, listener))
+
+	// The peer drops the connection
+	listener.m.Lock()
+	for conn := range listener.conns {
+		conn.Close()
+	}
+	listener.m.Unlock()
+	require.Eventually(t, func() bool { return numInboundConns(listener) == 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // Lets the dialer notice the connection was closed
+
+	require.NoError(t, dialer.Write([]byte("after")))
+	require.Equal(t, []byte("after"), readWithTimeout(t, listener))
+	require.Equal(t, 1, numInboundConns(listener))
+}
+
+func TestTCPTransportClosesIdleConnection(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	cfg.IdleTimeoutMsec = 50
+	listener, dialer := createTCPTransports(t, cfg)
+
+	require.NoError(t, dialer.Write([]byte("first")))
+	require.Equal(t, []byte("first"), readWithTimeout(t, listener))
+	require.Equal(t, 1, numInboundConns(listener))
+
+	// The dialer closes the idle connection and opens a new one for the next message
+	require.Eventually(t, func() bool { return numInboundConns(listener) == 0 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, dialer.Write([]byte("second")))
+	require.Equal(t, []byte("second"), readWithTimeout(t, listener))
+}
+
+func TestTCPTransportWriteQueue(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	cfg.WriteQueueSize = 2
+
+	// Nothing listens on the address of the peer
+	dialer, err := createTCPDialer(cfg, generateTestKey(t), unusedTCPAddress(t), generateTestKey(t).PublicKey())
+	require.NoError(t, err)
+
+	// Writes do not block while the dialer backs off, but fail once the queue is full
+	require.Eventually(t, func() bool {
+		return dialer.Write([]byte("message")) == ErrWriteQueueFull
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, dialer.Close())
+	require.Equal(t, ErrTransportClosed, dialer.Write([]byte("message")))
+}
+
+func TestTCPTransportUnreachablePeer(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	listenerKey := generateTestKey(t)
+	dialer, err := createTCPDialer(cfg, generateTestKey(t), unusedTCPAddress(t), listenerKey.PublicKey())
+	require.NoError(t, err)
+	t.Cleanup(func() { dialer.Close() })
+
+	// The message is dropped after a bounded number of attempts to connect, and writes fail from then on
+	require.NoError(t, dialer.Write([]byte("dropped")))
+	require.Eventually(t, func() bool {
+		return dialer.Write([]byte("unreachable")) == ErrPeerUnreachable
+	}, time.Second, time.Millisecond)
+
+	// Writes succeed again once the peer is back and the next attempt to connect is due
+	listenerCfg := *cfg
+	listenerCfg.ConsensusPort = uint32(dialer.address.Port)
+	listener := createTestTCPListener(t, &listenerCfg, listenerKey, nil)
+	require.Eventually(t, func() bool {
+		return dialer.Write([]byte("reachable")) == nil
+	}, 2*time.Second, time.Millisecond)
+	require.Equal(t, []byte("reachable"), readWithTimeout(t, listener))
+}
+
+func TestTCPTransportDropsExpiredMessages(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	listener, dialer := createTCPTransports(t, cfg)
+
+	conn, err := dialer.writeMessage(nil, queuedMessage{data: []byte("expired"), expiresAt: time.Now()}, newReconnectBackoff(cfg))
+	require.ErrorIs(t, err, errMessageExpired)
+	require.NotNil(t, conn)
+	defer conn.close()
+
+	select {
+	case msg := <-listener.inbound:
+		t.Fatalf("Unexpected message received: %s", msg.data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestTCPTransportAuthenticatesPeers(t *testing.T) {
+	cfg := createTCPTransportConfig(t)
+	cfg.RequireKnownPeers = true
+	listenerKey, knownKey, unknownKey := generateTestKey(t), generateTestKey(t), generateTestKey(t)
+	listener := createTestTCPListener(t, cfg, listenerKey, func(publicKey cryptoPocket.PublicKey) bool {
+		return publicKey.Equals(knownKey.PublicKey())
+	})
+
+	// The listener does not authenticate with the key the dialer expects
+	impersonatingDialer := createTestTCPDialer(t, cfg, knownKey, listener, unknownKey.PublicKey())
+	require.NoError(t, impersonatingDialer.Write([]byte("impersonated")))
+
+	// The dialer is not in the address book of the listener
+	unknownDialer := createTestTCPDialer(t, cfg, unknownKey, listener, listenerKey.PublicKey())
+	require.NoError(t, unknownDialer.Write([]byte("unknown")))
+
+	knownDialer := createTestTCPDialer(t, cfg, knownKey, listener, listenerKey.PublicKey())
+	require.NoError(t, knownDialer.Write([]byte("known")))
+
+	// Only the message from the known dialer is received, along with its verified address
+	data, sender := readMessageWithTimeout(t, listener)
+	require.Equal(t, []byte("known"), data)
+	require.Equal(t, knownKey.Address(), sender)
+	select {
+	case msg := <-listener.inbound:
+		t.Fatalf("Unexpected message received: %s", msg.data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Unknown peers are accepted unless configured otherwise
+	openCfg := createTCPTransportConfig(t)
+	openListener := createTestTCPListener(t, openCfg, listenerKey, func(cryptoPocket.PublicKey) bool { return false })
+	unknownDialer = createTestTCPDialer(t, openCfg, unknownKey, openListener, listenerKey.PublicKey())
+	require.NoError(t, unknownDialer.Write([]byte("unknown")))
+	data, sender = readMessageWithTimeout(t, openListener)
+	require.Equal(t, []byte("unknown"), data)
+	require.Equal(t, unknownKey.Address(), sender)
+}
+
+func TestSecureConnEncryptsMessages(t *testing.T) {
+	dialerKey, listenerKey := generateTestKey(t), generateTestKey(t)
+	dialerConn, listenerConn := net.Pipe()
+	recordingConn := &recordingConn{Conn: dialerConn}
+
+	type handshakeResult struct {
+		conn *secureConn
+		err  error
+	}
+	accepted := make(chan handshakeResult, 1)
+	go func() {
+		conn, err := acceptHandshake(listenerConn, listenerKey, nil, time.Second)
+		accepted <- handshakeResult{conn, err}
+	}()
+	dialed, err := dialHandshake(recordingConn, dialerKey, listenerKey.PublicKey(), time.Second)
+	require.NoError(t, err)
+	result := <-accepted
+	require.NoError(t, result.err)
+
+	// Both sides are bound to the verified address of their peer
+	require.Equal(t, listenerKey.Address(), dialed.peerAddress())
+	require.Equal(t, dialerKey.Address(), result.conn.peerAddress())
+
+	// Messages are only sent encrypted, in both directions
+	message := []byte("a consensus message that must not be sent in plaintext")
+	go func() { dialed.writeMessage(message) }()
+	received, err := result.conn.readMessage(1024)
+	require.NoError(t, err)
+	require.Equal(t, message, received)
+	require.NotContains(t, string(recordingConn.written.Bytes()), string(message))
+
+	go func() { result.conn.writeMessage([]byte("reply")) }()
+	received, err = dialed.readMessage(1024)
+	require.NoError(t, err)
+	require.Equal(t, []byte("reply"), received)
+
+	// A tampered message is rejected
+	go func() {
+		frame := make([]byte, frameLenBytes+len(message)+dialed.sendCipher.Overhead())
+		binary.BigEndian.PutUint32(frame, uint32(len(frame)-frameLenBytes))
+		dialed.Conn.Write(frame)
+	}()
+	_, err = result.conn.readMessage(1024)
+	require.Error(t, err)
+}
+
+// Records the bytes written to the connection.
+type recordingConn struct {
+	net.Conn
+	written bytes.Buffer
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	c.written.Write(b)
+	return c.Conn.Write(b)
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	backoff := newReconnectBackoff(&config.Pre2PConfig{
+		ReconnectBackoffMsec:    100,
+		ReconnectBackoffMaxMsec: 300,
+	})
+
+	delays := make([]time.Duration, 0)
+	for i := 0; i < 4; i++ {
+		backoff.failed()
+		delays = append(delays, backoff.delay)
+	}
+	require.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}, delays)
+
+	backoff.succeeded()
+	require.Zero(t, backoff.delay)
+}
+
+func createTCPTransportConfig(t *testing.T) *config.Pre2PConfig {
+	cfg := &config.Pre2PConfig{
+		ConsensusPort:  0, // Any available port
+		ConnectionType: config.TCPConnection,
+	}
+	require.NoError(t, cfg.ValidateAndHydrate())
+	cfg.MaxMessageBytes = 1024 * 1024
+	cfg.ReconnectBackoffMsec = 10
+	return cfg
+}
+
+func createTCPTransports(t *testing.T, cfg *config.Pre2PConfig) (*tcpListener, *tcpConn) {
+	listenerKey, dialerKey := generateTestKey(t), generateTestKey(t)
+	listener := createTestTCPListener(t, cfg, listenerKey, func(publicKey cryptoPocket.PublicKey) bool {
+		return publicKey.Equals(dialerKey.PublicKey())
+	})
+	dialer := createTestTCPDialer(t, cfg, dialerKey, listener, listenerKey.PublicKey())
+	return listener, dialer
+}
+
+func createTestTCPListener(t *testing.T, cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, isKnownPeer func(cryptoPocket.PublicKey) bool) *tcpListener {
+	listener, err := createTCPListener(cfg, privateKey, isKnownPeer)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+func createTestTCPDialer(t *testing.T, cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, listener *tcpListener, peerPublicKey cryptoPocket.PublicKey) *tcpConn {
+	url := fmt.Sprintf("127.0.0.1:%d", listener.listener.Addr().(*net.TCPAddr).Port)
+	dialer, err := createTCPDialer(cfg, privateKey, url, peerPublicKey)
+	require.NoError(t, err)
+	t.Cleanup(func() { dialer.Close() })
+	return dialer
+}
+
+// Returns an address nothing listens on.
+func unusedTCPAddress(t *testing.T) string {
+	listener, err := net.Listen(TCPNetworkLayerProtocol, "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+	return listener.Addr().String()
+}
+
+func generateTestKey(t *testing.T) cryptoPocket.PrivateKey {
+	privateKey, err := cryptoPocket.GeneratePrivateKey()
+	require.NoError(t, err)
+	return privateKey
+}
+
+func readWithTimeout(t *testing.T, listener *tcpListener) []byte {
+	data, _ := readMessageWithTimeout(t, listener)
+	return data
+}
+
+func readMessageWithTimeout(t *testing.T, listener *tcpListener) ([]byte, cryptoPocket.Address) {
+	msgCh := make(chan inboundMessage, 1)
+	go func() {
+		data, sender, err := listener.Read()
+		require.NoError(t, err)
+		msgCh <- inboundMessage{data: data, sender: sender}
+	}()
+
+	select {
+	case msg := <-msgCh:
+		return msg.data, msg.sender
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for message")
+		return nil, nil
+	}
+}
+
+func numInboundConns(listener *tcpListener) int {
+	listener.m.Lock()
+	defer listener.m.Unlock()
+	return len(listener.conns)
+}
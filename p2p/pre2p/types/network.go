@@ -1,9 +1,16 @@
 package types
 
 import (
+	"errors"
+
 	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
 )
 
+var (
+	ErrPeerAlreadyInAddrBook = errors.New("peer is already in the address book")
+	ErrPeerNotInAddrBook     = errors.New("peer is not in the address book")
+)
+
 // CLEANUP(olshansky): See if we can deprecate one of these structures.
 type AddrBook []*NetworkPeer
 type AddrBookMap map[string]*NetworkPeer
@@ -14,15 +21,18 @@ type Network interface {
 	NetworkBroadcast(data []byte) error
 	NetworkSend(data []byte, address cryptoPocket.Address) error
 
-	// Address book helpers
+	// Address book helpers. Peers are identified by their address, and the dialers of the peers that are removed or
+	// replaced are closed.
 	GetAddrBook() AddrBook
-	AddPeerToAddrBook(peer *NetworkPeer) error    // TODO(team): Not used yet
-	RemovePeerToAddrBook(peer *NetworkPeer) error // TODO(team): Not used yet
+	AddPeerToAddrBook(peer *NetworkPeer) error      // Fails with `ErrPeerAlreadyInAddrBook` if the address is already in the address book
+	RemovePeerFromAddrBook(peer *NetworkPeer) error // Fails with `ErrPeerNotInAddrBook` if the address is not in the address book
+	UpdatePeerInAddrBook(peer *NetworkPeer) error   // Fails with `ErrPeerNotInAddrBook` if the address is not in the address book
 
 	// This function was added to specifically support the RainTree implementation.
 	// Handles the raw data received from the network and returns the data to be processed
-	// by the application layer.
-	HandleNetworkData(data []byte) ([]byte, error)
+	// by the application layer. `sender` is the address of the peer the data was received from, as
+	// authenticated by the transport.
+	HandleNetworkData(data []byte, sender cryptoPocket.Address) ([]byte, error)
 }
 
 type NetworkPeer struct {
@@ -34,7 +44,7 @@ type NetworkPeer struct {
 
 type Transport interface {
 	IsListener() bool
-	Read() ([]byte, error)
+	Read() ([]byte, cryptoPocket.Address, error) // Also returns the authenticated address of the peer that sent the data
 	Write([]byte) error
 	Close() error
 }
@@ -12,10 +12,10 @@ import (
 
 // CLEANUP(drewsky): These functions will turn into more of a "ActorToAddrBook" when we have a closer
 // integration with utility.
-func ValidatorMapToAddrBook(cfg *config.Pre2PConfig, validators map[string]*typesGenesis.Validator) (typesPre2P.AddrBook, error) {
+func ValidatorMapToAddrBook(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, validators map[string]*typesGenesis.Validator) (typesPre2P.AddrBook, error) {
 	book := make(typesPre2P.AddrBook, 0)
 	for _, v := range validators {
-		networkPeer, err := ValidatorToNetworkPeer(cfg, v)
+		networkPeer, err := ValidatorToNetworkPeer(cfg, privateKey, v)
 		if err != nil {
 			log.Println("[WARN] Error connecting to validator: ", err)
 			continue
@@ -27,23 +27,35 @@ func ValidatorMapToAddrBook(cfg *config.Pre2PConfig, validators map[string]*type
 
 // CLEANUP(drewsky): These functions will turn into more of a "ActorToAddrBook" when we have a closer
 // integration with utility.
-func ValidatorToNetworkPeer(cfg *config.Pre2PConfig, v *typesGenesis.Validator) (*typesPre2P.NetworkPeer, error) {
-	conn, err := CreateDialer(cfg, v.ServiceUrl)
+func ValidatorToNetworkPeer(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, v *typesGenesis.Validator) (*typesPre2P.NetworkPeer, error) {
+	return createNetworkPeer(cfg, privateKey, v.PublicKey, v.ServiceUrl)
+}
+
+func createNetworkPeer(cfg *config.Pre2PConfig, privateKey cryptoPocket.PrivateKey, publicKey []byte, serviceUrl string) (*typesPre2P.NetworkPeer, error) {
+	pubKey, err := cryptoPocket.NewPublicKeyFromBytes(publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("error resolving addr: %v", err)
+		return nil, err
 	}
 
-	pubKey, err := cryptoPocket.NewPublicKeyFromBytes(v.PublicKey)
+	conn, err := CreateDialer(cfg, privateKey, serviceUrl, pubKey)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error resolving addr: %v", err)
 	}
 
 	peer := &typesPre2P.NetworkPeer{
 		Dialer:     conn,
 		PublicKey:  pubKey,
 		Address:    pubKey.Address(),
-		ServiceUrl: v.ServiceUrl,
+		ServiceUrl: serviceUrl,
 	}
 
 	return peer, nil
 }
+
+func closeDialers(addrBook typesPre2P.AddrBook) {
+	for _, peer := range addrBook {
+		if err := peer.Dialer.Close(); err != nil {
+			log.Println("[WARN] Error closing connection to peer: ", err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package simnet
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	typesPre2P "github.com/pokt-network/pocket/p2p/pre2p/types"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var _ modules.P2PModule = &p2pModule{}
+
+type p2pModule struct {
+	bus     modules.Bus
+	network *Network
+
+	address  string   // Hex encoded
+	addrBook []string // Sorted hex encoded addresses of the validators, so broadcasts are sent in a deterministic order
+}
+
+// Creates the P2P module of a node with the specified address, which sends and receives messages through `network`.
+func (n *Network) CreateP2PModule(address cryptoPocket.Address) modules.P2PModule {
+	m := &p2pModule{
+		bus:     nil,
+		network: n,
+
+		address:  address.String(),
+		addrBook: make([]string, 0),
+	}
+	n.register(m)
+	return m
+}
+
+func (m *p2pModule) SetBus(bus modules.Bus) {
+	m.bus = bus
+}
+
+func (m *p2pModule) GetBus() modules.Bus {
+	if m.bus == nil {
+		log.Fatalf("PocketBus is not initialized")
+	}
+	return m.bus
+}
+
+func (m *p2pModule) Start() error {
+	return m.UpdateAddrBook(m.GetBus().GetConsensusModule().ValidatorMap())
+}
+
+func (m *p2pModule) Stop() error {
+	return nil
+}
+
+// Like the real network, a broadcast is also delivered to the sender.
+func (m *p2pModule) Broadcast(msg *anypb.Any, topic types.PocketTopic) error {
+	m.network.broadcast(m.address, &types.PocketEvent{Topic: topic, Data: msg}, m.addrBook)
+	return nil
+}
+
+func (m *p2pModule) Send(addr cryptoPocket.Address, msg *anypb.Any, topic types.PocketTopic) error {
+	return m.network.sendTo(m.address, addr, &types.PocketEvent{Topic: topic, Data: msg})
+}
+
+func (m *p2pModule) UpdateAddrBook(validators modules.ValidatorMap) error {
+	addrBook := make([]string, 0, len(validators))
+	for address := range validators {
+		addrBook = append(addrBook, address)
+	}
+	sort.Strings(addrBook)
+	m.addrBook = addrBook
+	return nil
+}
+
+// Applies the changes like the `pre2p` module, failing on the same changes. Only the addresses of the peers are kept,
+// so an update only checks the peer is in the address book.
+func (m *p2pModule) HandleAddrBookEvent(event *types.AddrBookEvent) error {
+	for _, change := range event.Changes {
+		address, err := getAddrBookChangeAddress(change)
+		if err != nil {
+			return err
+		}
+		i := sort.SearchStrings(m.addrBook, address)
+		isInAddrBook := i < len(m.addrBook) && m.addrBook[i] == address
+		switch change.Type {
+		case types.AddrBookChangeType_ADDRBOOK_CHANGE_ADD:
+			if isInAddrBook {
+				return typesPre2P.ErrPeerAlreadyInAddrBook
+			}
+			m.addrBook = append(m.addrBook[:i], append([]string{address}, m.addrBook[i:]...)...)
+		case types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE, types.AddrBookChangeType_ADDRBOOK_CHANGE_UPDATE:
+			if !isInAddrBook {
+				return typesPre2P.ErrPeerNotInAddrBook
+			}
+			if change.Type == types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE {
+				m.addrBook = append(m.addrBook[:i], m.addrBook[i+1:]...)
+			}
+		default:
+			return fmt.Errorf("unknown address book change type: %s", change.Type)
+		}
+	}
+	return nil
+}
+
+// Only the address of the peers that are removed is set; the address of the others is the one of their public key.
+func getAddrBookChangeAddress(change *types.AddrBookChange) (string, error) {
+	if change.Type == types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE {
+		return cryptoPocket.Address(change.Address).String(), nil
+	}
+	publicKey, err := cryptoPocket.NewPublicKeyFromBytes(change.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return publicKey.Address().String(), nil
+}
+
+// Events are handled synchronously by the modules of the recipient rather than going through its bus, so every
+// message sent while handling an event is sequenced deterministically by the network.
+func (m *p2pModule) handleEvent(event *types.PocketEvent) {
+	var err error
+	switch event.Topic {
+	case types.PocketTopic_CONSENSUS_MESSAGE_TOPIC:
+		err = m.GetBus().GetConsensusModule().HandleMessage(event.Data)
+	case types.PocketTopic_DEBUG_TOPIC:
+		var debugMessage types.DebugMessage
+		if err = anypb.UnmarshalTo(event.Data, &debugMessage, proto.UnmarshalOptions{}); err == nil {
+			err = m.GetBus().GetConsensusModule().HandleDebugMessage(&debugMessage)
+		}
+	default:
+		log.Printf("[WARN] Unsupported PocketEvent topic: %s \n", event.Topic)
+	}
+
+	if err != nil {
+		log.Println("Error handling simulated network event: ", err)
+	}
+}
@@ -0,0 +1,299 @@
+// Package simnet is a deterministic, in-memory network that routes messages between the P2P modules of in-process
+// nodes. Time only moves forward when the test advances the virtual clock of the network, and every random decision
+// (i.e. latency, drops and reordering) is drawn from a seeded source, so the same seed always replays the same run.
+package simnet
+
+import (
+	"container/heap"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type Config struct {
+	Seed int64
+
+	// The latency of every message is drawn uniformly from [MinLatency, MaxLatency]
+	MinLatency time.Duration
+	MaxLatency time.Duration
+
+	DropRate    float64 // Probability that a message is lost
+	ReorderRate float64 // Probability that a message is held back for an additional MaxLatency so later messages overtake it
+}
+
+type Stats struct {
+	Sent        int // Messages sent by the nodes, including those that were dropped
+	Delivered   int // Messages and scheduled events handled by the nodes
+	Dropped     int // Messages lost according to `DropRate`
+	Partitioned int // Messages discarded because the sender and the recipient were partitioned on delivery
+}
+
+type Network struct {
+	m sync.Mutex
+
+	cfg Config
+	rng *rand.Rand
+
+	// Virtual Clock
+	now   time.Duration // Time elapsed since the network was created
+	seq   uint64        // Breaks ties between messages delivered at the same time in the order they were sent
+	queue eventQueue
+
+	nodes      map[string]*p2pModule // Keyed by hex encoded address
+	partitions map[string]int        // The partition of every node, keyed by hex encoded address; 0 unless partitioned
+
+	stats Stats
+}
+
+type event struct {
+	at   time.Duration
+	seq  uint64
+	from string // Empty for events scheduled by the test, which are never dropped nor partitioned
+	to   string
+	data *types.PocketEvent
+}
+
+func NewNetwork(cfg Config) (*Network, error) {
+	if cfg.MinLatency < 0 || cfg.MaxLatency < cfg.MinLatency {
+		return nil, fmt.Errorf("invalid latency range: [%s, %s]", cfg.MinLatency, cfg.MaxLatency)
+	}
+
+	if cfg.DropRate < 0 || cfg.DropRate > 1 {
+		return nil, fmt.Errorf("DropRate must be between 0 and 1: %f", cfg.DropRate)
+	}
+
+	if cfg.ReorderRate < 0 || cfg.ReorderRate > 1 {
+		return nil, fmt.Errorf("ReorderRate must be between 0 and 1: %f", cfg.ReorderRate)
+	}
+
+	return &Network{
+		cfg: cfg,
+		rng: rand.New(rand.NewSource(cfg.Seed)),
+
+		now:   0,
+		seq:   0,
+		queue: make(eventQueue, 0),
+
+		nodes:      make(map[string]*p2pModule),
+		partitions: make(map[string]int),
+
+		stats: Stats{},
+	}, nil
+}
+
+// Returns the time elapsed on the virtual clock since the network was created.
+func (n *Network) Now() time.Duration {
+	n.m.Lock()
+	defer n.m.Unlock()
+	return n.now
+}
+
+func (n *Network) Stats() Stats {
+	n.m.Lock()
+	defer n.m.Unlock()
+	return n.stats
+}
+
+// Returns the number of messages and scheduled events that were not delivered yet.
+func (n *Network) InFlight() int {
+	n.m.Lock()
+	defer n.m.Unlock()
+	return n.queue.Len()
+}
+
+// Splits the network so that nodes only receive messages from nodes in the same group. Nodes that are not part
+// of any group form a group of their own. Messages already in flight are discarded if they cross a partition
+// by the time they are delivered.
+func (n *Network) Partition(groups ...[]cryptoPocket.Address) {
+	n.m.Lock()
+	defer n.m.Unlock()
+
+	n.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, address := range group {
+			n.partitions[address.String()] = i + 1
+		}
+	}
+}
+
+// Removes every partition from the network.
+func (n *Network) Heal() {
+	n.Partition()
+}
+
+// Delivers an event to the node with the specified address after `delay` on the virtual clock. This is meant to
+// trigger local events (e.g. debug messages) in lockstep with the rest of the network.
+func (n *Network) Schedule(delay time.Duration, address cryptoPocket.Address, msg *anypb.Any, topic types.PocketTopic) error {
+	n.m.Lock()
+	defer n.m.Unlock()
+
+	to := address.String()
+	if _, ok := n.nodes[to]; !ok {
+		return fmt.Errorf("unknown node: %s", to)
+	}
+
+	n.push("", to, delay, &types.PocketEvent{Topic: topic, Data: msg})
+	return nil
+}
+
+// Delivers the next message in flight, moving the virtual clock forward to its delivery time. Returns false if
+// there are no messages in flight.
+func (n *Network) Step() bool {
+	return n.step(-1)
+}
+
+// Delivers every message due within `d` on the virtual clock, including the ones sent while handling them, and
+// then moves the clock forward by `d`. Returns the number of messages delivered.
+func (n *Network) RunFor(d time.Duration) (numDelivered int) {
+	deadline := n.Now() + d
+	for n.step(deadline) {
+		numDelivered++
+	}
+
+	n.m.Lock()
+	n.now = deadline
+	n.m.Unlock()
+
+	return
+}
+
+// Delivers messages until there are none in flight or `maxMessages` were delivered, which bounds protocols that
+// never go quiet. Returns the number of messages delivered.
+func (n *Network) RunUntilIdle(maxMessages int) (numDelivered int) {
+	for numDelivered < maxMessages && n.Step() {
+		numDelivered++
+	}
+	return
+}
+
+// Delivers messages until `condition` holds after one of them is handled. Returns false if the messages in flight
+// or the `maxMessages` budget ran out before it did.
+func (n *Network) RunUntil(condition func() bool, maxMessages int) bool {
+	for i := 0; i < maxMessages; i++ {
+		if condition() {
+			return true
+		}
+		if !n.Step() {
+			return false
+		}
+	}
+	return condition()
+}
+
+// Delivers the next message in flight that is due by `deadline`, or any message if `deadline` is negative.
+func (n *Network) step(deadline time.Duration) bool {
+	n.m.Lock()
+	if n.queue.Len() == 0 || (deadline >= 0 && n.queue[0].at > deadline) {
+		n.m.Unlock()
+		return false
+	}
+
+	e := heap.Pop(&n.queue).(*event)
+	n.now = e.at
+
+	if e.from != "" && n.partitions[e.from] != n.partitions[e.to] {
+		n.stats.Partitioned++
+		n.m.Unlock()
+		return true
+	}
+	n.stats.Delivered++
+	node := n.nodes[e.to]
+	n.m.Unlock()
+
+	// The lock is released since handling the message may send new ones through the network.
+	node.handleEvent(e.data)
+	return true
+}
+
+func (n *Network) broadcast(from string, data *types.PocketEvent, recipients []string) {
+	n.m.Lock()
+	defer n.m.Unlock()
+
+	for _, to := range recipients {
+		if _, ok := n.nodes[to]; ok {
+			n.send(from, to, data)
+		}
+	}
+}
+
+func (n *Network) sendTo(from string, address cryptoPocket.Address, data *types.PocketEvent) error {
+	n.m.Lock()
+	defer n.m.Unlock()
+
+	to := address.String()
+	if _, ok := n.nodes[to]; !ok {
+		return fmt.Errorf("unknown node: %s", to)
+	}
+
+	n.send(from, to, data)
+	return nil
+}
+
+// Must be called while holding the lock since it draws from the random source.
+func (n *Network) send(from, to string, data *types.PocketEvent) {
+	n.stats.Sent++
+
+	if n.rng.Float64() < n.cfg.DropRate {
+		n.stats.Dropped++
+		return
+	}
+
+	latency := n.cfg.MinLatency
+	if spread := n.cfg.MaxLatency - n.cfg.MinLatency; spread > 0 {
+		latency += time.Duration(n.rng.Int63n(int64(spread) + 1))
+	}
+	if n.rng.Float64() < n.cfg.ReorderRate {
+		latency += n.cfg.MaxLatency
+	}
+
+	// Every recipient gets its own copy so nodes can never share state through a message.
+	n.push(from, to, latency, proto.Clone(data).(*types.PocketEvent))
+}
+
+func (n *Network) push(from, to string, delay time.Duration, data *types.PocketEvent) {
+	n.seq++
+	heap.Push(&n.queue, &event{
+		at:   n.now + delay,
+		seq:  n.seq,
+		from: from,
+		to:   to,
+		data: data,
+	})
+}
+
+func (n *Network) register(node *p2pModule) {
+	n.m.Lock()
+	defer n.m.Unlock()
+
+	if _, ok := n.nodes[node.address]; ok {
+		log.Fatalf("Node %s is already part of the simulated network", node.address)
+	}
+	n.nodes[node.address] = node
+}
+
+// Orders events by delivery time, and then by the order in which they were sent.
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	return q[i].at < q[j].at || (q[i].at == q[j].at && q[i].seq < q[j].seq)
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,193 @@
+package simnet
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	typesPre2P "github.com/pokt-network/pocket/p2p/pre2p/types"
+	"github.com/pokt-network/pocket/shared"
+	cryptoPocket "github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
+	modulesMock "github.com/pokt-network/pocket/shared/modules/mocks"
+	"github.com/pokt-network/pocket/shared/types"
+	"github.com/pokt-network/pocket/shared/types/genesis"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNetworkDeliversMessagesInOrderAfterLatency(t *testing.T) {
+	network, err := NewNetwork(Config{Seed: 1, MinLatency: 10 * time.Millisecond, MaxLatency: 10 * time.Millisecond})
+	require.NoError(t, err)
+	nodes, deliveries := createTestNodes(t, network, 2)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, nodes[0].Send(nodes[1].address(), testMessage(t, fmt.Sprint(i)), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	}
+
+	// Nothing is delivered until the virtual clock reaches the latency of the messages
+	require.Equal(t, 0, network.RunFor(9*time.Millisecond))
+	require.Equal(t, 3, network.RunFor(time.Millisecond))
+	require.Equal(t, 10*time.Millisecond, network.Now())
+
+	require.Equal(t, []string{"1:0", "1:1", "1:2"}, *deliveries)
+	require.Equal(t, Stats{Sent: 3, Delivered: 3}, network.Stats())
+}
+
+func TestNetworkBroadcastReachesEveryValidatorIncludingSender(t *testing.T) {
+	network, err := NewNetwork(Config{Seed: 1})
+	require.NoError(t, err)
+	nodes, deliveries := createTestNodes(t, network, 3)
+
+	require.NoError(t, nodes[1].Broadcast(testMessage(t, "m"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	require.Equal(t, 3, network.RunUntilIdle(100))
+	require.ElementsMatch(t, []string{"0:m", "1:m", "2:m"}, *deliveries)
+}
+
+func TestNetworkIsDeterministicForTheSameSeed(t *testing.T) {
+	run := func(seed int64) []string {
+		network, err := NewNetwork(Config{
+			Seed:        seed,
+			MinLatency:  5 * time.Millisecond,
+			MaxLatency:  50 * time.Millisecond,
+			DropRate:    0.2,
+			ReorderRate: 0.3,
+		})
+		require.NoError(t, err)
+		nodes, deliveries := createTestNodes(t, network, 4)
+
+		for i := 0; i < 20; i++ {
+			require.NoError(t, nodes[i%len(nodes)].Broadcast(testMessage(t, fmt.Sprint(i)), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+		}
+		network.RunUntilIdle(1000)
+		return *deliveries
+	}
+
+	firstRun := run(42)
+	require.Equal(t, firstRun, run(42))
+	require.NotEqual(t, firstRun, run(43))
+
+	// Messages were both dropped and reordered
+	require.Less(t, len(firstRun), 80)
+	require.NotEqual(t, "0:0", firstRun[0])
+}
+
+func TestNetworkPartitionsAndHeals(t *testing.T) {
+	network, err := NewNetwork(Config{Seed: 1, MinLatency: time.Millisecond, MaxLatency: time.Millisecond})
+	require.NoError(t, err)
+	nodes, deliveries := createTestNodes(t, network, 3)
+
+	// Node 0 is isolated from nodes 1 and 2
+	network.Partition([]cryptoPocket.Address{nodes[0].address()})
+	require.NoError(t, nodes[0].Broadcast(testMessage(t, "isolated"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	require.NoError(t, nodes[1].Send(nodes[2].address(), testMessage(t, "majority"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	network.RunUntilIdle(100)
+	require.ElementsMatch(t, []string{"0:isolated", "2:majority"}, *deliveries)
+	require.Equal(t, 2, network.Stats().Partitioned)
+
+	// Messages in flight when the network heals are delivered
+	require.NoError(t, nodes[0].Send(nodes[1].address(), testMessage(t, "healed"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	network.Heal()
+	network.RunUntilIdle(100)
+	require.Contains(t, *deliveries, "1:healed")
+}
+
+func TestNetworkSchedulesEventsOnTheVirtualClock(t *testing.T) {
+	network, err := NewNetwork(Config{Seed: 1, DropRate: 1})
+	require.NoError(t, err)
+	nodes, deliveries := createTestNodes(t, network, 2)
+
+	// Scheduled events are never dropped, unlike messages sent through the network
+	require.NoError(t, network.Schedule(time.Second, nodes[1].address(), testMessage(t, "scheduled"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	require.NoError(t, nodes[0].Send(nodes[1].address(), testMessage(t, "dropped"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+
+	require.True(t, network.RunUntil(func() bool { return len(*deliveries) == 1 }, 10))
+	require.Equal(t, []string{"1:scheduled"}, *deliveries)
+	require.Equal(t, time.Second, network.Now())
+	require.Equal(t, 1, network.Stats().Dropped)
+
+	require.Error(t, nodes[0].Send(cryptoPocket.Address("unknown"), testMessage(t, "unknown"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+}
+
+func TestHandleAddrBookEvent(t *testing.T) {
+	network, err := NewNetwork(Config{Seed: 1})
+	require.NoError(t, err)
+	nodes, deliveries := createTestNodes(t, network, 3)
+
+	removeNode := &types.AddrBookChange{Type: types.AddrBookChangeType_ADDRBOOK_CHANGE_REMOVE, Address: nodes[2].address()}
+	require.NoError(t, nodes[0].HandleAddrBookEvent(&types.AddrBookEvent{Changes: []*types.AddrBookChange{removeNode}}))
+	require.NoError(t, nodes[0].Broadcast(testMessage(t, "removed"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	network.RunUntilIdle(100)
+	require.ElementsMatch(t, []string{"0:removed", "1:removed"}, *deliveries)
+
+	// Peers that are added or updated are identified by their public key
+	addNode := &types.AddrBookChange{Type: types.AddrBookChangeType_ADDRBOOK_CHANGE_ADD, PublicKey: nodes[2].privateKey.PublicKey().Bytes()}
+	updateNode := &types.AddrBookChange{Type: types.AddrBookChangeType_ADDRBOOK_CHANGE_UPDATE, PublicKey: nodes[2].privateKey.PublicKey().Bytes()}
+	require.Equal(t, typesPre2P.ErrPeerNotInAddrBook, nodes[0].HandleAddrBookEvent(&types.AddrBookEvent{Changes: []*types.AddrBookChange{updateNode}}))
+	require.NoError(t, nodes[0].HandleAddrBookEvent(&types.AddrBookEvent{Changes: []*types.AddrBookChange{addNode, updateNode}}))
+	require.Equal(t, typesPre2P.ErrPeerAlreadyInAddrBook, nodes[0].HandleAddrBookEvent(&types.AddrBookEvent{Changes: []*types.AddrBookChange{addNode}}))
+	require.NoError(t, nodes[0].Broadcast(testMessage(t, "added"), types.PocketTopic_CONSENSUS_MESSAGE_TOPIC))
+	network.RunUntilIdle(100)
+	require.Contains(t, *deliveries, "2:added")
+}
+
+type testNode struct {
+	modules.P2PModule
+	privateKey cryptoPocket.PrivateKey
+}
+
+func (n *testNode) address() cryptoPocket.Address {
+	return n.privateKey.Address()
+}
+
+// Creates nodes whose consensus module records every message it handles as "<node index>:<message>".
+func createTestNodes(t *testing.T, network *Network, numNodes int) ([]*testNode, *[]string) {
+	ctrl := gomock.NewController(t)
+	deliveries := make([]string, 0)
+
+	validators := make(modules.ValidatorMap, numNodes)
+	nodes := make([]*testNode, numNodes)
+	for i := range nodes {
+		seed := make([]byte, ed25519.PrivateKeySize)
+		binary.LittleEndian.PutUint32(seed, uint32(i+1))
+		privateKey, err := cryptoPocket.NewPrivateKeyFromSeed(seed)
+		require.NoError(t, err)
+
+		nodes[i] = &testNode{
+			P2PModule:  network.CreateP2PModule(privateKey.Address()),
+			privateKey: privateKey,
+		}
+		validators[privateKey.Address().String()] = &genesis.Validator{Address: privateKey.Address()}
+	}
+
+	for i, node := range nodes {
+		nodeIndex := i
+		consensusMock := modulesMock.NewMockConsensusModule(ctrl)
+		consensusMock.EXPECT().SetBus(gomock.Any()).AnyTimes()
+		consensusMock.EXPECT().ValidatorMap().Return(validators).AnyTimes()
+		consensusMock.EXPECT().
+			HandleMessage(gomock.Any()).
+			DoAndReturn(func(msg *anypb.Any) error {
+				var value wrapperspb.StringValue
+				require.NoError(t, msg.UnmarshalTo(&value))
+				deliveries = append(deliveries, fmt.Sprintf("%d:%s", nodeIndex, value.Value))
+				return nil
+			}).
+			AnyTimes()
+
+		shared.CreateBusWithOptionalModules(nil, node.P2PModule, nil, consensusMock)
+		require.NoError(t, node.Start())
+	}
+
+	return nodes, &deliveries
+}
+
+func testMessage(t *testing.T, value string) *anypb.Any {
+	msg, err := anypb.New(wrapperspb.String(value))
+	require.NoError(t, err)
+	return msg
+}
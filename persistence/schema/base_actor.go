@@ -68,6 +68,10 @@ func (actor *BaseProtocolActorSchema) GetQuery(address string, height int64) str
 	return Select(AllColsSelector, address, height, actor.tableName)
 }
 
+func (actor *BaseProtocolActorSchema) GetAllQuery(height int64) string {
+	return SelectAll(AllColsSelector, height, actor.tableName)
+}
+
 func (actor *BaseProtocolActorSchema) GetExistsQuery(address string, height int64) string {
 	return Exists(address, height, actor.tableName)
 }
@@ -18,6 +18,8 @@ type ProtocolActorSchema interface {
 
 	// Returns a query to retrieve all of a single Actor's attributes.
 	GetQuery(address string, height int64) string
+	// Returns a query to retrieve all of the attributes of every Actor.
+	GetAllQuery(height int64) string
 	// Returns a query for the existence of an Actor given its address.
 	GetExistsQuery(address string, height int64) string
 	// Returns a query to retrieve data associated with all the apps ready to unstake.
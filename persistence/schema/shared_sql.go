@@ -74,6 +74,16 @@ func Select(selector, address string, height int64, tableName string) string {
 		selector, tableName, address, height)
 }
 
+// Explainer:
+//   (SELECT MAX(height), address FROM %s WHERE height<=%d GROUP BY address) ->
+//       returns the latest height, at or before the one specified, for each address
+func SelectAll(selector string, height int64, tableName string) string {
+	return fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE (height, address) IN (SELECT MAX(height), address FROM %s WHERE height<=%d GROUP BY address)`,
+		selector, tableName, tableName, height)
+}
+
 func SelectChains(selector, address string, height int64, actorTableName, chainsTableName string) string {
 	return fmt.Sprintf(`SELECT %s FROM %s WHERE address='%s' AND height=(%s);`,
 		selector, chainsTableName, address, Select(HeightCol, address, height, actorTableName))
@@ -82,6 +82,34 @@ func (p *PostgresContext) GetActor(actorSchema schema.ProtocolActorSchema, addre
 	return
 }
 
+// NOTE: The chains each Actor is staked for are not retrieved.
+func (p *PostgresContext) GetAllActors(actorSchema schema.ProtocolActorSchema, height int64) (actors []schema.BaseActor, err error) {
+	ctx, conn, err := p.DB.GetCtxAndConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, actorSchema.GetAllQuery(height))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actorHeight int64 // unused
+	for rows.Next() {
+		actor := schema.BaseActor{}
+		if err = rows.Scan(
+			&actor.Address, &actor.PublicKey, &actor.StakedTokens, &actor.ActorSpecificParam,
+			&actor.OutputAddress, &actor.PausedHeight, &actor.UnstakingHeight,
+			&actorHeight,
+		); err != nil {
+			return
+		}
+		actors = append(actors, actor)
+	}
+	return
+}
+
 func (p *PostgresContext) InsertActor(actorSchema schema.ProtocolActorSchema, actor schema.BaseActor) error {
 	ctx, conn, err := p.DB.GetCtxAndConnection()
 	if err != nil {
@@ -47,6 +47,40 @@ func TestInsertValidatorAndExists(t *testing.T) {
 	require.True(t, exists, "actor that should exist at current height does not")
 }
 
+func TestGetAllValidators(t *testing.T) {
+	db := &persistence.PostgresContext{
+		Height: 0,
+		DB:     *PostgresDB,
+	}
+
+	validator, err := createAndInsertDefaultTestValidator(db)
+	require.NoError(t, err)
+
+	db.Height = 1
+
+	validator2, err := createAndInsertDefaultTestValidator(db)
+	require.NoError(t, err)
+
+	containsValidator := func(validators []*typesGenesis.Validator, address []byte) bool {
+		for _, v := range validators {
+			if hex.EncodeToString(v.Address) == hex.EncodeToString(address) {
+				return true
+			}
+		}
+		return false
+	}
+
+	validators, err := db.GetAllValidators(0)
+	require.NoError(t, err)
+	require.True(t, containsValidator(validators, validator.Address), "actor that should exist at previous height is missing")
+	require.False(t, containsValidator(validators, validator2.Address), "actor that should not exist at previous height appears to")
+
+	validators, err = db.GetAllValidators(1)
+	require.NoError(t, err)
+	require.True(t, containsValidator(validators, validator.Address), "actor that should exist at current height is missing")
+	require.True(t, containsValidator(validators, validator2.Address), "actor that should exist at current height is missing")
+}
+
 func TestUpdateValidator(t *testing.T) {
 	db := &persistence.PostgresContext{
 		Height: 0,
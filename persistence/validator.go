@@ -6,8 +6,38 @@ import (
 
 	"github.com/pokt-network/pocket/persistence/schema"
 	"github.com/pokt-network/pocket/shared/types"
+	typesGenesis "github.com/pokt-network/pocket/shared/types/genesis"
 )
 
+func (p PostgresContext) GetAllValidators(height int64) (validators []*typesGenesis.Validator, err error) {
+	actors, err := p.GetAllActors(schema.ValidatorActor, height)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, actor := range actors {
+		validator := &typesGenesis.Validator{
+			Paused:          actor.PausedHeight != schema.DefaultBigInt,
+			Status:          UnstakingHeightToStatus(actor.UnstakingHeight),
+			ServiceUrl:      actor.ActorSpecificParam,
+			StakedTokens:    actor.StakedTokens,
+			PausedHeight:    actor.PausedHeight,
+			UnstakingHeight: actor.UnstakingHeight,
+		}
+		if validator.Address, err = hex.DecodeString(actor.Address); err != nil {
+			return nil, err
+		}
+		if validator.PublicKey, err = hex.DecodeString(actor.PublicKey); err != nil {
+			return nil, err
+		}
+		if validator.Output, err = hex.DecodeString(actor.OutputAddress); err != nil {
+			return nil, err
+		}
+		validators = append(validators, validator)
+	}
+	return
+}
+
 func (p PostgresContext) GetValidatorExists(address []byte, height int64) (exists bool, err error) {
 	return p.GetExists(schema.ValidatorActor, address, height)
 }
@@ -34,11 +34,52 @@ const (
 	EmptyConnection ConnectionType = "empty" // Only used for testing
 )
 
+type LeaderElectionType string
+
+const (
+	RoundRobinLeaderElection   LeaderElectionType = "round_robin"
+	VRFSortitionLeaderElection LeaderElectionType = "vrf_sortition" // Stake weighted leader election using VRFs and cryptographic sortition
+)
+
+type HotstuffMode string
+
+const (
+	BasicHotstuff   HotstuffMode = "basic"
+	ChainedHotstuff HotstuffMode = "chained" // Pipelined HotStuff where the QC of every block doubles as the PREPARE QC of the next one
+)
+
+type ThresholdSignatureScheme string
+
+const (
+	PartialSignatureListScheme ThresholdSignatureScheme = "partial_signatures" // Every partial signature is included in the QC alongside the signer's address
+	AggregateSignatureScheme   ThresholdSignatureScheme = "aggregate"          // Half-aggregated ed25519 signatures with a signer bitmap
+)
+
 // TECHDEBT(team): consolidate/replace this with P2P configs depending on next steps
 type Pre2PConfig struct {
 	ConsensusPort  uint32         `json:"consensus_port"`
 	UseRainTree    bool           `json:"use_raintree"`
 	ConnectionType ConnectionType `json:"connection_type"`
+
+	// TCP connections are kept open per peer and carry length prefixed messages
+	MaxMessageBytes         uint32 `json:"max_message_bytes"`          // Larger messages are neither sent nor accepted
+	WriteQueueSize          uint32 `json:"write_queue_size"`           // Messages waiting to be sent to a peer; writes fail once it is full
+	MessageTTLMsec          uint64 `json:"message_ttl_msec"`           // Queued messages that could not be sent within this delay are dropped
+	IdleTimeoutMsec         uint64 `json:"idle_timeout_msec"`          // Connections to peers are closed once no message was sent for this long
+	ConnectionTimeoutMsec   uint64 `json:"connection_timeout_msec"`    // Timeout for connecting to a peer and for sending a message to it
+	ReconnectBackoffMsec    uint64 `json:"reconnect_backoff_msec"`     // Delay before reconnecting to a peer, doubled after every failed attempt...
+	ReconnectBackoffMaxMsec uint64 `json:"reconnect_backoff_max_msec"` // ...up to this delay
+
+	// TCP connections are authenticated with the nodes' keys and encrypted
+	RequireKnownPeers bool `json:"require_known_peers"` // Connections are only accepted from peers in the address book if set, as validators should
+
+	// RainTree re-sends messages to the neighbours that did not acknowledge them
+	RainTreeCleanupDelayMsec uint64 `json:"raintree_cleanup_delay_msec"` // Delay before re-sending a message to the neighbours that did not acknowledge it...
+	RainTreeCleanupMaxRounds uint32 `json:"raintree_cleanup_max_rounds"` // ...at most this many times
+
+	// RainTree targets acknowledge the messages sent to them through the tree
+	RainTreeAckTimeoutMsec uint64 `json:"raintree_ack_timeout_msec"` // The message is sent to the next peer at the same level if its target does not acknowledge it in time...
+	RainTreeMaxRetries     uint32 `json:"raintree_max_retries"`      // ...at most this many times per target
 }
 
 type PrePersistenceConfig struct {
@@ -61,10 +102,34 @@ type P2PConfig struct {
 	TimeoutInMs      uint     `json:"timeout_in_ms"`
 }
 
+const (
+	defaultPre2PMaxMessageBytes          = uint32(16 * 1024 * 1024)
+	defaultPre2PWriteQueueSize           = uint32(128)
+	defaultPre2PMessageTTLMsec           = uint64(5000)
+	defaultPre2PIdleTimeoutMsec          = uint64(60000)
+	defaultPre2PConnectionTimeoutMsec    = uint64(5000)
+	defaultPre2PReconnectBackoffMsec     = uint64(100)
+	defaultPre2PReconnectBackoffMaxMsec  = uint64(10000)
+	defaultPre2PRainTreeCleanupDelayMsec = uint64(500)
+	defaultPre2PRainTreeCleanupMaxRounds = uint32(3)
+	defaultPre2PRainTreeAckTimeoutMsec   = uint64(200)
+	defaultPre2PRainTreeMaxRetries       = uint32(2)
+)
+
+const (
+	defaultPacemakerTimeoutMultiplier = float64(1) // Exponential backoff is disabled unless configured
+	defaultPacemakerTimeoutMaxMsec    = uint64(60000)
+)
+
 type PacemakerConfig struct {
 	TimeoutMsec               uint64 `json:"timeout_msec"`
 	Manual                    bool   `json:"manual"`
 	DebugTimeBetweenStepsMsec uint64 `json:"debug_time_between_steps_msec"`
+
+	// Step timeouts grow exponentially with every failed round at the same height: min(TimeoutMsec * TimeoutMultiplier^round, TimeoutMaxMsec)
+	TimeoutMultiplier float64 `json:"timeout_multiplier"`
+	TimeoutMaxMsec    uint64  `json:"timeout_max_msec"`
+	TimeoutJitter     float64 `json:"timeout_jitter"` // Up to this fraction of the timeout is randomly added to it
 }
 
 type ConsensusConfig struct {
@@ -76,6 +141,18 @@ type ConsensusConfig struct {
 
 	// Pacemaker
 	Pacemaker *PacemakerConfig `json:"pacemaker"`
+
+	// Leader Election
+	LeaderElection LeaderElectionType `json:"leader_election"` // Defaults to round robin if not specified
+
+	// Hotstuff
+	HotstuffMode HotstuffMode `json:"hotstuff_mode"` // Defaults to basic if not specified. Chained validators only persist their last signed state in the root directory, not a WAL
+
+	// Quorum Certificates
+	ThresholdSignature ThresholdSignatureScheme `json:"threshold_signature"` // Defaults to partial signatures if not specified; must be the same for all validators
+
+	// Debugging
+	TraceFile string `json:"trace_file"` // Every consensus message sent and received is recorded in this file if specified; overwritten every time the node starts
 }
 
 type PersistenceConfig struct {
@@ -133,6 +210,12 @@ func (c *Config) ValidateAndHydrate() error {
 		return fmt.Errorf("error validating or completing P2P config: %v", err)
 	}
 
+	if c.Pre2P != nil {
+		if err := c.Pre2P.ValidateAndHydrate(); err != nil {
+			return fmt.Errorf("error validating or completing Pre2P config: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -151,9 +234,64 @@ func (c *P2PConfig) ValidateAndHydrate() error {
 	return nil
 }
 
+func (c *Pre2PConfig) ValidateAndHydrate() error {
+	if c.MaxMessageBytes == 0 {
+		c.MaxMessageBytes = defaultPre2PMaxMessageBytes
+	}
+
+	if c.WriteQueueSize == 0 {
+		c.WriteQueueSize = defaultPre2PWriteQueueSize
+	}
+
+	if c.MessageTTLMsec == 0 {
+		c.MessageTTLMsec = defaultPre2PMessageTTLMsec
+	}
+
+	if c.IdleTimeoutMsec == 0 {
+		c.IdleTimeoutMsec = defaultPre2PIdleTimeoutMsec
+	}
+
+	if c.ConnectionTimeoutMsec == 0 {
+		c.ConnectionTimeoutMsec = defaultPre2PConnectionTimeoutMsec
+	}
+
+	if c.ReconnectBackoffMsec == 0 {
+		c.ReconnectBackoffMsec = defaultPre2PReconnectBackoffMsec
+	}
+
+	if c.ReconnectBackoffMaxMsec == 0 {
+		c.ReconnectBackoffMaxMsec = defaultPre2PReconnectBackoffMaxMsec
+		if c.ReconnectBackoffMsec > c.ReconnectBackoffMaxMsec {
+			c.ReconnectBackoffMaxMsec = c.ReconnectBackoffMsec
+		}
+	}
+
+	if c.ReconnectBackoffMaxMsec < c.ReconnectBackoffMsec {
+		return fmt.Errorf("ReconnectBackoffMaxMsec must be at least ReconnectBackoffMsec")
+	}
+
+	if c.RainTreeCleanupDelayMsec == 0 {
+		c.RainTreeCleanupDelayMsec = defaultPre2PRainTreeCleanupDelayMsec
+	}
+
+	if c.RainTreeCleanupMaxRounds == 0 {
+		c.RainTreeCleanupMaxRounds = defaultPre2PRainTreeCleanupMaxRounds
+	}
+
+	if c.RainTreeAckTimeoutMsec == 0 {
+		c.RainTreeAckTimeoutMsec = defaultPre2PRainTreeAckTimeoutMsec
+	}
+
+	if c.RainTreeMaxRetries == 0 {
+		c.RainTreeMaxRetries = defaultPre2PRainTreeMaxRetries
+	}
+
+	return nil
+}
+
 func (c *ConsensusConfig) ValidateAndHydrate() error {
 	if err := c.Pacemaker.ValidateAndHydrate(); err != nil {
-		log.Fatalf("Error validating or completing Pacemaker configs")
+		return fmt.Errorf("error validating or completing Pacemaker config: %v", err)
 	}
 
 	if c.MaxMempoolBytes <= 0 {
@@ -164,9 +302,61 @@ func (c *ConsensusConfig) ValidateAndHydrate() error {
 		return fmt.Errorf("MaxBlockBytes must be a positive integer")
 	}
 
+	switch c.LeaderElection {
+	case "":
+		c.LeaderElection = RoundRobinLeaderElection
+	case RoundRobinLeaderElection, VRFSortitionLeaderElection:
+	default:
+		return fmt.Errorf("unknown leader election type: %s", c.LeaderElection)
+	}
+
+	switch c.HotstuffMode {
+	case "":
+		c.HotstuffMode = BasicHotstuff
+	case BasicHotstuff:
+	case ChainedHotstuff:
+		// Votes are sent to the leader of the next height before it is known which validators can claim it.
+		if c.LeaderElection != RoundRobinLeaderElection {
+			return fmt.Errorf("chained hotstuff requires %s leader election", RoundRobinLeaderElection)
+		}
+	default:
+		return fmt.Errorf("unknown hotstuff mode: %s", c.HotstuffMode)
+	}
+
+	switch c.ThresholdSignature {
+	case "":
+		c.ThresholdSignature = PartialSignatureListScheme
+	case PartialSignatureListScheme, AggregateSignatureScheme:
+	default:
+		return fmt.Errorf("unknown threshold signature scheme: %s", c.ThresholdSignature)
+	}
+
 	return nil
 }
 
 func (c *PacemakerConfig) ValidateAndHydrate() error {
+	if c.TimeoutMultiplier == 0 {
+		c.TimeoutMultiplier = defaultPacemakerTimeoutMultiplier
+	}
+
+	if c.TimeoutMaxMsec == 0 {
+		c.TimeoutMaxMsec = defaultPacemakerTimeoutMaxMsec
+		if c.TimeoutMsec > c.TimeoutMaxMsec {
+			c.TimeoutMaxMsec = c.TimeoutMsec
+		}
+	}
+
+	if c.TimeoutMultiplier < 1 {
+		return fmt.Errorf("TimeoutMultiplier must be at least 1")
+	}
+
+	if c.TimeoutMaxMsec < c.TimeoutMsec {
+		return fmt.Errorf("TimeoutMaxMsec must be at least TimeoutMsec")
+	}
+
+	if c.TimeoutJitter < 0 || c.TimeoutJitter > 1 {
+		return fmt.Errorf("TimeoutJitter must be between 0 and 1")
+	}
+
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/pokt-network/pocket/shared/types/genesis"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +60,65 @@ func TestLoadConfigFromJson(t *testing.T) {
 	err := json.Unmarshal([]byte(config), &c)
 	require.NoError(t, err)
 }
+
+func TestConsensusConfigHotstuffMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		hotstuffMode   HotstuffMode
+		leaderElection LeaderElectionType
+		expectedMode   HotstuffMode
+		expectErr      bool
+	}{
+		{"defaults to basic", "", RoundRobinLeaderElection, BasicHotstuff, false},
+		{"chained with round robin", ChainedHotstuff, RoundRobinLeaderElection, ChainedHotstuff, false},
+		{"chained with vrf sortition", ChainedHotstuff, VRFSortitionLeaderElection, "", true},
+		{"unknown mode", "pipelined", RoundRobinLeaderElection, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ConsensusConfig{
+				MaxMempoolBytes: 500000000,
+				MaxBlockBytes:   4000000,
+				Pacemaker:       &PacemakerConfig{TimeoutMsec: 5000},
+				LeaderElection:  tt.leaderElection,
+				HotstuffMode:    tt.hotstuffMode,
+			}
+			err := c.ValidateAndHydrate()
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedMode, c.HotstuffMode)
+		})
+	}
+}
+
+func TestConsensusConfigInvalidPacemaker(t *testing.T) {
+	c := &ConsensusConfig{
+		MaxMempoolBytes: 500000000,
+		MaxBlockBytes:   4000000,
+		Pacemaker:       &PacemakerConfig{TimeoutMsec: 5000, TimeoutJitter: 2},
+	}
+	require.Error(t, c.ValidateAndHydrate())
+}
+
+func TestConfigChainedHotstuffWithRootDir(t *testing.T) {
+	for _, rootDir := range []string{"", t.TempDir()} {
+		c := &Config{
+			RootDir:       rootDir,
+			PrivateKey:    make([]byte, 64),
+			GenesisSource: &genesis.GenesisSource{Source: &genesis.GenesisSource_State{State: &genesis.GenesisState{}}},
+			Consensus: &ConsensusConfig{
+				MaxMempoolBytes: 500000000,
+				MaxBlockBytes:   4000000,
+				Pacemaker:       &PacemakerConfig{TimeoutMsec: 5000},
+				HotstuffMode:    ChainedHotstuff,
+			},
+			P2P: &P2PConfig{},
+		}
+		// The root directory only holds the last signed state of chained validators
+		require.NoError(t, c.ValidateAndHydrate())
+	}
+}
@@ -0,0 +1,34 @@
+package crypto
+
+// Leaves and inner nodes are hashed with different prefixes so an inner node can never be passed off as a leaf.
+var (
+	merkleLeafPrefix  = []byte{0}
+	merkleInnerPrefix = []byte{1}
+)
+
+// Returns the root of a binary Merkle tree built over `leaves` in order. When a level has an odd number of
+// nodes, the last one is promoted to the next level as is. The root of an empty tree is the hash of no data.
+func MerkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return SHA3Hash(nil)
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = SHA3Hash(append(append([]byte{}, merkleLeafPrefix...), leaf...))
+	}
+
+	for len(level) > 1 {
+		nextLevel := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				nextLevel = append(nextLevel, level[i])
+				continue
+			}
+			node := append(append([]byte{}, merkleInnerPrefix...), level[i]...)
+			nextLevel = append(nextLevel, SHA3Hash(append(node, level[i+1]...)))
+		}
+		level = nextLevel
+	}
+	return level[0]
+}
@@ -14,9 +14,15 @@ type ConsensusModule interface {
 	// Consensus Engine
 	HandleMessage(*anypb.Any) error
 	HandleDebugMessage(*types.DebugMessage) error
+	SubmitTransaction(tx []byte) error // Checks the transaction against the utility mempool before gossiping it to the network
 
 	// Consensus State
 	BlockHeight() uint64
-	AppHash() string            // DISCUSS: Why not call this a BlockHash or StateHash? Should it be a []byte or string?
-	ValidatorMap() ValidatorMap // TODO: This needs to be dynamically updated during various operations and network changes.
+	BlockHash() string          // The hash of the last committed block
+	AppHash() string            // The state hash after applying the last committed block. DISCUSS: Should it be a []byte or string?
+	ValidatorMap() ValidatorMap // Updated every time a committed block changes the validator set
+
+	// Consensus Observability
+	StateSnapshot() *types.ConsensusStateSnapshot          // Safe to call concurrently
+	SubscribeToEvents(events chan<- *types.ConsensusEvent) // Every following state transition is also sent to `events`, besides the bus; events are dropped if it is full
 }
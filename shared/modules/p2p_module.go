@@ -10,4 +10,8 @@ type P2PModule interface {
 	Module
 	Broadcast(msg *anypb.Any, topic types.PocketTopic) error                       // TODO(derrandz): get rid of topic
 	Send(addr cryptoPocket.Address, msg *anypb.Any, topic types.PocketTopic) error // TODO(derrandz): get rid of topic
+	// Updates the set of peers the node communicates with every time the validator set changes.
+	UpdateAddrBook(validators ValidatorMap) error
+	// Applies the changes to the address book other modules publish on the bus under `ADDRBOOK_EVENT_TOPIC`.
+	HandleAddrBookEvent(event *types.AddrBookEvent) error
 }
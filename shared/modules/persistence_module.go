@@ -2,6 +2,7 @@ package modules
 
 import (
 	"github.com/pokt-network/pocket/shared/types"
+	typesGenesis "github.com/pokt-network/pocket/shared/types/genesis"
 	"github.com/syndtr/goleveldb/leveldb/memdb"
 )
 
@@ -95,6 +96,7 @@ type PersistenceContext interface {
 	GetFishermanOutputAddress(operator []byte, height int64) (output []byte, err error)
 
 	// Validator Operations
+	GetAllValidators(height int64) (validators []*typesGenesis.Validator, err error)
 	GetValidatorExists(address []byte, height int64) (exists bool, err error)
 	InsertValidator(address []byte, publicKey []byte, output []byte, paused bool, status int, serviceURL string, stakedTokens string, pausedHeight int64, unstakingHeight int64) error
 	UpdateValidator(address []byte, serviceURL string, amountToAdd string) error
@@ -1,5 +1,7 @@
 package modules
 
+import typesGenesis "github.com/pokt-network/pocket/shared/types/genesis"
+
 type UnstakingActor interface {
 	GetAddress() []byte
 	GetStakeAmount() string
@@ -10,9 +12,19 @@ type UnstakingActor interface {
 type UtilityContext interface {
 	ReleaseContext()
 	GetPersistenceContext() PersistenceContext
+	CommitContext() error // Commits the persistence context and removes the transactions of the applied block from the mempool
 	CheckTransaction(tx []byte) error
-	GetTransactionsForProposal(proposer []byte, maxTransactionBytes int, lastBlockByzantineValidators [][]byte) (transactions [][]byte, err error)
-	ApplyBlock(Height int64, proposer []byte, transactions [][]byte, lastBlockByzantineValidators [][]byte) (appHash []byte, err error)
+	// The missed validators did not sign the COMMIT QC of the last block, which is counted against their liveness
+	// (see `ValidatorMaxMissedBlocks`) since honest votes can arrive after the QC is formed. Byzantine validators are
+	// punished through `MessageDoubleSign` evidence instead.
+	GetTransactionsForProposal(proposer []byte, maxTransactionBytes int, lastBlockMissedValidators [][]byte) (transactions [][]byte, err error)
+	ApplyBlock(Height int64, proposer []byte, transactions [][]byte, lastBlockMissedValidators [][]byte) (appHash []byte, validatorUpdates []*ValidatorUpdate, err error)
+}
+
+// A change to the active validator set caused by applying a block. It should only take effect once the block is committed.
+type ValidatorUpdate struct {
+	Validator *typesGenesis.Validator
+	Removed   bool // The validator is no longer active (i.e. it is paused, unstaking or unstaked)
 }
 
 type UtilityModule interface {
@@ -124,12 +124,24 @@ func (node *Node) handleEvent(event *types.PocketEvent) error {
 		return node.handleDebugEvent(event.Data)
 	case types.PocketTopic_POCKET_NODE_TOPIC:
 		log.Println("[NOOP] Received pocket node topic signal")
+	case types.PocketTopic_ADDRBOOK_EVENT_TOPIC:
+		return node.handleAddrBookEvent(event.Data)
+	case types.PocketTopic_CONSENSUS_EVENT_TOPIC:
+		// NOOP: Consensus events are only published for observability purposes
 	default:
 		log.Printf("[WARN] Unsupported PocketEvent topic: %s \n", event.Topic)
 	}
 	return nil
 }
 
+func (node *Node) handleAddrBookEvent(anyMessage *anypb.Any) error {
+	var addrBookEvent types.AddrBookEvent
+	if err := anypb.UnmarshalTo(anyMessage, &addrBookEvent, proto.UnmarshalOptions{}); err != nil {
+		return err
+	}
+	return node.GetBus().GetP2PModule().HandleAddrBookEvent(&addrBookEvent)
+}
+
 func (node *Node) handleDebugEvent(anyMessage *anypb.Any) error {
 	var debugMessage types.DebugMessage
 	err := anypb.UnmarshalTo(anyMessage, &debugMessage, proto.UnmarshalOptions{})
@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/pokt-network/pocket/shared/crypto"
 	typesGenesis "github.com/pokt-network/pocket/shared/types/genesis"
 	typesUtil "github.com/pokt-network/pocket/utility/types"
 	"github.com/stretchr/testify/require"
@@ -22,7 +23,7 @@ func TestUtilityContext_ApplyBlock(t *testing.T) {
 	proposerBeforeBalance, err := ctx.GetAccountAmount(proposer.Address)
 	require.NoError(t, err)
 	// apply block
-	if _, err := ctx.ApplyBlock(0, proposer.Address, [][]byte{txBz}, [][]byte{byzantine.Address}); err != nil {
+	if _, _, err := ctx.ApplyBlock(0, proposer.Address, [][]byte{txBz}, [][]byte{byzantine.Address}); err != nil {
 		t.Fatal(err)
 	}
 	// beginBlock logic verify
@@ -58,6 +59,29 @@ func TestUtilityContext_ApplyBlock(t *testing.T) {
 	}
 }
 
+func TestUtilityContext_CommitContextRemovesTransactionsFromMempool(t *testing.T) {
+	ctx := NewTestingUtilityContext(t, 0)
+	pendingTx, _, _, _ := NewTestingTransaction(t, ctx)
+	appliedTx, _, _, _ := NewTestingTransaction(t, ctx)
+	proposer := GetAllTestingValidators(t, ctx)[0]
+	pendingTxBz, err := pendingTx.Bytes()
+	require.NoError(t, err)
+	appliedTxBz, err := appliedTx.Bytes()
+	require.NoError(t, err)
+	// both transactions were gossiped to this node
+	require.NoError(t, ctx.CheckTransaction(pendingTxBz))
+	require.NoError(t, ctx.CheckTransaction(appliedTxBz))
+	// apply a block that only includes one of them
+	_, _, er := ctx.ApplyBlock(0, proposer.Address, [][]byte{appliedTxBz}, nil)
+	require.NoError(t, er)
+	// the block may still not be committed, so both transactions stay pending until then
+	require.Equal(t, 2, ctx.Mempool.Size())
+	require.NoError(t, ctx.CommitContext())
+	require.False(t, ctx.Mempool.Contains(typesUtil.TransactionHash(appliedTxBz)))
+	require.True(t, ctx.Mempool.Contains(typesUtil.TransactionHash(pendingTxBz)))
+	require.Equal(t, 1, ctx.Mempool.Size())
+}
+
 func TestUtilityContext_BeginBlock(t *testing.T) {
 	ctx := NewTestingUtilityContext(t, 0)
 	tx, _, _, _ := NewTestingTransaction(t, ctx)
@@ -67,7 +91,7 @@ func TestUtilityContext_BeginBlock(t *testing.T) {
 	txBz, err := tx.Bytes()
 	require.NoError(t, err)
 	// apply block
-	if _, err := ctx.ApplyBlock(0, proposer.Address, [][]byte{txBz}, [][]byte{byzantine.Address}); err != nil {
+	if _, _, err := ctx.ApplyBlock(0, proposer.Address, [][]byte{txBz}, [][]byte{byzantine.Address}); err != nil {
 		t.Fatal(err)
 	}
 	// beginBlock logic verify
@@ -106,7 +130,7 @@ func TestUtilityContext_EndBlock(t *testing.T) {
 	proposerBeforeBalance, err := ctx.GetAccountAmount(proposer.Address)
 	require.NoError(t, err)
 	// apply block
-	if _, err := ctx.ApplyBlock(0, proposer.Address, [][]byte{txBz}, [][]byte{byzantine.Address}); err != nil {
+	if _, _, err := ctx.ApplyBlock(0, proposer.Address, [][]byte{txBz}, [][]byte{byzantine.Address}); err != nil {
 		t.Fatal(err)
 	}
 	// deliverTx logic verify
@@ -129,6 +153,42 @@ func TestUtilityContext_EndBlock(t *testing.T) {
 	}
 }
 
+func TestUtilityContext_EndBlockValidatorUpdates(t *testing.T) {
+	ctx := NewTestingUtilityContext(t, 0)
+	vals := GetAllTestingValidators(t, ctx)
+	proposer := vals[0]
+	leaving := vals[1]
+	if err := ctx.BeginBlock(nil); err != nil {
+		t.Fatal(err)
+	}
+	// a validator joins
+	pubKey, _ := crypto.GeneratePublicKey()
+	joining := pubKey.Address()
+	if err := ctx.InsertValidator(joining, pubKey.Bytes(), joining, defaultServiceUrl, defaultAmountString); err != nil {
+		t.Fatal(err)
+	}
+	// a validator leaves
+	if err := ctx.SetValidatorPauseHeight(leaving.Address, 0); err != nil {
+		t.Fatal(err)
+	}
+	updates, err := ctx.EndBlock(proposer.Address)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	for _, update := range updates {
+		switch {
+		case bytes.Equal(update.Validator.Address, joining):
+			require.False(t, update.Removed, "joining validator should be added")
+			require.Equal(t, defaultServiceUrl, update.Validator.ServiceUrl)
+		case bytes.Equal(update.Validator.Address, leaving.Address):
+			require.True(t, update.Removed, "paused validator should be removed")
+		default:
+			t.Fatalf("unexpected validator update for %x", update.Validator.Address)
+		}
+	}
+	// the updates are sorted by address so every node applies them in the same order
+	require.True(t, bytes.Compare(updates[0].Validator.Address, updates[1].Validator.Address) < 0)
+}
+
 func TestUtilityContext_GetAppHash(t *testing.T) {
 	ctx := NewTestingUtilityContext(t, 0)
 	appHashTest, err := ctx.GetAppHash()
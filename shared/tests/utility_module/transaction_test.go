@@ -101,6 +101,26 @@ func TestUtilityContext_GetTransactionsForProposal(t *testing.T) {
 	}
 }
 
+func TestUtilityContext_GetTransactionsForProposalMaxBytes(t *testing.T) {
+	ctx := NewTestingUtilityContext(t, 0)
+	tx, _, _, _ := NewTestingTransaction(t, ctx)
+	proposer := GetAllTestingValidators(t, ctx)[0]
+	txBz, err := tx.Bytes()
+	require.NoError(t, err)
+	require.Nil(t, ctx.CheckTransaction(txBz))
+
+	// The transaction does not fit if the block only has room for its contents but not for its encoding
+	txSizeInBlock := types.TransactionSizeInBlock(txBz)
+	txs, er := ctx.GetTransactionsForProposal(proposer.Address, txSizeInBlock-1, nil)
+	require.NoError(t, er)
+	require.Len(t, txs, 0)
+
+	txs, er = ctx.GetTransactionsForProposal(proposer.Address, txSizeInBlock, nil)
+	require.NoError(t, er)
+	require.Len(t, txs, 1)
+	require.Equal(t, txBz, txs[0])
+}
+
 func TestUtilityContext_HandleMessage(t *testing.T) {
 	ctx := NewTestingUtilityContext(t, 0)
 	accs := GetAllTestingAccounts(t, ctx)
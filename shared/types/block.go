@@ -2,7 +2,10 @@ package types
 
 import (
 	"encoding/hex"
+
 	crypto2 "github.com/pokt-network/pocket/shared/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 )
 
 func (b *Block) ValidateBasic() Error {
@@ -54,3 +57,26 @@ func (bh *BlockHeader) ValidateBasic() Error {
 	}
 	return nil
 }
+
+// Returns the canonical hash of the block, computed over the serialized header with the `Hash` field cleared.
+// Since the header contains the hash of the previous block, blocks form a hash chain.
+func (bh *BlockHeader) ComputeHash() (string, error) {
+	header := proto.Clone(bh).(*BlockHeader)
+	header.Hash = ""
+	headerBz, err := proto.MarshalOptions{Deterministic: true}.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(crypto2.SHA3Hash(headerBz)), nil
+}
+
+// Returns the hex encoded Merkle root of the transactions in the block.
+func TransactionsRoot(transactions [][]byte) string {
+	return hex.EncodeToString(crypto2.MerkleRoot(transactions))
+}
+
+// Returns the number of bytes a transaction adds to the serialized block it is included in.
+func TransactionSizeInBlock(tx []byte) int {
+	fieldNumber := (&Block{}).ProtoReflect().Descriptor().Fields().ByName("transactions").Number()
+	return protowire.SizeTag(fieldNumber) + protowire.SizeBytes(len(tx))
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockHeaderComputeHash(t *testing.T) {
+	txs := [][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3")}
+	header := &BlockHeader{
+		Height:           1,
+		NumTxs:           uint32(len(txs)),
+		LastBlockHash:    "",
+		ProposerAddress:  []byte("proposer"),
+		AppHash:          "31",
+		TransactionsRoot: TransactionsRoot(txs),
+	}
+	hash, err := header.ComputeHash()
+	require.NoError(t, err)
+
+	// The hash does not depend on the value of the `Hash` field itself
+	header.Hash = hash
+	rehash, err := header.ComputeHash()
+	require.NoError(t, err)
+	require.Equal(t, hash, rehash)
+
+	// The hash commits to the app hash and, through the Merkle root, to every transaction
+	header.AppHash = "32"
+	appHashChanged, err := header.ComputeHash()
+	require.NoError(t, err)
+	require.NotEqual(t, hash, appHashChanged)
+
+	header.AppHash = "31"
+	header.TransactionsRoot = TransactionsRoot([][]byte{[]byte("tx1"), []byte("tx3"), []byte("tx2")})
+	txsChanged, err := header.ComputeHash()
+	require.NoError(t, err)
+	require.NotEqual(t, hash, txsChanged)
+}
+
+func TestTransactionsRoot(t *testing.T) {
+	require.NotEmpty(t, TransactionsRoot(nil))
+	require.NotEqual(t, TransactionsRoot(nil), TransactionsRoot([][]byte{{}}))
+	require.NotEqual(t, TransactionsRoot([][]byte{[]byte("tx1")}), TransactionsRoot([][]byte{[]byte("tx1"), []byte("tx1")}))
+	require.Equal(t, TransactionsRoot([][]byte{[]byte("tx1"), []byte("tx2")}), TransactionsRoot([][]byte{[]byte("tx1"), []byte("tx2")}))
+}
@@ -109,6 +109,8 @@ const (
 	CodePayloadTooBigError         Code = 123
 	CodeSocketIOStartFailedError   Code = 124
 
+	CodeUnequalStepsError Code = 125
+
 	GetValidatorStakedTokensError     = "an error occurred getting the validator staked tokens"
 	SetValidatorStakedTokensError     = "an error occurred setting the validator staked tokens"
 	EqualVotesError                   = "the votes are identical and not equivocating"
@@ -193,6 +195,7 @@ const (
 	ExportStateError                  = "an error occurred exporting the state"
 	UnequalHeightsError               = "the heights are not equal"
 	SetMissedBlocksError              = "an error occurred setting missed blocks"
+	UnequalStepsError                 = "the steps are not equal"
 
 	MissingRequiredArgError    = "socket error: missing required argument."
 	SocketRequestTimedOutError = "socket error: request timed out while waiting on ACK."
@@ -227,6 +230,10 @@ func ErrUnequalRounds() Error {
 	return NewError(CodeUnequalRoundsError, fmt.Sprintf("%s", UnequalRoundsError))
 }
 
+func ErrUnequalSteps() Error {
+	return NewError(CodeUnequalStepsError, fmt.Sprintf("%s", UnequalStepsError))
+}
+
 func ErrInvalidServiceUrl(reason string) Error {
 	return NewError(CodeInvalidServiceUrlError, fmt.Sprintf("%s: %s", InvalidServiceUrlError, reason))
 }
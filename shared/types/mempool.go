@@ -78,7 +78,7 @@ func (f *FIFOMempool) DeleteTransaction(tx []byte) Error {
 	f.l.Lock()
 	defer f.l.Unlock()
 	var toRemove *list.Element
-	for e := f.pool.Front(); e.Next() != nil; {
+	for e := f.pool.Front(); e != nil; e = e.Next() {
 		if bytes.Equal(tx, e.Value.([]byte)) {
 			toRemove = e
 			break
@@ -1,59 +1,73 @@
 package utility
 
 import (
+	"github.com/pokt-network/pocket/shared/modules"
 	"github.com/pokt-network/pocket/shared/types"
 	typesUtil "github.com/pokt-network/pocket/utility/types"
 )
 
-func (u *UtilityContext) ApplyBlock(latestHeight int64, proposerAddress []byte, transactions [][]byte, lastBlockByzantineValidators [][]byte) ([]byte, error) {
+func (u *UtilityContext) ApplyBlock(latestHeight int64, proposerAddress []byte, transactions [][]byte, lastBlockMissedValidators [][]byte) ([]byte, []*modules.ValidatorUpdate, error) {
 	u.LatestHeight = latestHeight
+	u.appliedTransactions = nil
 	// begin block lifecycle phase
-	if err := u.BeginBlock(lastBlockByzantineValidators); err != nil {
-		return nil, err
+	if err := u.BeginBlock(lastBlockMissedValidators); err != nil {
+		return nil, nil, err
 	}
 	// deliver txs lifecycle phase
 	for _, transaction := range transactions {
 		tx, err := typesUtil.TransactionFromBytes(transaction)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if err := tx.ValidateBasic(); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if err := u.ApplyTransaction(tx); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		// TODO: if found, remove transaction from mempool
-		// if err := u.Mempool.DeleteTransaction(tx); err != nil {
-		// 	return nil, err
-		// }
 	}
+	// the transactions are removed from the mempool once the block is committed; see `CommitContext`
+	u.appliedTransactions = transactions
 	// end block lifecycle phase
-	if err := u.EndBlock(proposerAddress); err != nil {
-		return nil, err
+	validatorUpdates, err := u.EndBlock(proposerAddress)
+	if err != nil {
+		return nil, nil, err
 	}
-	// return the app hash (consensus module will get the validator set directly
-	return u.GetAppHash()
+	// return the app hash alongside the changes to the validator set
+	appHash, err := u.GetAppHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	return appHash, validatorUpdates, nil
 }
 
-func (u *UtilityContext) BeginBlock(previousBlockByzantineValidators [][]byte) types.Error {
-	if err := u.HandleByzantineValidators(previousBlockByzantineValidators); err != nil {
+func (u *UtilityContext) BeginBlock(previousBlockMissedValidators [][]byte) types.Error {
+	// A proposer begins the same block twice (when reaping the mempool and when applying the block), so the
+	// validator set is only captured the first time to include the changes made in between.
+	if u.validatorSet == nil {
+		validatorSet, err := u.GetActiveValidators()
+		if err != nil {
+			return err
+		}
+		u.validatorSet = validatorSet
+	}
+	if err := u.HandleByzantineValidators(previousBlockMissedValidators); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u *UtilityContext) EndBlock(proposer []byte) types.Error {
+func (u *UtilityContext) EndBlock(proposer []byte) ([]*modules.ValidatorUpdate, types.Error) {
 	if err := u.HandleProposalRewards(proposer); err != nil {
-		return err
+		return nil, err
 	}
 	if err := u.UnstakeActorsThatAreReady(); err != nil {
-		return err
+		return nil, err
 	}
 	if err := u.BeginUnstakingMaxPausedActors(); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return u.GetValidatorUpdates()
 }
 
 func (u *UtilityContext) BeginUnstakingMaxPausedActors() types.Error {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pokt-network/pocket/shared/modules"
 	"github.com/pokt-network/pocket/shared/types"
+	typesGenesis "github.com/pokt-network/pocket/shared/types/genesis"
 	typesUtil "github.com/pokt-network/pocket/utility/types"
 )
 
@@ -14,6 +15,9 @@ type UtilityContext struct {
 	LatestHeight int64
 	Mempool      types.Mempool
 	Context      *Context
+
+	validatorSet        map[string]*typesGenesis.Validator // The active validator set when the first block was begun in this context
+	appliedTransactions [][]byte                           // The transactions of the last block applied in this context
 }
 
 type Context struct {
@@ -46,6 +50,25 @@ func (u *UtilityContext) GetPersistenceContext() modules.PersistenceContext {
 	return u.Context.PersistenceContext
 }
 
+// The transactions of the applied block were gossiped to every node, so they must not be proposed again in a future
+// block. They are only removed from the mempool once the block is committed, since a block that is applied but never
+// committed (e.g. because its round timed out) leaves them pending.
+func (u *UtilityContext) CommitContext() error {
+	if err := u.Context.Commit(); err != nil {
+		return err
+	}
+	for _, transaction := range u.appliedTransactions {
+		if !u.Mempool.Contains(typesUtil.TransactionHash(transaction)) {
+			continue
+		}
+		if err := u.Mempool.DeleteTransaction(transaction); err != nil {
+			return err
+		}
+	}
+	u.appliedTransactions = nil
+	return nil
+}
+
 func (u *UtilityContext) ReleaseContext() {
 	u.Context.Release()
 	u.Context = nil
@@ -7,7 +7,6 @@ import (
 	modulesMock "github.com/pokt-network/pocket/shared/modules/mocks"
 )
 
-var maxTxBytes = 90000
 var emptyByzValidators = make([][]byte, 0)
 var appHash []byte
 
@@ -26,13 +25,14 @@ func CreateMockedModule(_ *config.Config) (modules.UtilityModule, error) {
 
 	utilityContextMock.EXPECT().GetPersistenceContext().Return(persistenceContextMock).AnyTimes()
 	utilityContextMock.EXPECT().ReleaseContext().Return().AnyTimes()
+	utilityContextMock.EXPECT().CommitContext().Return(nil).AnyTimes()
 	utilityContextMock.EXPECT().
-		GetTransactionsForProposal(gomock.Any(), maxTxBytes, gomock.AssignableToTypeOf(emptyByzValidators)).
+		GetTransactionsForProposal(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(emptyByzValidators)).
 		Return(make([][]byte, 0), nil).
 		AnyTimes()
 	utilityContextMock.EXPECT().
 		ApplyBlock(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(appHash, nil).
+		Return(appHash, nil, nil).
 		AnyTimes()
 
 	persistenceContextMock.EXPECT().Commit().Return(nil).AnyTimes()
@@ -39,8 +39,8 @@ func (u *UtilityContext) CheckTransaction(transactionProtoBytes []byte) error {
 	return u.Mempool.AddTransaction(transactionProtoBytes)
 }
 
-func (u *UtilityContext) GetTransactionsForProposal(proposer []byte, maxTransactionBytes int, lastBlockByzantineValidators [][]byte) ([][]byte, error) {
-	if err := u.BeginBlock(lastBlockByzantineValidators); err != nil {
+func (u *UtilityContext) GetTransactionsForProposal(proposer []byte, maxTransactionBytes int, lastBlockMissedValidators [][]byte) ([][]byte, error) {
+	if err := u.BeginBlock(lastBlockMissedValidators); err != nil {
 		return nil, err
 	}
 	transactions := make([][]byte, 0)
@@ -54,9 +54,9 @@ func (u *UtilityContext) GetTransactionsForProposal(proposer []byte, maxTransact
 		if err != nil {
 			return nil, err
 		}
-		txSizeInBytes := len(txBytes)
+		txSizeInBytes := types.TransactionSizeInBlock(txBytes)
 		totalSizeInBytes += txSizeInBytes
-		if totalSizeInBytes >= maxTransactionBytes {
+		if totalSizeInBytes > maxTransactionBytes {
 			// Add back popped transaction to be applied in a future block
 			err := u.Mempool.AddTransaction(txBytes)
 			if err != nil {
@@ -73,7 +73,7 @@ func (u *UtilityContext) GetTransactionsForProposal(proposer []byte, maxTransact
 		}
 		transactions = append(transactions, txBytes)
 	}
-	if err := u.EndBlock(proposer); err != nil {
+	if _, err := u.EndBlock(proposer); err != nil {
 		return nil, err
 	}
 	return transactions, nil
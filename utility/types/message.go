@@ -313,6 +313,9 @@ func (msg *MessageDoubleSign) ValidateBasic() types.Error {
 	if msg.VoteA.Round != msg.VoteB.Round {
 		return types.ErrUnequalRounds()
 	}
+	if msg.VoteA.Step != msg.VoteB.Step {
+		return types.ErrUnequalSteps()
+	}
 	if bytes.Equal(msg.VoteA.BlockHash, msg.VoteB.BlockHash) {
 		return types.ErrEqualVotes()
 	}
@@ -107,6 +107,15 @@ func TestMessageDoubleSign_ValidateBasic(t *testing.T) {
 	if err := msgUnequalRounds.ValidateBasic(); err.Code() != types.ErrUnequalRounds().Code() {
 		t.Fatal(err)
 	}
+	msgUnequalSteps := new(MessageDoubleSign)
+	msgUnequalSteps.VoteA = new(Vote)
+	msgUnequalSteps.VoteB = new(Vote)
+	*msgUnequalSteps.VoteA = *msg.VoteA
+	*msgUnequalSteps.VoteB = *msg.VoteB
+	msgUnequalSteps.VoteA.Step = 1
+	if err := msgUnequalSteps.ValidateBasic(); err.Code() != types.ErrUnequalSteps().Code() {
+		t.Fatal(err)
+	}
 	//msgUnequalVoteTypes := new(MessageDoubleSign) TODO only one type of evidence right now
 	//msgUnequalVoteTypes.VoteA = new(Vote)
 	//msgUnequalVoteTypes.VoteB = new(Vote)
@@ -1,9 +1,13 @@
 package utility
 
 import (
+	"bytes"
+	"encoding/hex"
 	"math/big"
+	"sort"
 
 	"github.com/pokt-network/pocket/shared/crypto"
+	"github.com/pokt-network/pocket/shared/modules"
 	"github.com/pokt-network/pocket/shared/types"
 	typesGenesis "github.com/pokt-network/pocket/shared/types/genesis"
 	typesUtil "github.com/pokt-network/pocket/utility/types"
@@ -197,7 +201,9 @@ func (u *UtilityContext) HandleMessageUnpauseValidator(message *typesUtil.Messag
 	return nil
 }
 
-func (u *UtilityContext) HandleByzantineValidators(lastBlockByzantineValidators [][]byte) types.Error {
+// Counts a missed block against every validator that did not sign the last block, and only pauses and burns the
+// validators once they reach `ValidatorMaxMissedBlocks`.
+func (u *UtilityContext) HandleByzantineValidators(lastBlockMissedValidators [][]byte) types.Error {
 	latestBlockHeight, err := u.GetLatestHeight()
 	if err != nil {
 		return err
@@ -206,7 +212,7 @@ func (u *UtilityContext) HandleByzantineValidators(lastBlockByzantineValidators
 	if err != nil {
 		return err
 	}
-	for _, address := range lastBlockByzantineValidators {
+	for _, address := range lastBlockMissedValidators {
 		numberOfMissedBlocks, err := u.GetValidatorMissedBlocks(address)
 		if err != nil {
 			return err
@@ -580,6 +586,66 @@ func (u *UtilityContext) GetValidatorOutputAddress(operator []byte) ([]byte, typ
 	return output, nil
 }
 
+// Returns the validators that participate in consensus (i.e. staked and not paused) keyed by their hex encoded address.
+func (u *UtilityContext) GetActiveValidators() (map[string]*typesGenesis.Validator, types.Error) {
+	store := u.Store()
+	height, er := store.GetHeight()
+	if er != nil {
+		return nil, types.ErrGetAllValidators(er)
+	}
+	validators, er := store.GetAllValidators(height)
+	if er != nil {
+		return nil, types.ErrGetAllValidators(er)
+	}
+	activeValidators := make(map[string]*typesGenesis.Validator, len(validators))
+	for _, validator := range validators {
+		if validator.Paused || validator.Status != typesUtil.StakedStatus {
+			continue
+		}
+		activeValidators[hex.EncodeToString(validator.Address)] = validator
+	}
+	return activeValidators, nil
+}
+
+// Returns the changes to the active validator set since the start of the block sorted by address, so every
+// node applies them in the same order.
+func (u *UtilityContext) GetValidatorUpdates() ([]*modules.ValidatorUpdate, types.Error) {
+	activeValidators, err := u.GetActiveValidators()
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(activeValidators))
+	for address := range activeValidators {
+		addresses = append(addresses, address)
+	}
+	for address := range u.validatorSet {
+		if _, ok := activeValidators[address]; !ok {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	updates := make([]*modules.ValidatorUpdate, 0)
+	for _, address := range addresses {
+		before, wasActive := u.validatorSet[address]
+		after, isActive := activeValidators[address]
+		switch {
+		case wasActive && !isActive:
+			updates = append(updates, &modules.ValidatorUpdate{Validator: before, Removed: true})
+		case isActive && (!wasActive || isValidatorChanged(before, after)):
+			updates = append(updates, &modules.ValidatorUpdate{Validator: after})
+		}
+	}
+	return updates, nil
+}
+
+// Only the attributes consensus and P2P depend on are considered.
+func isValidatorChanged(before, after *typesGenesis.Validator) bool {
+	return !bytes.Equal(before.PublicKey, after.PublicKey) ||
+		before.ServiceUrl != after.ServiceUrl ||
+		before.StakedTokens != after.StakedTokens
+}
+
 func (u *UtilityContext) CalculateUnstakingHeight(unstakingBlocks int64) (int64, types.Error) {
 	latestHeight, err := u.GetLatestHeight()
 	if err != nil {